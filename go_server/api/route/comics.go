@@ -2,7 +2,10 @@ package route
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -10,18 +13,29 @@ import (
 	"strings"
 	"time"
 
+	"comics/api/middleware"
+	"comics/bootstrap"
+	"comics/internal/jsonpatch"
 	"comics/internal/service"
+	"comics/internal/tokenutil"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
-func comicsRouter(group *gin.RouterGroup) {
+// comicsRouter registers the comic REST routes and returns their service, nil
+// when the SQLite database can't be opened
+func comicsRouter(ctx context.Context, env *bootstrap.Env, group *gin.RouterGroup) *service.SQLiteComicService {
 	comics, err := service.NewSQLiteComicService(os.Getenv("COMICS_SQLITE_PATH"))
 	if err != nil {
 		log.Warn().Err(err).Msg("Comic REST routes disabled")
-		return
+		return nil
+	}
+	adminOnly := []gin.HandlerFunc{
+		middleware.AuthenticationMiddleware(env.JWTConfig.AccessTokenSecret),
+		middleware.RoleMiddleware(tokenutil.RoleAdmin),
 	}
+	startTrashRetention(ctx, comics, os.Getenv("COMICS_TRASH_RETENTION_DAYS"))
 
 	group.GET("/health/db", func(c *gin.Context) {
 		if err := comics.Ping(c.Request.Context()); err != nil {
@@ -31,27 +45,49 @@ func comicsRouter(group *gin.RouterGroup) {
 		c.JSON(http.StatusOK, gin.H{"message": "success"})
 	})
 
-	group.GET("/scrape", proxyPythonScrape)
-
 	group.GET("/comics", listComics(comics))
 	group.POST("/comics", createComic(comics))
+	group.POST("/comics/batch", batchComics(comics))
+	group.POST("/comics/import", importComics(comics))
+	group.POST("/comics/import/tachiyomi", importTachiyomi(comics))
+	group.POST("/comics/mark-read", markComicsRead(comics))
+	group.GET("/comics/trash", listTrash(comics))
+	group.GET("/comics/duplicates", listDuplicates(comics))
+	group.GET("/comics/export", exportComics(comics))
 	group.GET("/comics/:id", getComic(comics))
+	group.GET("/comics/:id/history", comicHistory(comics))
+	group.GET("/comics/:id/merges", listComicMerges(comics))
+	group.POST("/comics/:id/unmerge/:merge_id", unmergeComic(comics))
 	group.PUT("/comics/:id", updateComic(comics))
+	group.PATCH("/comics/:id", patchComic(comics))
 	group.DELETE("/comics/:id", deleteComic(comics))
+	group.POST("/comics/:id/restore", restoreComic(comics))
+	group.POST("/comics/:id/split", splitComic(comics))
+	group.DELETE("/comics/:id/purge", append(adminOnly, purgeComic(comics))...)
 	group.PATCH("/comics/:id/cover-visibility", updateCoverVisibility(comics))
 	group.GET("/comics/search/:title", searchComics(comics))
-	group.PATCH("/comics/:base_id/:merging_id", mergeComics(comics))
-	group.PUT("/comics/:base_id/:merging_id", mergeComics(comics))
+	// The base comic id shares the ":id" wildcard name with the single comic
+	// routes, gin rejects different wildcard names on the same segment
+	group.GET("/comics/:id/:merging_id/preview", previewMerge(comics))
+	group.PATCH("/comics/:id/:merging_id", mergeComics(comics))
+	group.PUT("/comics/:id/:merging_id", mergeComics(comics))
+	return comics
 }
 
 func listComics(comics *service.SQLiteComicService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		filter, order, err := comicListQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
 		result, err := comics.List(
 			c.Request.Context(),
 			queryInt(c, "from", 0),
 			queryInt(c, "limit", 20),
-			queryBool(c, "only_tracked"),
-			queryBool(c, "only_unchecked"),
+			c.Query("cursor"),
+			filter,
+			order,
 			queryBool(c, "full"),
 		)
 		writeComicList(c, result, err)
@@ -65,13 +101,19 @@ func searchComics(comics *service.SQLiteComicService) gin.HandlerFunc {
 			c.JSON(http.StatusBadRequest, gin.H{"message": "Title cannot be empty"})
 			return
 		}
+		filter, order, err := comicListQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
 		result, err := comics.Search(
 			c.Request.Context(),
 			title,
 			queryInt(c, "from", 0),
 			queryInt(c, "limit", 20),
-			queryBool(c, "only_tracked"),
-			queryBool(c, "only_unchecked"),
+			c.Query("cursor"),
+			filter,
+			order,
 			queryBool(c, "full"),
 		)
 		writeComicList(c, result, err)
@@ -117,11 +159,215 @@ func updateComic(comics *service.SQLiteComicService) gin.HandlerFunc {
 			return
 		}
 		applyComicPatch(&current, body)
-		comic, err := comics.Update(c.Request.Context(), id, current)
+		source, ok := intValue(body["source"])
+		if !ok {
+			source = -1
+		}
+		comic, err := comics.UpdateWithOptions(c.Request.Context(), id, current, service.UpdateOptions{
+			Source:  source,
+			IfMatch: c.GetHeader("If-Match"),
+		})
 		writeComic(c, comic, err)
 	}
 }
 
+// patchComic accepts RFC 7396 merge patches and RFC 6902 JSON patches, any
+// other content type keeps the partial update behaviour of PUT.
+func patchComic(comics *service.SQLiteComicService) gin.HandlerFunc {
+	legacy := updateComic(comics)
+	return func(c *gin.Context) {
+		var patch service.ComicPatch
+		switch c.ContentType() {
+		case jsonpatch.MergePatchType:
+			var body any
+			if err := c.ShouldBindJSON(&body); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "Body payload is necessary"})
+				return
+			}
+			merge, ok := body.(map[string]any)
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "merge patch must be a JSON object", "path": ""})
+				return
+			}
+			patch.Merge = merge
+		case jsonpatch.JSONPatchType:
+			data, err := c.GetRawData()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "Body payload is necessary"})
+				return
+			}
+			patch.Operations, err = jsonpatch.DecodeOperations(data)
+			if err != nil {
+				writePatchError(c, err)
+				return
+			}
+		default:
+			legacy(c)
+			return
+		}
+
+		comic, err := comics.Patch(c.Request.Context(), pathInt(c, "id"), patch, service.UpdateOptions{
+			Source:  -1,
+			IfMatch: c.GetHeader("If-Match"),
+		})
+		var patchErr *jsonpatch.Error
+		if errors.As(err, &patchErr) {
+			writePatchError(c, err)
+			return
+		}
+		writeComic(c, comic, err)
+	}
+}
+
+func writePatchError(c *gin.Context, err error) {
+	var patchErr *jsonpatch.Error
+	if !errors.As(err, &patchErr) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	status := http.StatusBadRequest
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		status = http.StatusConflict
+	}
+	c.JSON(status, gin.H{"message": patchErr.Error(), "path": patchErr.Path})
+}
+
+// batchComics runs create, update, delete and mark-read operations in one
+// transaction. With "atomic" (the default) any failure rolls back the whole
+// batch, otherwise every operation reports its own status.
+func batchComics(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Atomic     *bool                    `json:"atomic"`
+			Operations []service.BatchOperation `json:"operations"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil || len(body.Operations) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "operations should be a non-empty list"})
+			return
+		}
+		atomic := body.Atomic == nil || *body.Atomic
+
+		results, err := comics.Batch(c.Request.Context(), body.Operations, atomic)
+		if errors.Is(err, service.ErrBatchTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+
+		items := make([]gin.H, 0, len(results))
+		status := http.StatusOK
+		for _, result := range results {
+			item := gin.H{"index": result.Index, "op": result.Op, "status": batchItemStatus(result)}
+			if result.ID != 0 {
+				item["id"] = result.ID
+			}
+			if result.Comic != nil {
+				item["comic"] = result.Comic
+				item["etag"] = service.ComicETag(*result.Comic)
+			}
+			if result.Err != nil {
+				item["message"] = result.Err.Error()
+				var patchErr *jsonpatch.Error
+				if errors.As(result.Err, &patchErr) {
+					item["path"] = patchErr.Path
+				}
+				var conflict *service.IdentityConflictError
+				if errors.As(result.Err, &conflict) {
+					item["conflicting_id"] = conflict.ConflictingID
+				}
+				if atomic && !errors.Is(result.Err, service.ErrBatchAborted) {
+					status = batchItemStatus(result)
+				}
+			}
+			items = append(items, item)
+		}
+		c.JSON(status, gin.H{"atomic": atomic, "results": items})
+	}
+}
+
+func batchItemStatus(result service.BatchResult) int {
+	var patchErr *jsonpatch.Error
+	switch {
+	case result.Err == nil && result.Op == "create":
+		return http.StatusCreated
+	case result.Err == nil:
+		return http.StatusOK
+	case errors.Is(result.Err, service.ErrComicNotFound):
+		return http.StatusNotFound
+	case errors.Is(result.Err, service.ErrPreconditionFailed):
+		return http.StatusPreconditionFailed
+	case errors.Is(result.Err, service.ErrIdentityConflict):
+		return http.StatusConflict
+	case errors.Is(result.Err, service.ErrBatchAborted):
+		return http.StatusFailedDependency
+	case errors.As(result.Err, &patchErr),
+		errors.Is(result.Err, service.ErrUnknownBatchOp),
+		errors.Is(result.Err, service.ErrBatchMissingComic),
+		errors.Is(result.Err, service.ErrBatchMissingTarget):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func markComicsRead(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			IDs           []int `json:"ids"`
+			OnlyTracked   bool  `json:"only_tracked"`
+			OnlyUnchecked bool  `json:"only_unchecked"`
+			Publisher     int   `json:"publisher"`
+			Genre         int   `json:"genre"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Body payload is necessary"})
+			return
+		}
+		affected, err := comics.MarkRead(c.Request.Context(), body.IDs, service.ComicFilter{
+			OnlyTracked:   body.OnlyTracked,
+			OnlyUnchecked: body.OnlyUnchecked,
+			Publisher:     body.Publisher,
+			Genre:         body.Genre,
+		})
+		if errors.Is(err, service.ErrEmptySelection) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"affected": affected})
+	}
+}
+
+func comicHistory(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := comics.History(
+			c.Request.Context(),
+			pathInt(c, "id"),
+			queryInt(c, "from", 0),
+			queryInt(c, "limit", 20),
+		)
+		if errors.Is(err, service.ErrComicNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Comic not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		c.Header("access-control-expose-headers", "total-events,total-pages,current-page")
+		c.Header("total-events", strconv.Itoa(result.Total))
+		c.Header("total-pages", strconv.Itoa(result.TotalPages))
+		c.Header("current-page", strconv.Itoa(result.CurrentPage))
+		c.JSON(http.StatusOK, result.Events)
+	}
+}
+
 func deleteComic(comics *service.SQLiteComicService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		err := comics.Delete(c.Request.Context(), pathInt(c, "id"))
@@ -137,6 +383,127 @@ func deleteComic(comics *service.SQLiteComicService) gin.HandlerFunc {
 	}
 }
 
+func listTrash(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		result, err := comics.Trash(
+			c.Request.Context(),
+			queryInt(c, "from", 0),
+			queryInt(c, "limit", 20),
+			queryBool(c, "full"),
+		)
+		writeComicList(c, result, err)
+	}
+}
+
+// listDuplicates returns the groups of comics that look like the same series,
+// min_confidence defaults to 0.5 out of 1.
+func listDuplicates(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		minConfidence, err := strconv.ParseFloat(c.DefaultQuery("min_confidence", "0.5"), 64)
+		if err != nil || minConfidence < 0 || minConfidence > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "min_confidence should be a number between 0 and 1"})
+			return
+		}
+		groups, err := comics.Duplicates(c.Request.Context(), minConfidence)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		if limit := queryInt(c, "limit", 50); limit > 0 && len(groups) > limit {
+			groups = groups[:limit]
+		}
+		c.JSON(http.StatusOK, groups)
+	}
+}
+
+// splitComic moves titles and publishers of the comic to a new comic and
+// returns both.
+func splitComic(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body service.SplitJSON
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		result, err := comics.Split(c.Request.Context(), pathInt(c, "id"), body, service.UpdateOptions{
+			IfMatch: c.GetHeader("If-Match"),
+		})
+		if errors.Is(err, service.ErrInvalidSplit) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		if err != nil {
+			writeComic(c, service.ComicJSON{}, err)
+			return
+		}
+		c.JSON(http.StatusCreated, result)
+	}
+}
+
+func restoreComic(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		comic, err := comics.Restore(c.Request.Context(), pathInt(c, "id"))
+		if errors.Is(err, service.ErrComicNotTrashed) {
+			c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+			return
+		}
+		writeComic(c, comic, err)
+	}
+}
+
+func purgeComic(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := comics.Purge(c.Request.Context(), pathInt(c, "id"))
+		if errors.Is(err, service.ErrComicNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Comic not found"})
+			return
+		}
+		if errors.Is(err, service.ErrComicNotTrashed) {
+			c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, http.StatusAccepted)
+	}
+}
+
+// startTrashRetention purges trashed comics older than the given amount of
+// days every hour until ctx ends, it does nothing when the setting is empty
+// or invalid.
+func startTrashRetention(ctx context.Context, comics *service.SQLiteComicService, days string) {
+	retentionDays, err := strconv.Atoi(days)
+	if err != nil || retentionDays <= 0 {
+		return
+	}
+	retention := time.Duration(retentionDays) * 24 * time.Hour
+	purge := func() {
+		purged, err := comics.PurgeExpired(ctx, retention)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to purge expired trash")
+			return
+		}
+		if purged > 0 {
+			log.Info().Int("purged", purged).Msg("Expired comics purged from trash")
+		}
+	}
+	go func() {
+		purge()
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				purge()
+			}
+		}
+	}()
+}
+
 func updateCoverVisibility(comics *service.SQLiteComicService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var body struct {
@@ -157,14 +524,30 @@ func updateCoverVisibility(comics *service.SQLiteComicService) gin.HandlerFunc {
 	}
 }
 
+// mergeComics merges :merging_id into :id, the optional body overrides the
+// strategy of single fields:
+//
+//	{"strategies": {"author": "prefer_merging", "rating": {"strategy": "value", "value": 8}}}
 func mergeComics(comics *service.SQLiteComicService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		var body struct {
+			Strategies service.MergeStrategies `json:"strategies"`
+		}
+		data, err := c.GetRawData()
+		if err == nil && len(bytes.TrimSpace(data)) > 0 {
+			err = json.Unmarshal(data, &body)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
 		comic, err := comics.Merge(
 			c.Request.Context(),
-			pathInt(c, "base_id"),
+			pathInt(c, "id"),
 			pathInt(c, "merging_id"),
+			body.Strategies,
 		)
-		if err != nil && strings.Contains(err.Error(), "same type") {
+		if isMergeRequestError(err) {
 			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
 			return
 		}
@@ -172,15 +555,100 @@ func mergeComics(comics *service.SQLiteComicService) gin.HandlerFunc {
 	}
 }
 
+// previewMerge returns the result of a merge without applying it, the
+// strategies query parameter takes the same JSON map as mergeComics.
+func previewMerge(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var strategies service.MergeStrategies
+		if raw := c.Query("strategies"); raw != "" {
+			if err := json.Unmarshal([]byte(raw), &strategies); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				return
+			}
+		}
+		preview, err := comics.PreviewMerge(
+			c.Request.Context(),
+			pathInt(c, "id"),
+			pathInt(c, "merging_id"),
+			strategies,
+		)
+		var conflict *service.IdentityConflictError
+		switch {
+		case errors.Is(err, service.ErrComicNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"message": "Comic not found"})
+		case isMergeRequestError(err):
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		case errors.As(err, &conflict):
+			c.JSON(http.StatusConflict, gin.H{
+				"message":        err.Error(),
+				"identity_key":   conflict.IdentityKey,
+				"conflicting_id": conflict.ConflictingID,
+			})
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		default:
+			c.JSON(http.StatusOK, preview)
+		}
+	}
+}
+
+func isMergeRequestError(err error) bool {
+	return err != nil && (errors.Is(err, service.ErrInvalidMergeStrategy) ||
+		errors.Is(err, service.ErrInvalidMergedComic) ||
+		strings.Contains(err.Error(), "same type") ||
+		strings.Contains(err.Error(), "merge with themselves"))
+}
+
+func listComicMerges(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		merges, err := comics.Merges(c.Request.Context(), pathInt(c, "id"))
+		if errors.Is(err, service.ErrComicNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Comic not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, merges)
+	}
+}
+
+func unmergeComic(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		comic, err := comics.Unmerge(
+			c.Request.Context(),
+			pathInt(c, "id"),
+			pathInt(c, "merge_id"),
+		)
+		if errors.Is(err, service.ErrMergeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Merge not found"})
+			return
+		}
+		if errors.Is(err, service.ErrMergeReverted) || errors.Is(err, service.ErrMergeConflict) {
+			c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+			return
+		}
+		writeComic(c, comic, err)
+	}
+}
+
 func writeComicList(c *gin.Context, result service.ComicListResult, err error) {
+	if errors.Is(err, service.ErrInvalidCursor) || errors.Is(err, service.ErrRelevanceWithoutSearch) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
-	c.Header("access-control-expose-headers", "total-comics,total-pages,current-page")
+	c.Header("access-control-expose-headers", "total-comics,total-pages,current-page,next-cursor")
 	c.Header("total-comics", strconv.Itoa(result.Total))
 	c.Header("total-pages", strconv.Itoa(result.TotalPages))
 	c.Header("current-page", strconv.Itoa(result.CurrentPage))
+	if result.NextCursor != "" {
+		c.Header("next-cursor", result.NextCursor)
+	}
 	c.JSON(http.StatusOK, result.Comics)
 }
 
@@ -193,10 +661,25 @@ func writeComicWithStatus(c *gin.Context, comic service.ComicJSON, err error, st
 		c.JSON(http.StatusNotFound, gin.H{"message": "Comic not found"})
 		return
 	}
+	if errors.Is(err, service.ErrPreconditionFailed) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"message": err.Error()})
+		return
+	}
+	var conflict *service.IdentityConflictError
+	if errors.As(err, &conflict) {
+		c.JSON(http.StatusConflict, gin.H{
+			"message":        err.Error(),
+			"identity_key":   conflict.IdentityKey,
+			"conflicting_id": conflict.ConflictingID,
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
 		return
 	}
+	c.Header("access-control-expose-headers", "etag")
+	c.Header("ETag", service.ComicETag(comic))
 	c.JSON(status, comic)
 }
 
@@ -244,6 +727,82 @@ func queryBool(c *gin.Context, key string) bool {
 	return strings.EqualFold(c.DefaultQuery(key, "false"), "true")
 }
 
+// comicListQuery reads the List and Search filters and sort order from the
+// query string.
+func comicListQuery(c *gin.Context) (service.ComicFilter, service.ComicSort, error) {
+	filter := service.ComicFilter{
+		OnlyTracked:   queryBool(c, "only_tracked"),
+		OnlyUnchecked: queryBool(c, "only_unchecked"),
+	}
+	optional := []struct {
+		key    string
+		target **int
+	}{
+		{"status", &filter.Status},
+		{"com_type", &filter.ComType},
+		{"min_rating", &filter.MinRating},
+		{"max_rating", &filter.MaxRating},
+		{"unread_gt", &filter.UnreadOver},
+	}
+	var err error
+	for _, param := range optional {
+		if *param.target, err = queryOptionalInt(c, param.key); err != nil {
+			return service.ComicFilter{}, service.ComicSort{}, err
+		}
+	}
+	if filter.Publisher, err = queryIntStrict(c, "publisher"); err != nil {
+		return service.ComicFilter{}, service.ComicSort{}, err
+	}
+	if filter.Genre, err = queryIntStrict(c, "genre"); err != nil {
+		return service.ComicFilter{}, service.ComicSort{}, err
+	}
+	if since := c.Query("updated_since"); since != "" {
+		if filter.UpdatedSince, err = parseQueryTime(since); err != nil {
+			return service.ComicFilter{}, service.ComicSort{}, fmt.Errorf("updated_since %w", err)
+		}
+	}
+
+	order, err := service.ParseComicSort(c.Query("sort"), c.Query("order"))
+	if err != nil {
+		return service.ComicFilter{}, service.ComicSort{}, err
+	}
+	return filter, order, nil
+}
+
+func queryOptionalInt(c *gin.Context, key string) (*int, error) {
+	raw, ok := c.GetQuery(key)
+	if !ok || raw == "" {
+		return nil, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an integer", key)
+	}
+	return &value, nil
+}
+
+// queryIntStrict returns zero for a missing parameter and an error for a
+// malformed one.
+func queryIntStrict(c *gin.Context, key string) (int, error) {
+	value, err := queryOptionalInt(c, key)
+	if err != nil || value == nil {
+		return 0, err
+	}
+	return *value, nil
+}
+
+// parseQueryTime accepts RFC 3339 timestamps and unix seconds.
+func parseQueryTime(raw string) (time.Time, error) {
+	if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	parsed, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, errors.New("must be an RFC 3339 timestamp or unix seconds")
+	}
+	return parsed, nil
+}
+
 func pathInt(c *gin.Context, key string) int {
 	value, _ := strconv.Atoi(c.Param(key))
 	return value
@@ -0,0 +1,194 @@
+package route
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"comics/internal/service"
+	"comics/pkg/pb"
+	"comics/sampler"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// comicsField is the field number of the comics list in pb.Comics, the
+// protobuf export writes every comic as one element of it
+var comicsField = (&pb.Comics{}).ProtoReflect().Descriptor().Fields().ByName("comics").Number()
+
+var csvHeader = []string{
+	"id", "titles", "author", "description", "com_type", "status", "cover",
+	"cover_visible", "current_chap", "viewed_chap", "published_in", "genres",
+	"rating", "track", "deleted", "last_update",
+}
+
+// comicExporter writes the comics of an export in one format, begin and end
+// wrap the list and may be nil
+type comicExporter struct {
+	contentType string
+	extension   string
+	begin       func(w io.Writer) error
+	write       func(w io.Writer, comic service.ComicJSON, first bool) error
+	end         func(w io.Writer) error
+}
+
+func newComicExporter(format string) (comicExporter, bool) {
+	switch format {
+	case "csv":
+		var writer *csv.Writer
+		return comicExporter{
+			contentType: "text/csv",
+			extension:   "csv",
+			begin: func(w io.Writer) error {
+				writer = csv.NewWriter(w)
+				return writer.Write(csvHeader)
+			},
+			write: func(_ io.Writer, comic service.ComicJSON, _ bool) error {
+				return writer.Write(comicCSVRecord(comic))
+			},
+			end: func(io.Writer) error {
+				writer.Flush()
+				return writer.Error()
+			},
+		}, true
+	case "json":
+		// The output is the proto JSON of a pb.Comics message
+		return comicExporter{
+			contentType: "application/json",
+			extension:   "json",
+			begin: func(w io.Writer) error {
+				_, err := io.WriteString(w, `{"comics":[`)
+				return err
+			},
+			write: func(w io.Writer, comic service.ComicJSON, first bool) error {
+				if !first {
+					if _, err := io.WriteString(w, ","); err != nil {
+						return err
+					}
+				}
+				return sampler.WriteProtobufToJSON(w, comic.Proto(), "")
+			},
+			end: func(w io.Writer) error {
+				_, err := io.WriteString(w, "]}\n")
+				return err
+			},
+		}, true
+	case "ndjson":
+		return comicExporter{
+			contentType: "application/x-ndjson",
+			extension:   "ndjson",
+			write: func(w io.Writer, comic service.ComicJSON, _ bool) error {
+				if err := sampler.WriteProtobufToJSON(w, comic.Proto(), ""); err != nil {
+					return err
+				}
+				_, err := io.WriteString(w, "\n")
+				return err
+			},
+		}, true
+	case "pb":
+		return comicExporter{
+			contentType: "application/x-protobuf",
+			extension:   "pb",
+			write: func(w io.Writer, comic service.ComicJSON, _ bool) error {
+				return sampler.WriteProtobufField(w, comicsField, comic.Proto())
+			},
+		}, true
+	}
+	return comicExporter{}, false
+}
+
+func comicCSVRecord(comic service.ComicJSON) []string {
+	return []string{
+		strconv.Itoa(comic.ID),
+		strings.Join(comic.Titles, "|"),
+		comic.Author,
+		comic.Description,
+		strconv.Itoa(comic.ComType),
+		strconv.Itoa(comic.Status),
+		comic.Cover,
+		strconv.FormatBool(comic.CoverVisible),
+		strconv.Itoa(comic.CurrentChap),
+		strconv.Itoa(comic.ViewedChap),
+		joinIDs(comic.PublishedIn),
+		joinIDs(comic.Genres),
+		strconv.Itoa(comic.Rating),
+		strconv.FormatBool(comic.Track),
+		strconv.FormatBool(comic.Deleted),
+		comic.LastUpdate,
+	}
+}
+
+func joinIDs(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(value)
+	}
+	return strings.Join(parts, "|")
+}
+
+// exportComics streams the whole library in the requested format, filtered
+// and sorted like listComics. include_deleted adds the comics in the trash.
+func exportComics(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.DefaultQuery("format", "json")
+		exporter, ok := newComicExporter(format)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "format should be one of csv, json, ndjson or pb"})
+			return
+		}
+		filter, order, err := comicListQuery(c)
+		if err == nil && order.Field == service.SortRelevance {
+			err = service.ErrRelevanceWithoutSearch
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		filter.IncludeDeleted = queryBool(c, "include_deleted")
+
+		// Headers go out with the first comic, so errors before it still get
+		// a proper status
+		started := false
+		start := func() error {
+			started = true
+			c.Header("Content-Type", exporter.contentType)
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="comics.%s"`, exporter.extension))
+			c.Status(http.StatusOK)
+			if exporter.begin == nil {
+				return nil
+			}
+			return exporter.begin(c.Writer)
+		}
+		first := true
+		err = comics.Export(c.Request.Context(), filter, order, func(comic service.ComicJSON) error {
+			if !started {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+			if err := exporter.write(c.Writer, comic, first); err != nil {
+				return err
+			}
+			first = false
+			return nil
+		})
+		if err == nil && !started {
+			err = start()
+		}
+		if err == nil && exporter.end != nil {
+			err = exporter.end(c.Writer)
+		}
+		if err != nil {
+			if !started {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+				return
+			}
+			// The status is gone already, the client sees a truncated body
+			log.Error().Err(err).Str("format", format).Msg("Comic export interrupted")
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package route
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"comics/internal/service"
+	"comics/pkg/pb"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxImportSize caps the body of an import
+const maxImportSize = 64 << 20
+
+// importFormats maps content types to the formats of the export
+var importFormats = map[string]string{
+	"text/csv":               "csv",
+	"application/json":       "json",
+	"application/x-ndjson":   "ndjson",
+	"application/x-protobuf": "pb",
+}
+
+// importComics applies a file written by exportComics, the format comes from
+// the format query parameter or else from the Content-Type. With dry_run=true
+// the report is computed without writing anything.
+func importComics(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.Query("format")
+		if format == "" {
+			contentType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+			format = importFormats[contentType]
+		}
+		data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize))
+		if err != nil {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": err.Error()})
+			return
+		}
+		rows, err := decodeComics(format, data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+
+		report, err := comics.Import(c.Request.Context(), rows, queryBool(c, "dry_run"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+func decodeComics(format string, data []byte) ([]service.ComicJSON, error) {
+	switch format {
+	case "csv":
+		return decodeComicsCSV(data)
+	case "json":
+		var message pb.Comics
+		if err := protojson.Unmarshal(data, &message); err != nil {
+			return nil, fmt.Errorf("invalid json: %w", err)
+		}
+		return comicsFromProto(message.GetComics()), nil
+	case "ndjson":
+		comics := []service.ComicJSON{}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, maxImportSize)
+		for line := 1; scanner.Scan(); line++ {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			var message pb.Comic
+			if err := protojson.Unmarshal(scanner.Bytes(), &message); err != nil {
+				return nil, fmt.Errorf("invalid json on line %d: %w", line, err)
+			}
+			comics = append(comics, service.ComicFromProto(&message))
+		}
+		return comics, scanner.Err()
+	case "pb":
+		var message pb.Comics
+		if err := proto.Unmarshal(data, &message); err != nil {
+			return nil, fmt.Errorf("invalid protobuf: %w", err)
+		}
+		return comicsFromProto(message.GetComics()), nil
+	}
+	return nil, errors.New("format should be one of csv, json, ndjson or pb")
+}
+
+func comicsFromProto(messages []*pb.Comic) []service.ComicJSON {
+	comics := make([]service.ComicJSON, len(messages))
+	for i, message := range messages {
+		comics[i] = service.ComicFromProto(message)
+	}
+	return comics
+}
+
+// decodeComicsCSV reads the columns of csvHeader by name, the missing ones
+// keep their zero value and cover_visible defaults to true
+func decodeComicsCSV(data []byte) ([]service.ComicJSON, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+	if len(records) == 0 {
+		return []service.ComicJSON{}, nil
+	}
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	if _, ok := columns["titles"]; !ok {
+		return nil, errors.New("invalid csv: the titles column is required")
+	}
+
+	comics := make([]service.ComicJSON, 0, len(records)-1)
+	for line, record := range records[1:] {
+		comic, err := comicFromCSV(columns, record)
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv on row %d: %w", line+1, err)
+		}
+		comics = append(comics, comic)
+	}
+	return comics, nil
+}
+
+func comicFromCSV(columns map[string]int, record []string) (service.ComicJSON, error) {
+	value := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	comic := service.ComicJSON{
+		Author:       value("author"),
+		Description:  value("description"),
+		Cover:        value("cover"),
+		CoverVisible: true,
+	}
+	if titles := value("titles"); titles != "" {
+		comic.Titles = strings.Split(titles, "|")
+	}
+
+	var err error
+	for name, target := range map[string]*int{
+		"id":           &comic.ID,
+		"com_type":     &comic.ComType,
+		"status":       &comic.Status,
+		"current_chap": &comic.CurrentChap,
+		"viewed_chap":  &comic.ViewedChap,
+		"rating":       &comic.Rating,
+	} {
+		if raw := value(name); raw != "" {
+			if *target, err = strconv.Atoi(raw); err != nil {
+				return service.ComicJSON{}, fmt.Errorf("%s must be an integer", name)
+			}
+		}
+	}
+	for name, target := range map[string]*bool{
+		"cover_visible": &comic.CoverVisible,
+		"track":         &comic.Track,
+		"deleted":       &comic.Deleted,
+	} {
+		if raw := value(name); raw != "" {
+			if *target, err = strconv.ParseBool(raw); err != nil {
+				return service.ComicJSON{}, fmt.Errorf("%s must be a boolean", name)
+			}
+		}
+	}
+	for name, target := range map[string]*[]int{
+		"published_in": &comic.PublishedIn,
+		"genres":       &comic.Genres,
+	} {
+		if raw := value(name); raw != "" {
+			for _, part := range strings.Split(raw, "|") {
+				id, err := strconv.Atoi(part)
+				if err != nil {
+					return service.ComicJSON{}, fmt.Errorf("%s must hold integers separated by |", name)
+				}
+				*target = append(*target, id)
+			}
+		}
+	}
+	return comic, nil
+}
@@ -0,0 +1,60 @@
+package route
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"comics/internal/service"
+	"comics/internal/tachiyomi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tachiyomiReport is the import report with the backup sources that match no
+// publisher
+type tachiyomiReport struct {
+	service.ImportReport
+	UnknownSources []string `json:"unknown_sources"`
+}
+
+// importTachiyomi applies a .tachibk backup of Tachiyomi or Mihon, uploaded
+// as the file field of a multipart form or as the raw body. With dry_run=true
+// the report is computed without writing anything.
+func importTachiyomi(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+		var body io.Reader = c.Request.Body
+		if c.ContentType() == gin.MIMEMultipartPOSTForm {
+			header, err := c.FormFile("file")
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": "the backup should be sent as the file field"})
+				return
+			}
+			file, err := header.Open()
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+				return
+			}
+			defer file.Close() // nolint:errcheck
+			body = file
+		}
+
+		backup, err := tachiyomi.Read(body)
+		if err != nil {
+			status := http.StatusRequestEntityTooLarge
+			if errors.Is(err, tachiyomi.ErrInvalidBackup) {
+				status = http.StatusBadRequest
+			}
+			c.JSON(status, gin.H{"message": err.Error()})
+			return
+		}
+		rows, unknown := backup.Comics()
+		report, err := comics.Import(c.Request.Context(), rows, queryBool(c, "dry_run"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, tachiyomiReport{ImportReport: report, UnknownSources: unknown})
+	}
+}
@@ -0,0 +1,231 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"comics/internal/scrape"
+	"comics/internal/service"
+	"comics/pkg/pb"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// publisherSiteRequest is the body of a site. On update the fields left out
+// keep their stored value, so a move only needs the new base_url: the
+// targets follow it and the old domain joins the previous ones. alive
+// defaults to true on creation.
+type publisherSiteRequest struct {
+	Publisher       int      `json:"publisher"`
+	BaseURL         string   `json:"base_url" binding:"required"`
+	PreviousDomains []string `json:"previous_domains"`
+	Targets         []string `json:"targets"`
+	SeriesTemplate  *string  `json:"series_template"`
+	ChapterTemplate *string  `json:"chapter_template"`
+	Alive           *bool    `json:"alive"`
+}
+
+// site applies the request to the stored site, the zero site on creation
+func (r publisherSiteRequest) site(current service.PublisherSite) service.PublisherSite {
+	site := current
+	site.Publisher = r.Publisher
+	site.BaseURL = r.BaseURL
+	if r.PreviousDomains != nil {
+		site.PreviousDomains = r.PreviousDomains
+	}
+	if r.Targets != nil {
+		site.Targets = r.Targets
+	}
+	if r.SeriesTemplate != nil {
+		site.SeriesTemplate = *r.SeriesTemplate
+	}
+	if r.ChapterTemplate != nil {
+		site.ChapterTemplate = *r.ChapterTemplate
+	}
+	if r.Alive != nil {
+		site.Alive = *r.Alive
+	}
+	return site
+}
+
+// publisherTargets stores the default sites of the publishers without one
+// and returns the pages to scrape of the stored sites, DefaultURLs when they
+// can't be read
+func publisherTargets(comics *service.SQLiteComicService) map[pb.Publisher][]string {
+	ctx := context.Background()
+	if seeded, err := comics.SeedPublisherSites(ctx, scrape.DefaultSites()); err != nil {
+		log.Warn().Err(err).Msg("Failed to seed publisher sites")
+	} else if seeded > 0 {
+		log.Info().Int("sites", seeded).Msg("Publisher sites seeded")
+	}
+	sites, err := comics.PublisherSites(ctx)
+	if err != nil {
+		log.Warn().Err(err).Msg("Publisher sites unavailable, scraping the default pages")
+		return scrape.DefaultURLs
+	}
+	return scrape.Targets(sites)
+}
+
+// publishersRouter manages where the publishers live, a new base URL moves
+// the covers and the scrape targets of the publisher to the new domain
+//
+//	@Summary		Publisher sites
+//	@Description	Lists, creates, updates and deletes the sites of the publishers: base URL,
+//	@Description	previous domains, scrape targets, series and chapter URL templates and alive status.
+//	@Description	The publisher is given by name or number. An update keeps the fields left out.
+//	@Description	The templates are stored for clients, the scrapers follow the links of the scraped pages.
+//	@ID				publisher-sites
+//	@Tags			Publishers
+//	@Security		Bearer JWT
+//	@Accept			json
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer JWT"	default(Bearer XXX)
+//	@Param			publisher		path		string					false	"Publisher name or number"
+//	@Param			site			body		publisherSiteRequest	false	"Publisher site"
+//	@Success		200				{object}	service.PublisherSite	"OK"
+//	@Success		201				{object}	service.PublisherSite	"Created"
+//	@Success		204				"Deleted"
+//	@Failure		400				{object}	map[string]string	"Invalid site"
+//	@Failure		404				{object}	map[string]string	"Site not found"
+//	@Failure		409				{object}	map[string]string	"Site already exists"
+//	@Router			/admin/publishers [get]
+//	@Router			/admin/publishers [post]
+//	@Router			/admin/publishers/{publisher} [get]
+//	@Router			/admin/publishers/{publisher} [put]
+//	@Router			/admin/publishers/{publisher} [delete]
+func publishersRouter(comics *service.SQLiteComicService, jobs *scrape.Jobs, group *gin.RouterGroup) {
+	if comics == nil {
+		log.Warn().Msg("Publisher routes disabled, no comics database available")
+		return
+	}
+	group.GET("/publishers", listPublisherSites(comics))
+	group.POST("/publishers", createPublisherSite(comics, jobs))
+	group.GET("/publishers/:publisher", getPublisherSite(comics))
+	group.PUT("/publishers/:publisher", updatePublisherSite(comics, jobs))
+	group.DELETE("/publishers/:publisher", deletePublisherSite(comics, jobs))
+}
+
+func listPublisherSites(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sites, err := comics.PublisherSites(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"publishers": sites})
+	}
+}
+
+func getPublisherSite(comics *service.SQLiteComicService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		publisher, ok := publisherParam(c)
+		if !ok {
+			return
+		}
+		site, err := comics.PublisherSite(c.Request.Context(), int(publisher))
+		if err != nil {
+			writePublisherSiteError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, site)
+	}
+}
+
+func createPublisherSite(comics *service.SQLiteComicService, jobs *scrape.Jobs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body publisherSiteRequest
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		site, err := comics.CreatePublisherSite(c.Request.Context(), body.site(service.PublisherSite{Alive: true}))
+		if err != nil {
+			writePublisherSiteError(c, err)
+			return
+		}
+		refreshScrapeTargets(c.Request.Context(), comics, jobs)
+		c.Header("Location", c.FullPath()+"/"+site.Name)
+		c.JSON(http.StatusCreated, site)
+	}
+}
+
+// updatePublisherSite changes the site of the publisher and answers with the
+// number of covers moved to the new domain
+func updatePublisherSite(comics *service.SQLiteComicService, jobs *scrape.Jobs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		publisher, ok := publisherParam(c)
+		if !ok {
+			return
+		}
+		var body publisherSiteRequest
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		current, err := comics.PublisherSite(c.Request.Context(), int(publisher))
+		if err != nil {
+			writePublisherSiteError(c, err)
+			return
+		}
+		body.Publisher = int(publisher)
+		site, rewritten, err := comics.UpdatePublisherSite(c.Request.Context(), body.site(current))
+		if err != nil {
+			writePublisherSiteError(c, err)
+			return
+		}
+		refreshScrapeTargets(c.Request.Context(), comics, jobs)
+		c.JSON(http.StatusOK, gin.H{"publisher": site, "covers_rewritten": rewritten})
+	}
+}
+
+func deletePublisherSite(comics *service.SQLiteComicService, jobs *scrape.Jobs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		publisher, ok := publisherParam(c)
+		if !ok {
+			return
+		}
+		if err := comics.DeletePublisherSite(c.Request.Context(), int(publisher)); err != nil {
+			writePublisherSiteError(c, err)
+			return
+		}
+		refreshScrapeTargets(c.Request.Context(), comics, jobs)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// refreshScrapeTargets points the new scrape jobs to the stored sites
+func refreshScrapeTargets(ctx context.Context, comics *service.SQLiteComicService, jobs *scrape.Jobs) {
+	if jobs == nil {
+		return
+	}
+	sites, err := comics.PublisherSites(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to refresh the scrape targets")
+		return
+	}
+	jobs.SetURLs(scrape.Targets(sites))
+}
+
+func publisherParam(c *gin.Context) (pb.Publisher, bool) {
+	publisher, err := scrape.ParsePublisher(c.Param("publisher"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return 0, false
+	}
+	return publisher, true
+}
+
+func writePublisherSiteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPublisherSiteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+	case errors.Is(err, service.ErrPublisherSiteExists):
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+	case errors.Is(err, service.ErrInvalidPublisherSite):
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	}
+}
@@ -0,0 +1,138 @@
+package route
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"comics/internal/scrape"
+	"comics/internal/service"
+	"comics/pkg/pb"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestComics opens a comics database with the table the Python side
+// creates
+func newTestComics(t *testing.T) *service.SQLiteComicService {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "comics.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE comics (
+		id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		titles TEXT NOT NULL,
+		current_chap INTEGER NOT NULL DEFAULT 0,
+		cover TEXT NOT NULL DEFAULT '',
+		last_update INTEGER NOT NULL,
+		com_type INTEGER NOT NULL DEFAULT 0,
+		status INTEGER NOT NULL DEFAULT 0,
+		published_in TEXT NOT NULL DEFAULT '0',
+		genres TEXT NOT NULL DEFAULT '0',
+		description TEXT NOT NULL DEFAULT '',
+		author TEXT NOT NULL DEFAULT '',
+		track BOOLEAN NOT NULL DEFAULT 0,
+		viewed_chap INTEGER NOT NULL DEFAULT 0,
+		rating INTEGER NOT NULL DEFAULT 0,
+		deleted BOOLEAN NOT NULL DEFAULT 0,
+		cover_visible BOOLEAN NOT NULL DEFAULT 1
+	)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	comics, err := service.NewSQLiteComicService(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { comics.Close() }) // nolint:errcheck
+	return comics
+}
+
+func serveJSON(router http.Handler, method string, path string, body any) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&payload).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPublishersRouter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	comics := newTestComics(t)
+	ctx := context.Background()
+	jobs, err := scrape.NewJobs(ctx, scrape.NewClient(), comics, publisherTargets(comics))
+	require.NoError(t, err)
+	_, err = comics.Create(ctx, service.ComicJSON{
+		Titles:      []string{"Nano Machine"},
+		Cover:       "https://asuracomic.net/storage/nano.webp",
+		PublishedIn: []int{int(pb.Publisher_ASURA)},
+	})
+	require.NoError(t, err)
+
+	router := gin.New()
+	publishersRouter(comics, jobs, router.Group("/admin"))
+
+	// A move only needs the new base URL, the seeded targets follow it
+	w := serveJSON(router, http.MethodPut, "/admin/publishers/asura", gin.H{"base_url": "https://asuracomic.org"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var moved struct {
+		Publisher       service.PublisherSite `json:"publisher"`
+		CoversRewritten int                   `json:"covers_rewritten"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &moved))
+	assert.Equal(t, 1, moved.CoversRewritten)
+	assert.Equal(t, []string{"/", "/page/2"}, moved.Publisher.Targets)
+	assert.Equal(t, []string{"asuracomic.net"}, moved.Publisher.PreviousDomains)
+	assert.True(t, moved.Publisher.Alive)
+	assert.Equal(t, []string{"https://asuracomic.org/", "https://asuracomic.org/page/2"},
+		jobs.URLs()[pb.Publisher_ASURA])
+
+	// Moving again keeps every previous domain
+	w = serveJSON(router, http.MethodPut, "/admin/publishers/1", gin.H{"base_url": "https://asura.gg", "alive": false})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &moved))
+	assert.Equal(t, []string{"asuracomic.net", "asuracomic.org"}, moved.Publisher.PreviousDomains)
+	assert.False(t, moved.Publisher.Alive)
+	assert.NotContains(t, jobs.URLs(), pb.Publisher_ASURA)
+	nano, err := comics.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "https://asura.gg/storage/nano.webp", nano.Cover)
+
+	w = serveJSON(router, http.MethodDelete, "/admin/publishers/REALM_SCANS", nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = serveJSON(router, http.MethodPost, "/admin/publishers", gin.H{
+		"publisher": int(pb.Publisher_REALM_SCANS),
+		"base_url":  "https://rizzfables.com",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "/admin/publishers/REALM_SCANS", w.Header().Get("Location"))
+
+	tests := []struct {
+		method string
+		path   string
+		body   any
+		code   int
+	}{
+		{http.MethodGet, "/admin/publishers/NOVEL_MIC", nil, http.StatusNotFound},
+		{http.MethodDelete, "/admin/publishers/NOVEL_MIC", nil, http.StatusNotFound},
+		{http.MethodPut, "/admin/publishers/NOVEL_MIC", gin.H{"base_url": "https://novelmic.com"}, http.StatusNotFound},
+		{http.MethodGet, "/admin/publishers/nobody", nil, http.StatusBadRequest},
+		{http.MethodPut, "/admin/publishers/ASURA", gin.H{}, http.StatusBadRequest},
+		{http.MethodPut, "/admin/publishers/ASURA", gin.H{"base_url": "asura.gg"}, http.StatusBadRequest},
+		{http.MethodPost, "/admin/publishers", gin.H{"publisher": 99, "base_url": "https://a.gg"}, http.StatusBadRequest},
+		{http.MethodPost, "/admin/publishers", gin.H{"publisher": 1, "base_url": "https://a.gg"}, http.StatusConflict},
+	}
+	for _, test := range tests {
+		w := serveJSON(router, test.method, test.path, test.body)
+		assert.Equal(t, test.code, w.Code, "%s %s: %s", test.method, test.path, w.Body.String())
+	}
+}
@@ -0,0 +1,75 @@
+package route
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+
+	"comics/bootstrap"
+	"comics/internal/quality"
+	"comics/internal/repo"
+	"comics/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// qualitySource picks the backend of the reports, Postgres when
+// COMICS_BACKEND=postgres and it answers within the init timeout, the SQLite
+// comics otherwise. The Postgres repo is closed with the app. It returns nil
+// when neither is available
+func qualitySource(ctx context.Context, app *bootstrap.Application, comics *service.SQLiteComicService) quality.Source {
+	if strings.EqualFold(os.Getenv("COMICS_BACKEND"), "postgres") {
+		// NewComicsRepo pings until the context ends
+		initCtx, cancel := context.WithTimeout(ctx, app.Env.InitCtxTimeout)
+		comicsRepo, err := repo.NewComicsRepo(initCtx, nil, nil)
+		cancel()
+		if err == nil {
+			app.OnClose(comicsRepo.Close)
+			return comicsRepo
+		}
+		log.Warn().Err(err).Msg("Postgres comics unavailable, reports fall back to SQLite")
+	}
+	if comics == nil {
+		return nil
+	}
+	return comics
+}
+
+// reportsRouter returns the data quality report of the comics
+//
+//	@Summary		Data quality report
+//	@Description	Returns counts, percentages and offending comic IDs of each data quality check,
+//	@Description	as JSON when requested through the Accept header and as an HTML view otherwise
+//	@ID				data-quality-report
+//	@Tags			Reports
+//	@Security		Bearer JWT
+//	@Produce		json,html
+//	@Param			Authorization	header		string			true	"Bearer JWT"	default(Bearer XXX)
+//	@Success		200				{object}	quality.Report	"OK"
+//	@Failure		500				{object}	map[string]string	"Report failed"
+//	@Router			/admin/reports/data-quality [get]
+func reportsRouter(source quality.Source, group *gin.RouterGroup) {
+	if source == nil {
+		log.Warn().Msg("Report routes disabled, no comics database available")
+		return
+	}
+	group.GET("/reports/data-quality", func(c *gin.Context) {
+		report, err := source.DataQuality(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+
+		if c.GetHeader(keyAccept) == contentTypeJSON {
+			c.JSON(http.StatusOK, report)
+			return
+		}
+		otelgin.HTML(c, http.StatusOK, "data_quality.html", gin.H{
+			"Title":  "Data Quality Report",
+			"Report": report,
+		})
+	})
+}
@@ -1,6 +1,7 @@
 package route
 
 import (
+	"context"
 	"net/http"
 
 	"comics/api/controller"
@@ -9,6 +10,7 @@ import (
 	"comics/docs"
 	"comics/domain"
 	"comics/internal/health"
+	"comics/internal/scrape"
 	"comics/internal/service"
 	"comics/internal/tokenutil"
 
@@ -27,6 +29,9 @@ const (
 	// Tracing
 	tracingServiceName = "comics_router"
 
+	// Metrics
+	metricsNamespace = "comics"
+
 	// Headers
 	keyRole          = middleware.KeyRole
 	keyAuthorization = middleware.KeyAuthorization
@@ -38,8 +43,10 @@ const (
 	cookieRefreshToken = middleware.KeyRefreshToken
 )
 
-// Setup configures the gin routes of the server
-func Setup(env *bootstrap.Env, userRepo domain.UserStore, g *gin.Engine) {
+// Setup configures the gin routes of the server, the background work they
+// start stops with ctx and their resources are closed with the app
+func Setup(ctx context.Context, app *bootstrap.Application, g *gin.Engine) {
+	env, userRepo := app.Env, app.UserRepo
 
 	// Starting user service and inject it into auth controller
 	userService := service.NewUserService(userRepo, env)
@@ -63,12 +70,17 @@ func Setup(env *bootstrap.Env, userRepo domain.UserStore, g *gin.Engine) {
 	})
 
 	basePath := "/"
+	var comics *service.SQLiteComicService
+	var jobs *scrape.Jobs
+	var scheduler *scrape.Scheduler
 	publicRouter := g.Group(basePath)
 	setOAuth2(env, authController, publicRouter)
 	{ // All Public APIs
 		swaggerRouter(env, basePath, publicRouter)
 		metricsRouter(userRepo, publicRouter)
-		comicsRouter(publicRouter)
+		comics = comicsRouter(ctx, env, publicRouter)
+		jobs = scrapeRouter(ctx, app, comics, publicRouter)
+		scheduler = startScrapeScheduler(ctx, jobs)
 		signUpRouter(authController, publicRouter)
 		loginRouter(authController, publicRouter)
 		refreshTokenRouter(authController, publicRouter)
@@ -88,7 +100,10 @@ func Setup(env *bootstrap.Env, userRepo domain.UserStore, g *gin.Engine) {
 		middleware.AuthenticationMiddleware(env.JWTConfig.AccessTokenSecret),
 		middleware.RoleMiddleware(tokenutil.RoleAdmin))
 	{ // All admin APIs
-		dashboardRouter(userRepo, admin)
+		dashboardRouter(userRepo, scheduler, admin)
+		reportsRouter(qualitySource(ctx, app, comics), admin)
+		scheduleRouter(scheduler, admin)
+		publishersRouter(comics, jobs, admin)
 	}
 }
 
@@ -131,7 +146,8 @@ func swaggerRouter(env *bootstrap.Env, basePath string, group *gin.RouterGroup)
 		ginSwagger.WrapHandler(swaggerFiles.Handler))
 }
 
-// dashboardRouter returns a dashboard view for admins
+// dashboardRouter returns a dashboard view for admins, the HTML view also
+// shows the scrape schedules
 //
 //	@Summary		Dashboard
 //	@Description	Returns the admin dashboard, needs admin auth
@@ -145,7 +161,7 @@ func swaggerRouter(env *bootstrap.Env, basePath string, group *gin.RouterGroup)
 //	@Failure		400				{string}	string				"Not registered"
 //	@Failure		404				{string}	string				"Not implemented"
 //	@Router			/admin/dashboard [get]
-func dashboardRouter(userRepo domain.UserStore, group *gin.RouterGroup) {
+func dashboardRouter(userRepo domain.UserStore, scheduler *scrape.Scheduler, group *gin.RouterGroup) {
 	group.GET("/dashboard", func(c *gin.Context) {
 		// Fetch metrics from repository
 		metrics := userRepo.GetStats()
@@ -154,10 +170,15 @@ func dashboardRouter(userRepo domain.UserStore, group *gin.RouterGroup) {
 			c.JSON(http.StatusOK, metrics)
 			return
 		}
+		var schedules []scrape.ScheduleState
+		if scheduler != nil {
+			schedules = scheduler.States()
+		}
 		// Render HTML template with metrics data
 		otelgin.HTML(c, http.StatusOK, "dashboard.html", gin.H{
-			"Title":   "Database Metrics Dashboard",
-			"Metrics": metrics,
+			"Title":     "Database Metrics Dashboard",
+			"Metrics":   metrics,
+			"Schedules": schedules,
 		})
 	})
 }
@@ -0,0 +1,170 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"comics/bootstrap"
+	"comics/internal/metrics"
+	"comics/internal/scrape"
+	"comics/internal/service"
+	"comics/pkg/pb"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// scrapeRouter registers the scrape routes and returns the job runner, nil
+// when the comics are unavailable. The jobs are cancelled when ctx ends and
+// the app waits for them to save their state when it closes.
+func scrapeRouter(
+	ctx context.Context,
+	app *bootstrap.Application,
+	comics *service.SQLiteComicService,
+	group *gin.RouterGroup,
+) *scrape.Jobs {
+	if comics == nil {
+		return nil
+	}
+	client := scrape.NewPoliteClient(politeConfig(), metrics.NewScrapeMetrics(metricsNamespace))
+	urls := publisherTargets(comics)
+
+	jobs, err := scrape.NewJobs(ctx, client, comics, urls)
+	if err != nil {
+		log.Warn().Err(err).Msg("Scrape jobs disabled")
+		group.GET("/scrape", scrapeComics(client, comics, func() map[pb.Publisher][]string { return urls }))
+		return nil
+	}
+	group.GET("/scrape", scrapeComics(client, comics, jobs.URLs))
+	group.POST("/scrape/jobs", startScrapeJob(jobs))
+	group.GET("/scrape/jobs", listScrapeJobs(jobs))
+	group.GET("/scrape/jobs/:id", getScrapeJob(jobs))
+	group.DELETE("/scrape/jobs/:id", cancelScrapeJob(jobs))
+	app.OnClose(jobs.Wait)
+	return jobs
+}
+
+// politeConfig is the default politeness of the scrapers, with the requests
+// per second to each publisher host from COMICS_SCRAPE_RATE
+func politeConfig() scrape.PoliteConfig {
+	config := scrape.DefaultPoliteConfig()
+	if requestRate, err := strconv.ParseFloat(os.Getenv("COMICS_SCRAPE_RATE"), 64); err == nil && requestRate > 0 {
+		config.Rate = requestRate
+	}
+	return config
+}
+
+// scrapedPage summarizes a scraped page, the comics themselves are in the
+// import report
+type scrapedPage struct {
+	Publisher pb.Publisher `json:"publisher"`
+	URL       string       `json:"url"`
+	Comics    int          `json:"comics"`
+	Errors    []string     `json:"errors,omitempty"`
+}
+
+// scrapeComics forwards to the Python scrapers when PY_BACKEND_URL is set,
+// otherwise it scrapes the pages of urls with the Go scrapers and imports the
+// comics. With dry_run=true nothing is written. POST /scrape/jobs does the
+// same without holding the request.
+func scrapeComics(
+	client *scrape.Client,
+	comics *service.SQLiteComicService,
+	urls func() map[pb.Publisher][]string,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if os.Getenv("PY_BACKEND_URL") != "" {
+			proxyPythonScrape(c)
+			return
+		}
+
+		results := client.ScrapeAll(c.Request.Context(), urls())
+		report, err := comics.Import(c.Request.Context(), scrape.Comics(results), queryBool(c, "dry_run"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		pages := make([]scrapedPage, len(results))
+		for i, result := range results {
+			pages[i] = scrapedPage{
+				Publisher: result.Publisher,
+				URL:       result.URL,
+				Comics:    len(result.Comics),
+				Errors:    result.Errors,
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"pages": pages, "report": report})
+	}
+}
+
+// startScrapeJob queues a scrape of the publishers in the body, all of them
+// when none is given, and answers with the job right away
+func startScrapeJob(jobs *scrape.Jobs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Publishers []pb.Publisher `json:"publishers"`
+			DryRun     bool           `json:"dry_run"`
+		}
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&body); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+				return
+			}
+		}
+		job, err := jobs.Start(c.Request.Context(), body.Publishers, body.DryRun || queryBool(c, "dry_run"))
+		if errors.Is(err, scrape.ErrUnknownPublisher) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		c.Header("Location", fmt.Sprintf("%s/%d", c.FullPath(), job.ID))
+		c.JSON(http.StatusAccepted, job)
+	}
+}
+
+func listScrapeJobs(jobs *scrape.Jobs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := jobs.List(c.Request.Context(), queryInt(c, "limit", 20))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"jobs": list})
+	}
+}
+
+// getScrapeJob reports the progress of a job, saved after every page
+func getScrapeJob(jobs *scrape.Jobs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := jobs.Get(c.Request.Context(), pathInt(c, "id"))
+		writeScrapeJob(c, job, err)
+	}
+}
+
+// cancelScrapeJob stops a running job and answers once it is stopped
+func cancelScrapeJob(jobs *scrape.Jobs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		job, err := jobs.Cancel(c.Request.Context(), pathInt(c, "id"))
+		writeScrapeJob(c, job, err)
+	}
+}
+
+func writeScrapeJob(c *gin.Context, job service.ScrapeJob, err error) {
+	switch {
+	case errors.Is(err, service.ErrScrapeJobNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+	case errors.Is(err, scrape.ErrJobFinished):
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error(), "job": job})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+	default:
+		c.JSON(http.StatusOK, job)
+	}
+}
@@ -0,0 +1,122 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"comics/internal/scrape"
+	"comics/pkg/pb"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// startScrapeScheduler schedules the scrape jobs from the environment:
+// COMICS_SCRAPE_INTERVAL is the interval of every publisher and
+// COMICS_SCRAPE_INTERVALS overrides it per publisher, like
+// "ASURA=30m,FLAME_SCANS=@daily,REALM_SCANS=off". COMICS_SCRAPE_JITTER adds
+// up to that much to each interval and COMICS_SCRAPE_MAX_CONCURRENT caps the
+// publishers scraped at once. The scheduler stops with ctx, it returns nil
+// when nothing is scheduled.
+func startScrapeScheduler(ctx context.Context, jobs *scrape.Jobs) *scrape.Scheduler {
+	if jobs == nil {
+		return nil
+	}
+	intervals, err := scrape.ParseIntervals(os.Getenv("COMICS_SCRAPE_INTERVAL"), os.Getenv("COMICS_SCRAPE_INTERVALS"))
+	if err != nil {
+		log.Warn().Err(err).Msg("Scrape scheduler disabled")
+		return nil
+	}
+	if len(intervals) == 0 {
+		return nil
+	}
+	jitter, err := time.ParseDuration(os.Getenv("COMICS_SCRAPE_JITTER"))
+	if err != nil || jitter < 0 {
+		jitter = 0
+	}
+	maxConcurrent, err := strconv.Atoi(os.Getenv("COMICS_SCRAPE_MAX_CONCURRENT"))
+	if err != nil || maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+
+	scheduler := scrape.NewScheduler(jobs, scrape.SchedulerConfig{
+		Intervals:     intervals,
+		Jitter:        jitter,
+		MaxConcurrent: maxConcurrent,
+	})
+	go scheduler.Run(ctx)
+	log.Info().
+		Int("publishers", len(intervals)).
+		Dur("jitter", jitter).
+		Int("max_concurrent", maxConcurrent).
+		Msg("Scrape scheduler started")
+	return scheduler
+}
+
+// scheduleRouter manages the scrape schedules of the publishers
+//
+//	@Summary		Scrape schedules
+//	@Description	Lists the scrape schedules, pauses and resumes the scheduled runs of a publisher
+//	@Description	or triggers one now. The publisher is given by name or number.
+//	@ID				scrape-schedules
+//	@Tags			Scrape
+//	@Security		Bearer JWT
+//	@Produce		json
+//	@Param			Authorization	header		string					true	"Bearer JWT"	default(Bearer XXX)
+//	@Param			publisher		path		string					false	"Publisher name or number"
+//	@Success		200				{object}	scrape.ScheduleState	"OK"
+//	@Failure		400				{object}	map[string]string		"Unknown publisher"
+//	@Failure		404				{object}	map[string]string		"Publisher not scheduled"
+//	@Failure		409				{object}	map[string]string		"Publisher already being scraped"
+//	@Router			/admin/scrape/schedules [get]
+//	@Router			/admin/scrape/schedules/{publisher}/pause [post]
+//	@Router			/admin/scrape/schedules/{publisher}/resume [post]
+//	@Router			/admin/scrape/schedules/{publisher}/trigger [post]
+func scheduleRouter(scheduler *scrape.Scheduler, group *gin.RouterGroup) {
+	if scheduler == nil {
+		log.Warn().Msg("Scrape schedule routes disabled, nothing scheduled")
+		return
+	}
+	group.GET("/scrape/schedules", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"schedules": scheduler.States()})
+	})
+	group.POST("/scrape/schedules/:publisher/pause", changeSchedule(
+		func(_ *gin.Context, publisher pb.Publisher) (scrape.ScheduleState, error) {
+			return scheduler.Pause(publisher)
+		}))
+	group.POST("/scrape/schedules/:publisher/resume", changeSchedule(
+		func(_ *gin.Context, publisher pb.Publisher) (scrape.ScheduleState, error) {
+			return scheduler.Resume(publisher)
+		}))
+	group.POST("/scrape/schedules/:publisher/trigger", changeSchedule(
+		func(c *gin.Context, publisher pb.Publisher) (scrape.ScheduleState, error) {
+			return scheduler.Trigger(c.Request.Context(), publisher)
+		}))
+}
+
+// changeSchedule applies change to the schedule of the publisher in the path
+// and answers with the new state
+func changeSchedule(change func(*gin.Context, pb.Publisher) (scrape.ScheduleState, error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		publisher, err := scrape.ParsePublisher(c.Param("publisher"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+			return
+		}
+		state, err := change(c, publisher)
+		switch {
+		case errors.Is(err, scrape.ErrNoSchedule):
+			c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+		case errors.Is(err, scrape.ErrAlreadyRunning):
+			c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"message": err.Error()})
+		default:
+			c.JSON(http.StatusOK, state)
+		}
+	}
+}
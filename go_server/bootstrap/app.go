@@ -59,6 +59,12 @@ func MustLoadApp(ctx context.Context) Application {
 	}
 }
 
+// OnClose closes a resource with the application, before the ones already
+// registered so the logger and the user repo stay available to it
+func (app *Application) OnClose(shutter func(context.Context) error) {
+	app.Shutters = append([]func(context.Context) error{shutter}, app.Shutters...)
+}
+
 // Close closes the application resources
 func (app *Application) Close(ctx context.Context) {
 	// Context with timeout for the shutdown
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+
+	"comics/internal/service"
+	"comics/internal/tachiyomi"
+
+	"github.com/joho/godotenv"
+)
+
+// command is a subcommand of the cli, run gets the arguments after its name
+type command struct {
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+const importTachiyomiUsage = "import-tachiyomi [-db path] [-dry-run] backup.tachibk"
+
+var commands = map[string]command{
+	"import-tachiyomi": {
+		usage: importTachiyomiUsage,
+		run:   importTachiyomi,
+	},
+}
+
+func usage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	text := "Wrong Usage, command should be one of:"
+	for _, name := range names {
+		text += "\n  comics " + commands[name].usage
+	}
+	return text
+}
+
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found: %v", err)
+	}
+
+	if len(os.Args) < 2 {
+		log.Fatal(usage())
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		log.Fatal(usage())
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := cmd.run(ctx, os.Args[2:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// importTachiyomi applies a Tachiyomi or Mihon backup to the sqlite library
+// and prints the import report as JSON
+func importTachiyomi(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("import-tachiyomi", flag.ContinueOnError)
+	dbPath := flags.String("db", os.Getenv("COMICS_SQLITE_PATH"), "path of the sqlite database")
+	dryRun := flags.Bool("dry-run", false, "report the changes without writing them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: comics %s", importTachiyomiUsage)
+	}
+
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close() // nolint:errcheck
+	backup, err := tachiyomi.Read(file)
+	if err != nil {
+		return err
+	}
+
+	comics, err := service.NewSQLiteComicService(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer comics.Close() // nolint:errcheck
+
+	rows, unknown := backup.Comics()
+	report, err := comics.Import(ctx, rows, *dryRun)
+	if err != nil {
+		return err
+	}
+	for _, source := range unknown {
+		log.Printf("Warning: source %q matches no publisher", source)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
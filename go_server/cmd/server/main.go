@@ -51,7 +51,7 @@ func main() {
 	g := gin.New()
 	g.Use(gin.Recovery())
 	// Route binding
-	route.Setup(app.Env, app.UserRepo, g)
+	route.Setup(ctx, &app, g)
 
 	// Running the server
 	srvErr := make(chan error, 1)
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -68,6 +69,19 @@ func createResponseMetadata(ctx context.Context, startTime time.Time, code codes
 	}
 }
 
+// comicETag formats the version tag of a comic, same format as the REST API
+func comicETag(id, version uint32) string {
+	return fmt.Sprintf(`"%d-%d"`, id, version)
+}
+
+// optionalString returns nil for an empty string so optional fields stay unset
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
 // handleError creates an error response with proper metadata
 func handleError(ctx context.Context, startTime time.Time, err error) (*pb.ComicResponse, error) {
 	st, _ := status.FromError(err)
@@ -133,6 +147,11 @@ func (s *comicsService) GetComicByID(ctx context.Context, req *pb.GetComicByIdRe
 		}
 		return handleError(ctx, startTime, fmt.Errorf("failed to get comic: %w", err))
 	}
+	version, err := s.repo.GetComicVersion(ctx, req.Id)
+	if err != nil {
+		return handleError(ctx, startTime, fmt.Errorf("failed to get comic version: %w", err))
+	}
+	etag := comicETag(req.Id, version)
 
 	duration := time.Since(startTime).Seconds()
 	requestDuration.WithLabelValues("GetComicByID", codes.OK.String()).Observe(duration)
@@ -141,6 +160,7 @@ func (s *comicsService) GetComicByID(ctx context.Context, req *pb.GetComicByIdRe
 	return &pb.ComicResponse{
 		Metadata: createResponseMetadata(ctx, startTime, codes.OK),
 		Comic:    comic,
+		Etag:     &etag,
 	}, nil
 }
 
@@ -156,8 +176,8 @@ func (s *comicsService) GetComics(ctx context.Context, req *pb.GetComicsRequest)
 		attribute.Int("page_size", int(req.Pagination.PageSize)),
 	)
 
-	// Validate pagination
-	if err := validatePagination(req.Pagination); err != nil {
+	// Validate request
+	if err := validateGetComicsRequest(req); err != nil {
 		errMsg := err.Error()
 		return &pb.ComicsResponse{
 			Metadata: createResponseMetadata(ctx, startTime, codes.InvalidArgument),
@@ -166,7 +186,23 @@ func (s *comicsService) GetComics(ctx context.Context, req *pb.GetComicsRequest)
 	}
 
 	// Get comics from database
-	comics, total, err := s.repo.GetComics(ctx, int(req.Pagination.Page), int(req.Pagination.PageSize), false, false)
+	comics, total, nextPageToken, err := s.repo.GetComics(
+		ctx,
+		int(req.Pagination.Page),
+		int(req.Pagination.PageSize),
+		req.Pagination.GetPageToken(),
+		req.GetSortOrder(),
+		req.GetTrackedOnly(),
+		req.GetUncheckedOnly(),
+	)
+	if errors.Is(err, repo.ErrInvalidPageParams) {
+		err = status.Error(codes.InvalidArgument, "invalid page token")
+		errMsg := err.Error()
+		return &pb.ComicsResponse{
+			Metadata: createResponseMetadata(ctx, startTime, codes.InvalidArgument),
+			Error:    &errMsg,
+		}, err
+	}
 	if err != nil {
 		errMsg := err.Error()
 		return &pb.ComicsResponse{
@@ -184,11 +220,12 @@ func (s *comicsService) GetComics(ctx context.Context, req *pb.GetComicsRequest)
 	totalPages := (totalCount + req.Pagination.PageSize - 1) / req.Pagination.PageSize
 
 	return &pb.ComicsResponse{
-		Metadata:    createResponseMetadata(ctx, startTime, codes.OK),
-		Comics:      comics,
-		TotalCount:  &totalCount,
-		TotalPages:  &totalPages,
-		CurrentPage: &req.Pagination.Page,
+		Metadata:      createResponseMetadata(ctx, startTime, codes.OK),
+		Comics:        comics,
+		TotalCount:    &totalCount,
+		TotalPages:    &totalPages,
+		CurrentPage:   &req.Pagination.Page,
+		NextPageToken: optionalString(nextPageToken),
 	}, nil
 }
 
@@ -214,7 +251,24 @@ func (s *comicsService) SearchComics(ctx context.Context, req *pb.SearchComicsRe
 	}
 
 	// Search comics in database
-	comics, total, err := s.repo.SearchComics(ctx, req.Query, int(req.Pagination.Page), int(req.Pagination.PageSize))
+	comics, total, nextPageToken, err := s.repo.SearchComics(
+		ctx,
+		req.Query,
+		int(req.Pagination.Page),
+		int(req.Pagination.PageSize),
+		req.Pagination.GetPageToken(),
+		req.GetSortOrder(),
+		req.GetTrackedOnly(),
+		req.GetUncheckedOnly(),
+	)
+	if errors.Is(err, repo.ErrInvalidPageParams) {
+		err = status.Error(codes.InvalidArgument, "invalid page token")
+		errMsg := err.Error()
+		return &pb.ComicsResponse{
+			Metadata: createResponseMetadata(ctx, startTime, codes.InvalidArgument),
+			Error:    &errMsg,
+		}, err
+	}
 	if err != nil {
 		errMsg := err.Error()
 		return &pb.ComicsResponse{
@@ -231,11 +285,58 @@ func (s *comicsService) SearchComics(ctx context.Context, req *pb.SearchComicsRe
 	totalPages := (totalCount + req.Pagination.PageSize - 1) / req.Pagination.PageSize
 
 	return &pb.ComicsResponse{
-		Metadata:    createResponseMetadata(ctx, startTime, codes.OK),
-		Comics:      comics,
-		TotalCount:  &totalCount,
-		TotalPages:  &totalPages,
-		CurrentPage: &req.Pagination.Page,
+		Metadata:      createResponseMetadata(ctx, startTime, codes.OK),
+		Comics:        comics,
+		TotalCount:    &totalCount,
+		TotalPages:    &totalPages,
+		CurrentPage:   &req.Pagination.Page,
+		NextPageToken: optionalString(nextPageToken),
+	}, nil
+}
+
+// MarkComicsRead implements the MarkComicsRead RPC method
+func (s *comicsService) MarkComicsRead(ctx context.Context, req *pb.MarkComicsReadRequest) (*pb.MarkComicsReadResponse, error) {
+	startTime := time.Now()
+	ctx, span := tracer.Start(ctx, "MarkComicsRead")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("comics.ids", len(req.Ids)),
+		attribute.Bool("tracked_only", req.GetTrackedOnly()),
+		attribute.Bool("unchecked_only", req.GetUncheckedOnly()),
+	)
+
+	// Validate request
+	if err := validateMarkComicsReadRequest(req); err != nil {
+		errMsg := err.Error()
+		return &pb.MarkComicsReadResponse{
+			Metadata: createResponseMetadata(ctx, startTime, codes.InvalidArgument),
+			Error:    &errMsg,
+		}, err
+	}
+
+	// Catch up the selected comics
+	affected, err := s.repo.MarkComicsRead(ctx, req.Ids, repo.MarkComicsReadFilter{
+		TrackedOnly:   req.GetTrackedOnly(),
+		UncheckedOnly: req.GetUncheckedOnly(),
+		Publisher:     req.GetPublisher(),
+		Genre:         req.GetGenre(),
+	})
+	if err != nil {
+		errMsg := err.Error()
+		return &pb.MarkComicsReadResponse{
+			Metadata: createResponseMetadata(ctx, startTime, codes.Internal),
+			Error:    &errMsg,
+		}, err
+	}
+
+	duration := time.Since(startTime).Seconds()
+	requestDuration.WithLabelValues("MarkComicsRead", codes.OK.String()).Observe(duration)
+	requestTotal.WithLabelValues("MarkComicsRead", codes.OK.String()).Inc()
+
+	return &pb.MarkComicsReadResponse{
+		Metadata: createResponseMetadata(ctx, startTime, codes.OK),
+		Affected: uint32(affected), // #nosec G115
 	}, nil
 }
 
@@ -255,14 +356,26 @@ func (s *comicsService) UpdateComic(ctx context.Context, req *pb.UpdateComicRequ
 		return handleError(ctx, startTime, err)
 	}
 
+	// Only update while the stored version matches the If-Match etag
+	version, err := parseIfMatch(req.GetIfMatch(), req.Id)
+	if err != nil {
+		return handleError(ctx, startTime, err)
+	}
+
 	// Update comic in database
-	err := s.repo.UpdateComic(ctx, req.Comic)
+	req.Comic.Id = req.Id
+	version, err = s.repo.UpdateComicIfVersion(ctx, req.Comic, version)
 	if err != nil {
-		if err.Error() == "comic not found" {
+		if errors.Is(err, repo.ErrNotFound) {
 			return handleError(ctx, startTime, status.Error(codes.NotFound, "comic not found"))
 		}
+		if errors.Is(err, repo.ErrVersionConflict) {
+			return handleError(ctx, startTime,
+				status.Error(codes.FailedPrecondition, "comic was modified, precondition failed"))
+		}
 		return handleError(ctx, startTime, fmt.Errorf("failed to update comic: %w", err))
 	}
+	etag := comicETag(req.Id, version)
 
 	duration := time.Since(startTime).Seconds()
 	requestDuration.WithLabelValues("UpdateComic", codes.OK.String()).Observe(duration)
@@ -271,6 +384,7 @@ func (s *comicsService) UpdateComic(ctx context.Context, req *pb.UpdateComicRequ
 	return &pb.ComicResponse{
 		Metadata: createResponseMetadata(ctx, startTime, codes.OK),
 		Comic:    req.Comic,
+		Etag:     &etag,
 	}, nil
 }
 
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	pb "comics/pkg/pb"
@@ -69,7 +70,8 @@ func validatePagination(pagination *pb.PaginationRequest) error {
 		return status.Error(codes.InvalidArgument, "pagination is required")
 	}
 
-	if pagination.Page <= 0 {
+	// A page token replaces the page number
+	if pagination.Page <= 0 && pagination.GetPageToken() == "" {
 		return status.Error(codes.InvalidArgument, "page must be greater than 0")
 	}
 
@@ -94,9 +96,52 @@ func validateSearchRequest(req *pb.SearchComicsRequest) error {
 		return err
 	}
 
+	return ValidateSortOrder(req.GetSortOrder())
+}
+
+// validateGetComicsRequest validates a get comics request, relevance needs a
+// search query to rank against
+func validateGetComicsRequest(req *pb.GetComicsRequest) error {
+	if err := validatePagination(req.Pagination); err != nil {
+		return err
+	}
+
+	if req.GetSortOrder() == pb.ComicSortOrder_RELEVANCE {
+		return status.Error(codes.InvalidArgument, "relevance sort order is only valid for search")
+	}
+
+	return ValidateSortOrder(req.GetSortOrder())
+}
+
+// validateMarkComicsReadRequest rejects requests that would select every comic
+func validateMarkComicsReadRequest(req *pb.MarkComicsReadRequest) error {
+	if len(req.Ids) > 0 {
+		return nil
+	}
+	if !req.GetTrackedOnly() && !req.GetUncheckedOnly() &&
+		req.GetPublisher() == pb.Publisher_PUBLISHER_UNKNOWN && req.GetGenre() == pb.Genre_GENRE_UNKNOWN {
+		return status.Error(codes.InvalidArgument, "ids or at least one filter are required")
+	}
 	return nil
 }
 
+// parseIfMatch extracts the expected version from an etag of the given comic,
+// an empty or "*" tag returns zero to skip the version check
+func parseIfMatch(ifMatch string, id uint32) (uint32, error) {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return 0, nil
+	}
+	var tagID, version uint32
+	if _, err := fmt.Sscanf(ifMatch, `"%d-%d"`, &tagID, &version); err != nil || version == 0 {
+		return 0, status.Error(codes.InvalidArgument, "invalid if_match etag")
+	}
+	if tagID != id {
+		return 0, status.Error(codes.FailedPrecondition, "if_match etag belongs to another comic")
+	}
+	return version, nil
+}
+
 // ValidateSortOrder validates the sort order
 func ValidateSortOrder(order pb.ComicSortOrder) error {
 	switch order {
@@ -0,0 +1,156 @@
+// Package identity decides when two comics are the same series. It mirrors
+// src/db/identity.py so that comics written by the Go and the Python services
+// get the same identity keys, see the shared golden corpus in
+// test/identity_golden.json. The Go services store the titles as they are
+// given, NormalizeTitles is the form the Python service stores and the
+// quality report compares them against.
+package identity
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// NovelType is the com_type of novels, they never share an identity with
+	// the comic adaptation of the same title
+	NovelType      = 4
+	NovelSuffix    = " - novel"
+	NovelPrefix    = "novel:"
+	SeriesPrefix   = "series:"
+	titleSeparator = "|"
+)
+
+var (
+	novelMarker    = regexp.MustCompile(`(?i)\(\s*novel\s*\)|-\s*novel\s*$`)
+	leadingArticle = regexp.MustCompile(`(?i)^(the|a|an)\s+`)
+	nonMatchChars  = regexp.MustCompile(`[^a-z0-9]+`)
+
+	smartQuotes = strings.NewReplacer(
+		"‘", "'",
+		"’", "'",
+		"“", `"`,
+		"”", `"`,
+		"–", "-",
+		"—", "-",
+	)
+)
+
+// NormalizeText applies NFKC, replaces smart quotes and dashes with their
+// ASCII forms and collapses whitespace, like helpers.text.normalize_text.
+func NormalizeText(value string) string {
+	text := smartQuotes.Replace(norm.NFKC.String(value))
+	return strings.Join(strings.FieldsFunc(text, unicode.IsSpace), " ")
+}
+
+// SplitTitles splits the pipe separated titles column.
+func SplitTitles(titles string) []string {
+	return strings.Split(titles, titleSeparator)
+}
+
+// StripNovelMarker removes a "(novel)" or trailing "- novel" marker.
+func StripNovelMarker(title string) string {
+	normalized := NormalizeText(title)
+	if normalized == "" {
+		return ""
+	}
+	return NormalizeText(novelMarker.ReplaceAllString(normalized, ""))
+}
+
+// HasNovelMarker reports whether the title is marked as a novel.
+func HasNovelMarker(title string) bool {
+	return novelMarker.MatchString(NormalizeText(title))
+}
+
+// IsNovel reports whether the title and type describe a novel.
+func IsNovel(title string, comType int) bool {
+	return comType == NovelType || HasNovelMarker(title)
+}
+
+// NormalizeTitle returns the title as stored: without novel markers and in
+// sentence case. The primary title of a novel gets the novel suffix back.
+func NormalizeTitle(title string, comType int, primary bool) string {
+	cleaned := StripNovelMarker(title)
+	if cleaned == "" {
+		return ""
+	}
+	if primary && IsNovel(title, comType) {
+		cleaned += NovelSuffix
+	}
+	return capitalize(cleaned)
+}
+
+// NormalizeTitles normalizes every title, dropping the empty and repeated
+// ones. Only the first title is treated as the primary one.
+func NormalizeTitles(titles []string, comType int) []string {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for i, title := range titles {
+		value := NormalizeTitle(title, comType, i == 0)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		normalized = append(normalized, value)
+	}
+	return normalized
+}
+
+// PrimaryTitle returns the first non-empty title.
+func PrimaryTitle(titles []string) string {
+	for _, title := range titles {
+		if normalized := NormalizeText(title); normalized != "" {
+			return normalized
+		}
+	}
+	return ""
+}
+
+// NormalizePrimaryTitle lowercases the title without its novel marker.
+func NormalizePrimaryTitle(title string) string {
+	return strings.ToLower(StripNovelMarker(title))
+}
+
+// MatchKey reduces a title to its letters and digits, without a leading
+// article, to compare titles written slightly differently.
+func MatchKey(title string) string {
+	normalized := leadingArticle.ReplaceAllString(NormalizePrimaryTitle(title), "")
+	return nonMatchChars.ReplaceAllString(normalized, "")
+}
+
+// IsPrefixMatch reports whether the incoming title is the start of the
+// stored one, publishers often truncate long titles.
+func IsPrefixMatch(incoming string, stored string) bool {
+	incomingKey := MatchKey(incoming)
+	storedKey := MatchKey(stored)
+	return incomingKey != "" && storedKey != "" && strings.HasPrefix(storedKey, incomingKey)
+}
+
+// Key returns the identity of a series from its primary title, novels and
+// comics with the same title get different keys. Empty titles have no key.
+func Key(primaryTitle string, comType int) string {
+	normalized := NormalizePrimaryTitle(primaryTitle)
+	if normalized == "" {
+		return ""
+	}
+	if IsNovel(primaryTitle, comType) {
+		return NovelPrefix + normalized
+	}
+	return SeriesPrefix + normalized
+}
+
+// KeyFromTitles returns the identity key of a list of titles.
+func KeyFromTitles(titles []string, comType int) string {
+	return Key(PrimaryTitle(titles), comType)
+}
+
+// capitalize works like Python's str.capitalize, the first letter in title
+// case and the rest in lower case.
+func capitalize(value string) string {
+	first, size := utf8.DecodeRuneInString(value)
+	return string(unicode.ToTitle(first)) + strings.ToLower(value[size:])
+}
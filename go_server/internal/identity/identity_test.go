@@ -0,0 +1,66 @@
+package identity
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goldenCorpus is shared with test/identity_test.py, both implementations
+// must produce the same values
+const goldenCorpus = "../../../test/identity_golden.json"
+
+type golden struct {
+	Titles []struct {
+		Titles           []string `json:"titles"`
+		ComType          int      `json:"com_type"`
+		NormalizedTitles []string `json:"normalized_titles"`
+		IdentityKey      string   `json:"identity_key"`
+		MatchKeys        []string `json:"match_keys"`
+	} `json:"titles"`
+	PrefixMatches []struct {
+		Incoming string `json:"incoming"`
+		Stored   string `json:"stored"`
+		Match    bool   `json:"match"`
+	} `json:"prefix_matches"`
+}
+
+func loadGolden(t *testing.T) golden {
+	t.Helper()
+	data, err := os.ReadFile(goldenCorpus)
+	require.NoError(t, err)
+	var corpus golden
+	require.NoError(t, json.Unmarshal(data, &corpus))
+	require.NotEmpty(t, corpus.Titles)
+	return corpus
+}
+
+func TestGoldenTitles(t *testing.T) {
+	t.Parallel()
+	for _, tc := range loadGolden(t).Titles {
+		assert.Equal(t, tc.NormalizedTitles, NormalizeTitles(tc.Titles, tc.ComType), "titles %q", tc.Titles)
+		assert.Equal(t, tc.IdentityKey, KeyFromTitles(tc.Titles, tc.ComType), "titles %q", tc.Titles)
+		for i, title := range tc.Titles {
+			assert.Equal(t, tc.MatchKeys[i], MatchKey(title), "title %q", title)
+		}
+	}
+}
+
+func TestGoldenPrefixMatches(t *testing.T) {
+	t.Parallel()
+	for _, tc := range loadGolden(t).PrefixMatches {
+		assert.Equal(t, tc.Match, IsPrefixMatch(tc.Incoming, tc.Stored), "%q in %q", tc.Incoming, tc.Stored)
+	}
+}
+
+func TestNovelsAndComicsHaveDifferentKeys(t *testing.T) {
+	t.Parallel()
+	title := "A mercenary's rebirth among nobles"
+	assert.Equal(t, "series:a mercenary's rebirth among nobles", Key(title, 3))
+	assert.Equal(t, "novel:a mercenary's rebirth among nobles", Key(title, NovelType))
+	assert.Equal(t, Key(title, NovelType), Key(title+" (Novel)", 3))
+	assert.Empty(t, Key("  ", 0))
+}
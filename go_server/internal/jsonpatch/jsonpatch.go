@@ -0,0 +1,318 @@
+// Package jsonpatch applies RFC 7396 JSON Merge Patch and RFC 6902 JSON Patch
+// documents to values decoded by encoding/json.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media types of the supported patch formats
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+// ErrTestFailed is wrapped by the Error of a failed "test" operation
+var ErrTestFailed = errors.New("test failed")
+
+// Error reports a failure together with the JSON pointer it happened at
+type Error struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	Err     error  `json:"-"`
+}
+
+func (e *Error) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "/"
+	}
+	return fmt.Sprintf("%s: %s", path, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func errorf(path string, format string, args ...any) *Error {
+	return &Error{Path: path, Message: fmt.Sprintf(format, args...)}
+}
+
+// Operation is a single RFC 6902 operation
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// MergePatch applies an RFC 7396 merge patch to the target document
+func MergePatch(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+	result := make(map[string]any, len(targetObject))
+	for key, value := range targetObject {
+		result[key] = value
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = MergePatch(result[key], value)
+	}
+	return result
+}
+
+// DecodeOperations parses an RFC 6902 patch document
+func DecodeOperations(data []byte) ([]Operation, error) {
+	var operations []Operation
+	if err := json.Unmarshal(data, &operations); err != nil {
+		return nil, errorf("", "patch must be an array of operations")
+	}
+	return operations, nil
+}
+
+// Apply runs every operation in order, the document is left untouched when
+// any of them fails
+func Apply(doc any, operations []Operation) (any, error) {
+	var err error
+	doc = deepCopy(doc)
+	for i, operation := range operations {
+		doc, err = apply(doc, operation)
+		if err != nil {
+			if patchErr, ok := err.(*Error); ok {
+				patchErr.Message = fmt.Sprintf("operation %d (%s): %s", i, operation.Op, patchErr.Message)
+			}
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+func apply(doc any, operation Operation) (any, error) {
+	tokens, err := parsePointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+	switch operation.Op {
+	case "add", "replace", "test":
+		if len(operation.Value) == 0 {
+			return nil, errorf(operation.Path, "missing value")
+		}
+		var value any
+		if err := json.Unmarshal(operation.Value, &value); err != nil {
+			return nil, errorf(operation.Path, "invalid value")
+		}
+		switch operation.Op {
+		case "add":
+			return add(doc, tokens, value, operation.Path)
+		case "replace":
+			if _, err := get(doc, tokens, operation.Path); err != nil {
+				return nil, err
+			}
+			doc, err = remove(doc, tokens, operation.Path)
+			if err != nil {
+				return nil, err
+			}
+			return add(doc, tokens, value, operation.Path)
+		default:
+			current, err := get(doc, tokens, operation.Path)
+			if err != nil {
+				return nil, err
+			}
+			if !equal(current, value) {
+				return nil, &Error{Path: operation.Path, Message: ErrTestFailed.Error(), Err: ErrTestFailed}
+			}
+			return doc, nil
+		}
+	case "remove":
+		return remove(doc, tokens, operation.Path)
+	case "move", "copy":
+		fromTokens, err := parsePointer(operation.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(doc, fromTokens, operation.From)
+		if err != nil {
+			return nil, err
+		}
+		if operation.Op == "move" {
+			if strings.HasPrefix(operation.Path, operation.From+"/") {
+				return nil, errorf(operation.Path, "cannot move a value into one of its children")
+			}
+			doc, err = remove(doc, fromTokens, operation.From)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return add(doc, tokens, deepCopy(value), operation.Path)
+	default:
+		return nil, errorf(operation.Path, "unknown operation %q", operation.Op)
+	}
+}
+
+// parsePointer splits an RFC 6901 JSON pointer into unescaped tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errorf(pointer, "invalid JSON pointer")
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func get(doc any, tokens []string, path string) (any, error) {
+	current := doc
+	for _, token := range tokens {
+		switch node := current.(type) {
+		case map[string]any:
+			value, ok := node[token]
+			if !ok {
+				return nil, errorf(path, "path not found")
+			}
+			current = value
+		case []any:
+			index, err := arrayIndex(token, len(node)-1, path)
+			if err != nil {
+				return nil, err
+			}
+			current = node[index]
+		default:
+			return nil, errorf(path, "path not found")
+		}
+	}
+	return current, nil
+}
+
+func add(doc any, tokens []string, value any, path string) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, tokens[:len(tokens)-1], path)
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		node[last] = value
+		return doc, nil
+	case []any:
+		index := len(node)
+		if last != "-" {
+			index, err = arrayIndex(last, len(node), path)
+			if err != nil {
+				return nil, err
+			}
+		}
+		updated := append(node[:index:index], append([]any{value}, node[index:]...)...)
+		return replaceParent(doc, tokens[:len(tokens)-1], updated, path)
+	default:
+		return nil, errorf(path, "parent is not a container")
+	}
+}
+
+func remove(doc any, tokens []string, path string) (any, error) {
+	if len(tokens) == 0 {
+		return nil, errorf(path, "cannot remove the whole document")
+	}
+	parent, err := get(doc, tokens[:len(tokens)-1], path)
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]any:
+		if _, ok := node[last]; !ok {
+			return nil, errorf(path, "path not found")
+		}
+		delete(node, last)
+		return doc, nil
+	case []any:
+		index, err := arrayIndex(last, len(node)-1, path)
+		if err != nil {
+			return nil, err
+		}
+		updated := append(node[:index:index], node[index+1:]...)
+		return replaceParent(doc, tokens[:len(tokens)-1], updated, path)
+	default:
+		return nil, errorf(path, "parent is not a container")
+	}
+}
+
+// replaceParent stores a resized array back into its container
+func replaceParent(doc any, tokens []string, value []any, path string) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	grandParent, err := get(doc, tokens[:len(tokens)-1], path)
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := grandParent.(type) {
+	case map[string]any:
+		node[last] = value
+	case []any:
+		index, err := arrayIndex(last, len(node)-1, path)
+		if err != nil {
+			return nil, err
+		}
+		node[index] = value
+	}
+	return doc, nil
+}
+
+func arrayIndex(token string, maxIndex int, path string) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, errorf(path, "invalid array index %q", token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, errorf(path, "invalid array index %q", token)
+	}
+	if index > maxIndex {
+		return 0, errorf(path, "array index %d out of bounds", index)
+	}
+	return index, nil
+}
+
+func deepCopy(value any) any {
+	switch node := value.(type) {
+	case map[string]any:
+		copied := make(map[string]any, len(node))
+		for key, item := range node {
+			copied[key] = deepCopy(item)
+		}
+		return copied
+	case []any:
+		copied := make([]any, len(node))
+		for i, item := range node {
+			copied[i] = deepCopy(item)
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+func equal(a any, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,105 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decode(t *testing.T, data string) any {
+	t.Helper()
+	var value any
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestMergePatch(t *testing.T) {
+	t.Parallel()
+	// Cases taken from RFC 7396 appendix A
+	cases := []struct{ target, patch, result string }{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tc := range cases {
+		result := MergePatch(decode(t, tc.target), decode(t, tc.patch))
+		assert.Equal(t, decode(t, tc.result), result, "target %s patch %s", tc.target, tc.patch)
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+	cases := []struct{ doc, patch, result string }{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{`{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{`{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"qux"}]`, `{"foo":["bar","qux"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{`{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{`{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":null}]`, `{"baz":null,"foo":"bar"}`},
+		{`{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+			`[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+			`{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`},
+		{`{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`,
+			`{"foo":["all","cows","eat","grass"]}`},
+		{`{"foo":[1]}`, `[{"op":"copy","from":"/foo","path":"/bar"}]`, `{"foo":[1],"bar":[1]}`},
+		{`{"a/b":1,"m~n":2}`, `[{"op":"test","path":"/a~1b","value":1},{"op":"remove","path":"/m~0n"}]`, `{"a/b":1}`},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"","value":[1]}]`, `[1]`},
+	}
+	for _, tc := range cases {
+		operations, err := DecodeOperations([]byte(tc.patch))
+		assert.NoError(t, err)
+		result, err := Apply(decode(t, tc.doc), operations)
+		assert.NoError(t, err, tc.patch)
+		assert.Equal(t, decode(t, tc.result), result, tc.patch)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	t.Parallel()
+	cases := []struct{ doc, patch, path string }{
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/baz/bat","value":"qux"}]`, "/baz/bat"},
+		{`{"foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, "/baz"},
+		{`{"foo":"bar"}`, `[{"op":"replace","path":"/baz","value":1}]`, "/baz"},
+		{`{"foo":[1]}`, `[{"op":"add","path":"/foo/01","value":1}]`, "/foo/01"},
+		{`{"foo":[1]}`, `[{"op":"remove","path":"/foo/1"}]`, "/foo/1"},
+		{`{"foo":"bar"}`, `[{"op":"add","path":"/foo"}]`, "/foo"},
+		{`{"foo":"bar"}`, `[{"op":"frobnicate","path":"/foo"}]`, "/foo"},
+		{`{"foo":{"bar":1}}`, `[{"op":"move","from":"/foo","path":"/foo/bar/baz"}]`, "/foo/bar/baz"},
+	}
+	for _, tc := range cases {
+		operations, err := DecodeOperations([]byte(tc.patch))
+		assert.NoError(t, err)
+		_, err = Apply(decode(t, tc.doc), operations)
+		var patchErr *Error
+		if assert.True(t, errors.As(err, &patchErr), tc.patch) {
+			assert.Equal(t, tc.path, patchErr.Path, tc.patch)
+		}
+	}
+}
+
+func TestApplyTestFailureLeavesDocumentUntouched(t *testing.T) {
+	t.Parallel()
+	doc := decode(t, `{"foo":["bar"]}`)
+	operations, err := DecodeOperations([]byte(
+		`[{"op":"add","path":"/foo/-","value":"baz"},{"op":"test","path":"/foo/0","value":"qux"}]`,
+	))
+	assert.NoError(t, err)
+
+	_, err = Apply(doc, operations)
+	assert.ErrorIs(t, err, ErrTestFailed)
+	assert.Equal(t, decode(t, `{"foo":["bar"]}`), doc)
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog/log"
+)
+
+// Decisions of the outbound scrape requests
+const (
+	DecisionAllowed      = "allowed"
+	DecisionThrottled    = "throttled"
+	DecisionDisallowed   = "robots_disallowed"
+	DecisionCircuitOpen  = "circuit_open"
+	DecisionRetried      = "retried"
+	DecisionGaveUp       = "gave_up"
+	DecisionNotModified  = "not_modified"
+	DecisionCacheStored  = "cache_stored"
+	DecisionRobotsLoaded = "robots_loaded"
+)
+
+// States of the circuit breaker of a host, as set in the circuit_state gauge
+const (
+	CircuitClosed = iota
+	CircuitHalfOpen
+	CircuitOpen
+)
+
+// ScrapeCollector defines the interface for collecting the outbound scrape
+// metrics, labelled by host
+type ScrapeCollector interface {
+	RecordDecision(host, decision string)
+	RecordRequest(host string, status int, duration time.Duration)
+	RecordWait(host string, wait time.Duration)
+	SetCircuitState(host string, state int)
+}
+
+var _ ScrapeCollector = &ScrapeMetrics{}
+
+// ScrapeMetrics represents the metrics of the outbound scrape requests
+type ScrapeMetrics struct {
+	decisions       *prometheus.CounterVec
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	waitDuration    *prometheus.HistogramVec
+	circuitState    *prometheus.GaugeVec
+}
+
+// NewScrapeMetrics creates the scrape metrics. They are registered once, a
+// second call shares the collectors of the first.
+func NewScrapeMetrics(serviceName string) *ScrapeMetrics {
+	return &ScrapeMetrics{
+		decisions: register(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: serviceName,
+			Subsystem: "scrape",
+			Name:      "decisions_total",
+			Help:      "Decisions taken on outbound scrape requests",
+		}, []string{"host", "decision"})),
+
+		requests: register(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: serviceName,
+			Subsystem: "scrape",
+			Name:      "requests_total",
+			Help:      "Outbound scrape requests sent, by response status (0 for transport errors)",
+		}, []string{"host", "status"})),
+
+		requestDuration: register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: serviceName,
+			Subsystem: "scrape",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of outbound scrape requests in seconds",
+			Buckets:   prometheus.ExponentialBuckets(0.1, 2, 8), // From 100ms to ~13s
+		}, []string{"host"})),
+
+		waitDuration: register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: serviceName,
+			Subsystem: "scrape",
+			Name:      "rate_limit_wait_seconds",
+			Help:      "Time outbound scrape requests waited for the host rate limit in seconds",
+			Buckets:   prometheus.ExponentialBuckets(0.05, 2, 10), // From 50ms to ~25s
+		}, []string{"host"})),
+
+		circuitState: register(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: serviceName,
+			Subsystem: "scrape",
+			Name:      "circuit_state",
+			Help:      "Circuit breaker state of a host: 0 closed, 1 half open, 2 open",
+		}, []string{"host"})),
+	}
+}
+
+// RecordDecision records a decision taken on a request to the host
+func (m *ScrapeMetrics) RecordDecision(host, decision string) {
+	log.Trace().Str("host", host).Msgf("scrape decision: %s", decision)
+	m.decisions.WithLabelValues(host, decision).Inc()
+}
+
+// RecordRequest records a request sent to the host
+func (m *ScrapeMetrics) RecordRequest(host string, status int, duration time.Duration) {
+	m.requests.WithLabelValues(host, strconv.Itoa(status)).Inc()
+	m.requestDuration.WithLabelValues(host).Observe(duration.Seconds())
+}
+
+// RecordWait records the time a request waited for the rate limit of the host
+func (m *ScrapeMetrics) RecordWait(host string, wait time.Duration) {
+	m.waitDuration.WithLabelValues(host).Observe(wait.Seconds())
+}
+
+// SetCircuitState records the circuit breaker state of the host
+func (m *ScrapeMetrics) SetCircuitState(host string, state int) {
+	m.circuitState.WithLabelValues(host).Set(float64(state))
+}
+
+// register registers the collector in the default registry, or returns the
+// one already registered under the same name
+func register[T prometheus.Collector](collector T) T {
+	if err := prometheus.Register(collector); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if errors.As(err, &registered) {
+			if existing, ok := registered.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		log.Error().Err(err).Msg("Failed to register scrape metric")
+	}
+	return collector
+}
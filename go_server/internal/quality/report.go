@@ -0,0 +1,153 @@
+// Package quality computes the data quality report of the comics, the Go
+// counterpart of src/db/data_quality_report.py.
+package quality
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"comics/domain"
+	"comics/internal/identity"
+)
+
+// Names of the checks, in the order of the report
+const (
+	MissingTitles      = "missing_titles"
+	MissingAuthor      = "missing_author"
+	MissingDescription = "missing_description"
+	MissingCover       = "missing_cover"
+	ViewedOverCurrent  = "viewed_greater_than_current"
+	NegativeChapters   = "negative_chapters"
+	UnnormalizedTitles = "title_normalization_changes"
+	DuplicateTitleKeys = "duplicate_title_keys"
+)
+
+// percentagePrecision keeps two decimals in the percentages
+const percentagePrecision = 100
+
+// Source is a comics backend able to build the report
+type Source interface {
+	DataQuality(ctx context.Context) (Report, error)
+}
+
+// Check is the result of one check, Groups is only set by the duplicate
+// title keys check and holds the comics sharing each key.
+type Check struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Count       int              `json:"count"`
+	Percent     float64          `json:"percent"`
+	ComicIDs    []int            `json:"comic_ids"`
+	Groups      map[string][]int `json:"groups,omitempty"`
+}
+
+// Report lists the checks over the comics outside the trash
+type Report struct {
+	TotalComics int     `json:"total_comics"`
+	Checks      []Check `json:"checks"`
+}
+
+// Check returns the check with the given name
+func (r Report) Check(name string) (Check, bool) {
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return Check{}, false
+}
+
+type rule struct {
+	name        string
+	description string
+	fails       func(domain.Comic) bool
+}
+
+var rules = []rule{
+	{MissingTitles, "Comics without titles or with empty ones", func(c domain.Comic) bool {
+		return len(c.Titles) == 0 || slices.ContainsFunc(c.Titles, func(title string) bool {
+			return strings.TrimSpace(title) == ""
+		})
+	}},
+	{MissingAuthor, "Comics without an author", func(c domain.Comic) bool {
+		return strings.TrimSpace(c.Author) == ""
+	}},
+	{MissingDescription, "Comics without a description", func(c domain.Comic) bool {
+		return strings.TrimSpace(c.Description) == ""
+	}},
+	{MissingCover, "Comics without a visible cover", func(c domain.Comic) bool {
+		return strings.TrimSpace(c.Cover) == "" || !c.CoverVisible
+	}},
+	{ViewedOverCurrent, "Comics with more viewed chapters than published ones", func(c domain.Comic) bool {
+		return c.ViewedChap > c.CurrentChap
+	}},
+	{NegativeChapters, "Comics with negative chapter counts", func(c domain.Comic) bool {
+		return c.ViewedChap < 0 || c.CurrentChap < 0
+	}},
+	{UnnormalizedTitles, "Comics whose titles differ from their normalized form", func(c domain.Comic) bool {
+		return !slices.Equal(identity.NormalizeTitles(c.Titles, c.ComType), c.Titles)
+	}},
+}
+
+// Build runs every check over the comics, the ones in the trash are skipped.
+func Build(comics []domain.Comic) Report {
+	live := []domain.Comic{}
+	for _, comic := range comics {
+		if !comic.Deleted {
+			live = append(live, comic)
+		}
+	}
+	sort.Slice(live, func(i, j int) bool { return live[i].ID < live[j].ID })
+
+	report := Report{TotalComics: len(live), Checks: []Check{}}
+	for _, rule := range rules {
+		ids := []int{}
+		for _, comic := range live {
+			if rule.fails(comic) {
+				ids = append(ids, comic.ID)
+			}
+		}
+		report.Checks = append(report.Checks, newCheck(rule.name, rule.description, ids, len(live)))
+	}
+
+	groups := map[string][]int{}
+	for _, comic := range live {
+		if key := identity.KeyFromTitles(comic.Titles, comic.ComType); key != "" {
+			groups[key] = append(groups[key], comic.ID)
+		}
+	}
+	ids := []int{}
+	for key, group := range groups {
+		if len(group) < 2 {
+			delete(groups, key)
+			continue
+		}
+		ids = append(ids, group...)
+	}
+	sort.Ints(ids)
+	duplicates := newCheck(DuplicateTitleKeys, "Comics sharing the identity key of another comic", ids, len(live))
+	duplicates.Groups = groups
+	report.Checks = append(report.Checks, duplicates)
+	return report
+}
+
+func newCheck(name string, description string, ids []int, total int) Check {
+	return Check{
+		Name:        name,
+		Description: description,
+		Count:       len(ids),
+		Percent:     percent(len(ids), total),
+		ComicIDs:    ids,
+	}
+}
+
+// percent rounds to two decimals like the Python report
+func percent(part int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*100*percentagePrecision) / percentagePrecision
+}
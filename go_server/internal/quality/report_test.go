@@ -0,0 +1,53 @@
+package quality
+
+import (
+	"testing"
+
+	"comics/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	report := Build([]domain.Comic{
+		{ID: 3, Titles: []string{"SOLO LEVELING"}, Author: "Chugong", CurrentChap: 10, ViewedChap: 12},
+		{ID: 1, Titles: []string{"Solo leveling"}, Author: "Chugong", Description: "Hunters", Cover: "cover.webp", CoverVisible: true},
+		{ID: 2, Titles: []string{"Tower of god", ""}, Cover: "hidden.webp", CurrentChap: -1},
+		{ID: 4, Titles: []string{"Solo leveling"}, Deleted: true},
+	})
+
+	assert.Equal(t, 3, report.TotalComics)
+	expected := map[string][]int{
+		MissingTitles:      {2},
+		MissingAuthor:      {2},
+		MissingDescription: {2, 3},
+		MissingCover:       {2, 3},
+		ViewedOverCurrent:  {2, 3},
+		NegativeChapters:   {2},
+		UnnormalizedTitles: {2, 3},
+		DuplicateTitleKeys: {1, 3},
+	}
+	require.Len(t, report.Checks, len(expected))
+	for name, ids := range expected {
+		check, ok := report.Check(name)
+		require.True(t, ok, name)
+		assert.Equal(t, ids, check.ComicIDs, name)
+		assert.Equal(t, len(ids), check.Count, name)
+		assert.NotEmpty(t, check.Description, name)
+	}
+
+	check, _ := report.Check(MissingDescription)
+	assert.Equal(t, 66.67, check.Percent)
+	check, _ = report.Check(DuplicateTitleKeys)
+	assert.Equal(t, map[string][]int{"series:solo leveling": {1, 3}}, check.Groups)
+}
+
+func TestBuildEmpty(t *testing.T) {
+	report := Build(nil)
+	assert.Zero(t, report.TotalComics)
+	for _, check := range report.Checks {
+		assert.Zero(t, check.Percent, check.Name)
+		assert.Empty(t, check.ComicIDs, check.Name)
+	}
+}
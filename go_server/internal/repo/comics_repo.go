@@ -3,6 +3,7 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"math"
@@ -17,8 +18,9 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	pgxmigrate "github.com/golang-migrate/migrate/v4/database/pgx/v5"
 	_ "github.com/golang-migrate/migrate/v4/source/file" // migrate file driver
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/joho/godotenv"
@@ -28,13 +30,19 @@ import (
 
 const (
 	namespace = "comics_repo"
-	// requires migrate file driver on imports
-	migrationSource = "file://internal/repo/sql/migrations"
+	// word similarity needed for a title to match a search, pg_trgm's default
+	defaultSearchThreshold = 0.6
+	// time given to the tracer to flush when a construction fails
+	closeTimeout = 5 * time.Second
 )
 
 var (
 	backoffMinInterval = 500 * time.Millisecond
 	backoffTimeout     = 5 * time.Second
+	// migrationSource holds the NNN_name.up.sql and .down.sql files read by
+	// golang-migrate, relative to the working directory. Requires the migrate
+	// file driver on imports.
+	migrationSource = "file://internal/repo/sql/migrations"
 )
 
 // Implement UserStore methods for UserRepo
@@ -52,6 +60,8 @@ type ComicsRepo struct {
 	cl      *pgxpool.Pool
 	metrics *metrics.Metrics
 	tracer  *tracer.Tracer
+	// searchThreshold is the minimum title similarity of search results
+	searchThreshold float64
 }
 
 // DefaultConfig returns a DBConfig empty usable struct
@@ -66,6 +76,10 @@ func DefaultConfig() *DBConfig {
 		Name:            os.Getenv("PG_NAME"),
 		MaxPoolSize:     100,
 		MaxConnIdleTime: 5 * time.Minute,
+		SearchThreshold: defaultSearchThreshold,
+	}
+	if threshold, err := strconv.ParseFloat(os.Getenv("PG_SEARCH_THRESHOLD"), 64); err == nil {
+		cfg.SearchThreshold = threshold
 	}
 	if cfg.Name == "" {
 		cfg.Name = "comics_db"
@@ -135,18 +149,25 @@ func NewComicsRepo(ctx context.Context, cfg *DBConfig, tpCfg *tracer.Config) (*C
 
 	// Create repository
 	repo := &ComicsRepo{
-		cl:      cl,
-		metrics: metrics,
-		tracer:  tracer,
+		cl:              cl,
+		metrics:         metrics,
+		tracer:          tracer,
+		searchThreshold: cfg.SearchThreshold,
+	}
+	if repo.searchThreshold <= 0 || repo.searchThreshold > 1 {
+		repo.searchThreshold = defaultSearchThreshold
 	}
 
-	// Test connection with retry
+	// Test connection with retry, the pool and the tracer are released when
+	// the database is unusable
 	if err := repo.pingWithRetry(ctx); err != nil {
+		repo.close()
 		return nil, err
 	}
 
 	// Run migrations
 	if err := repo.runMigrations(ctx, cfg.Name); err != nil {
+		repo.close()
 		return nil, err
 	}
 
@@ -164,6 +185,16 @@ func (r *ComicsRepo) Close(ctx context.Context) error {
 	return nil
 }
 
+// close releases the repository when its construction fails
+func (r *ComicsRepo) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+	defer cancel()
+	r.cl.Close()
+	if err := r.tracer.Shutdown(ctx); err != nil {
+		log.Warn().Err(err).Msg("Failed to shutdown the comics repo tracer")
+	}
+}
+
 // Metrics returns a snapshot of the repository's metrics
 func (r *ComicsRepo) Metrics() *metrics.Snapshot {
 	log.Debug().Msgf("Comics repo db metrics: %v", r.cl.Stat())
@@ -183,7 +214,7 @@ func (r *ComicsRepo) pingWithRetry(ctx context.Context) error {
 func (r *ComicsRepo) runMigrations(_ context.Context, dataBaseName string) error {
 	// Create a new pgx driver instance
 	db := stdlib.OpenDBFromPool(r.cl)
-	driver, err := pgx.WithInstance(db, &pgx.Config{})
+	driver, err := pgxmigrate.WithInstance(db, &pgxmigrate.Config{})
 	if err != nil {
 		return fmt.Errorf("error creating migration driver: %w", err)
 	}
@@ -225,8 +256,9 @@ func (r *ComicsRepo) withRetry(ctx context.Context, operation string, fn func()
 	return backoff.Retry(func() error {
 		if err := fn(); err != nil {
 			r.metrics.RecordRetry(operation, false)
-			if errors.Is(err, ErrNotFound) {
-				// Do not retry if the error is ErrNotFound
+			if errors.Is(err, ErrNotFound) || errors.Is(err, ErrVersionConflict) ||
+				errors.Is(err, ErrInvalidPageParams) || errors.Is(err, ErrInvalidArgument) {
+				// Do not retry errors caused by the request itself
 				return backoff.Permanent(err)
 			}
 			log.Warn().Err(err).Caller().Msgf("Operation %s failed, retrying", operation)
@@ -243,8 +275,8 @@ func (r *ComicsRepo) CreateComic(ctx context.Context, comic *pb.Comic) error {
 		return r.withRetry(ctx, "CreateComic", func() error {
 			query := `
 			INSERT INTO comics (
-				titles, author, description, type, status, cover, cover_visible,
-				current_chap, last_update, publishers, genres,
+				titles, author, description, com_type, status, cover, cover_visible,
+				current_chap, last_update, published_in, genres,
 				track, viewed_chap, deleted
 			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 			RETURNING id`
@@ -283,26 +315,34 @@ func (r *ComicsRepo) CreateComic(ctx context.Context, comic *pb.Comic) error {
 
 // UpdateComic updates an existing comic in the database
 func (r *ComicsRepo) UpdateComic(ctx context.Context, comic *pb.Comic) error {
-	return r.withSpan(ctx, "UpdateComic", func(ctx context.Context) error {
+	_, err := r.UpdateComicIfVersion(ctx, comic, 0)
+	return err
+}
+
+// UpdateComicIfVersion updates an existing comic only while its stored version
+// still matches, a zero version skips the check. Returns the new version.
+func (r *ComicsRepo) UpdateComicIfVersion(ctx context.Context, comic *pb.Comic, version uint32) (uint32, error) {
+	var newVersion uint32
+	err := r.withSpan(ctx, "UpdateComic", func(ctx context.Context) error {
 		return r.withRetry(ctx, "UpdateComic", func() error {
 			query := `
 			UPDATE comics SET
 				titles = $1,
 				author = $2,
 				description = $3,
-				type = $4,
+				com_type = $4,
 				status = $5,
 				cover = $6,
 				cover_visible = $7,
 				current_chap = $8,
 				last_update = $9,
-				publishers = $10,
+				published_in = $10,
 				genres = $11,
 				track = $12,
 				viewed_chap = $13,
 				deleted = $14
-			WHERE id = $15
-			RETURNING id`
+			WHERE id = $15 AND ($16 = 0 OR version = $16)
+			RETURNING version`
 
 			publishers := make([]int32, len(comic.PublishedIn))
 			for i, p := range comic.PublishedIn {
@@ -314,7 +354,6 @@ func (r *ComicsRepo) UpdateComic(ctx context.Context, comic *pb.Comic) error {
 				genres[i] = int32(g)
 			}
 
-			var id int32
 			err := r.cl.QueryRow(
 				ctx,
 				query,
@@ -333,14 +372,43 @@ func (r *ComicsRepo) UpdateComic(ctx context.Context, comic *pb.Comic) error {
 				comic.ViewedChap,
 				comic.Deleted,
 				comic.Id,
-			).Scan(&id)
+				int64(version),
+			).Scan(&newVersion)
 
 			if errors.Is(err, sql.ErrNoRows) {
-				return ErrNotFound
+				if version == 0 {
+					return ErrNotFound
+				}
+				// Tell apart a missing comic from a stale version
+				if _, err := r.getComicVersion(ctx, comic.Id); err != nil {
+					return err
+				}
+				return ErrVersionConflict
 			}
 			return err
 		})
 	})
+	return newVersion, err
+}
+
+// GetComicVersion returns the stored version of a comic
+func (r *ComicsRepo) GetComicVersion(ctx context.Context, id uint32) (version uint32, err error) {
+	err = r.withSpan(ctx, "GetComicVersion", func(ctx context.Context) error {
+		return r.withRetry(ctx, "GetComicVersion", func() error {
+			version, err = r.getComicVersion(ctx, id)
+			return err
+		})
+	})
+	return version, err
+}
+
+func (r *ComicsRepo) getComicVersion(ctx context.Context, id uint32) (uint32, error) {
+	var version uint32
+	err := r.cl.QueryRow(ctx, "SELECT version FROM comics WHERE id = $1", id).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	return version, err
 }
 
 // DeleteComic deletes an existing comic from the database
@@ -363,14 +431,63 @@ func (r *ComicsRepo) DeleteComic(ctx context.Context, id uint32) error {
 	})
 }
 
+// MarkComicsReadFilter selects the comics caught up by MarkComicsRead when
+// no ids are given, zero values disable a filter
+type MarkComicsReadFilter struct {
+	TrackedOnly   bool
+	UncheckedOnly bool
+	Publisher     pb.Publisher
+	Genre         pb.Genre
+}
+
+// MarkComicsRead sets viewed_chap to current_chap on the given comics, or on
+// the ones matching the filter when ids is empty. Returns the affected count
+func (r *ComicsRepo) MarkComicsRead(ctx context.Context, ids []uint32, filter MarkComicsReadFilter) (int64, error) {
+	var affected int64
+	err := r.withSpan(ctx, "MarkComicsRead", func(ctx context.Context) error {
+		return r.withRetry(ctx, "MarkComicsRead", func() error {
+			whereClause := "WHERE NOT deleted AND viewed_chap <> current_chap"
+			args := []any{}
+			if len(ids) > 0 {
+				ids32 := make([]int32, len(ids))
+				for i, id := range ids {
+					ids32[i] = int32(id) // #nosec G115
+				}
+				args = append(args, ids32)
+				whereClause += fmt.Sprintf(" AND id = ANY($%d)", len(args))
+			} else {
+				if filter.TrackedOnly || filter.UncheckedOnly {
+					whereClause += " AND track = true"
+				}
+				if filter.Publisher != pb.Publisher_PUBLISHER_UNKNOWN {
+					args = append(args, int32(filter.Publisher))
+					whereClause += fmt.Sprintf(" AND $%d = ANY(published_in)", len(args))
+				}
+				if filter.Genre != pb.Genre_GENRE_UNKNOWN {
+					args = append(args, int32(filter.Genre))
+					whereClause += fmt.Sprintf(" AND $%d = ANY(genres)", len(args))
+				}
+			}
+
+			tag, err := r.cl.Exec(ctx, "UPDATE comics SET viewed_chap = current_chap "+whereClause, args...)
+			if err != nil {
+				return err
+			}
+			affected = tag.RowsAffected()
+			return nil
+		})
+	})
+	return affected, err
+}
+
 // GetComicByID retrieves a comic by its ID
 func (r *ComicsRepo) GetComicByID(ctx context.Context, id uint32) (*pb.Comic, error) {
 	var comic pb.Comic
 	err := r.withSpan(ctx, "GetComicByID", func(ctx context.Context) error {
 		return r.withRetry(ctx, "GetComicByID", func() error {
 			query := `
-			SELECT id, titles, author, description, type, status, cover, cover_visible, current_chap,
-				last_update, publishers, genres, track, viewed_chap, deleted
+			SELECT id, titles, author, description, com_type, status, cover, cover_visible, current_chap,
+				last_update, published_in, genres, track, viewed_chap, deleted
 			FROM comics
 			WHERE id = $1`
 
@@ -425,20 +542,29 @@ func (r *ComicsRepo) GetComicByID(ctx context.Context, id uint32) (*pb.Comic, er
 	return &comic, nil
 }
 
-// GetComics retrieves a paginated list of comics
-func (r *ComicsRepo) GetComics(ctx context.Context, page, pageSize int, trackedOnly, uncheckedOnly bool) ([]*pb.Comic, int, error) {
+// GetComics retrieves a paginated list of comics. A page token continues
+// after the last comic of the previous page and takes precedence over page.
+// Comics are ordered by last update, newest first, unless another sort order
+// is requested, relevance is only valid for searches
+func (r *ComicsRepo) GetComics(
+	ctx context.Context,
+	page, pageSize int,
+	pageToken string,
+	sortOrder pb.ComicSortOrder,
+	trackedOnly, uncheckedOnly bool,
+) ([]*pb.Comic, int, string, error) {
 	var comics []*pb.Comic
 	var total int
+	var nextPageToken string
 
-	err := r.withSpan(ctx, "GetComics", func(ctx context.Context) error {
+	order, err := listOrder(sortOrder)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	err = r.withSpan(ctx, "GetComics", func(ctx context.Context) error {
 		return r.withRetry(ctx, "GetComics", func() error {
-			whereClause := "WHERE NOT deleted"
-			if trackedOnly {
-				whereClause += " AND track = true"
-			}
-			if uncheckedOnly {
-				whereClause += " AND track = true AND viewed_chap < current_chap"
-			}
+			whereClause := "WHERE NOT deleted" + trackingFilter(trackedOnly, uncheckedOnly)
 
 			// Get total count
 			countQuery := fmt.Sprintf("SELECT COUNT(*) FROM comics %s", whereClause)
@@ -447,129 +573,131 @@ func (r *ComicsRepo) GetComics(ctx context.Context, page, pageSize int, trackedO
 			}
 
 			// Get paginated results
+			pageClause, args, err := keysetPage(whereClause, nil, order, page, pageSize, pageToken)
+			if err != nil {
+				return err
+			}
 			query := fmt.Sprintf(`
-				SELECT id, titles, author, description, type, status, cover, cover_visible, current_chap,
-					last_update, publishers, genres, track, viewed_chap, deleted
+				SELECT %s, %s
 				FROM comics
-				%s
-				ORDER BY last_update DESC
-				LIMIT $1 OFFSET $2`, whereClause)
+				%s`, comicColumns, order.expression, pageClause)
 
-			offset := (page - 1) * pageSize
-			rows, err := r.cl.Query(ctx, query, pageSize, offset)
+			rows, err := r.cl.Query(ctx, query, args...)
 			if err != nil {
 				return err
 			}
-			defer rows.Close()
-
-			for rows.Next() {
-				var comic pb.Comic
-				var coverVisible bool
-				var lastUpdate time.Time
-				var publishers, genres []int32
-
-				err := rows.Scan(
-					&comic.Id,
-					&comic.Titles,
-					&comic.Author,
-					&comic.Description,
-					&comic.ComType,
-					&comic.Status,
-					&comic.Cover,
-					&coverVisible,
-					&comic.CurrentChap,
-					&lastUpdate,
-					&publishers,
-					&genres,
-					&comic.Track,
-					&comic.ViewedChap,
-					&comic.Deleted,
-				)
-				if err != nil {
-					return err
-				}
-
-				comic.LastUpdate = timestamppb.New(lastUpdate)
-				comic.CoverVisible = &coverVisible
-				comic.PublishedIn = make([]pb.Publisher, len(publishers))
-				comic.Genres = make([]pb.Genre, len(genres))
-
-				for i, p := range publishers {
-					comic.PublishedIn[i] = pb.Publisher(p)
-				}
-				for i, g := range genres {
-					comic.Genres[i] = pb.Genre(g)
-				}
-
-				comics = append(comics, &comic)
-			}
-
-			return rows.Err()
+			comics, nextPageToken, err = scanComicPage(rows, order, pageSize)
+			return err
 		})
 	})
 
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, "", err
 	}
-	return comics, total, nil
+	return comics, total, nextPageToken, nil
 }
 
-// SearchComics searches for comics by title, author, or genre
-func (r *ComicsRepo) SearchComics(ctx context.Context, query string, page, pageSize int) (comics []*pb.Comic, total int, err error) {
+// SearchComics finds comics whose titles are similar to the query, which
+// tolerates typos, or whose author or description contain it. Results are
+// ordered by title similarity unless another sort order is requested
+func (r *ComicsRepo) SearchComics(
+	ctx context.Context,
+	query string,
+	page, pageSize int,
+	pageToken string,
+	sortOrder pb.ComicSortOrder,
+	trackedOnly, uncheckedOnly bool,
+) (comics []*pb.Comic, total int, nextPageToken string, err error) {
 	ctx, span := r.tracer.StartSpan(ctx, "SearchComics")
 	defer span.End()
 
 	// Validate input
 	if query == "" {
 		err = fmt.Errorf("search query cannot be empty")
-		return comics, total, err
+		return comics, total, nextPageToken, err
+	}
+	order, err := searchOrder(sortOrder)
+	if err != nil {
+		return nil, 0, "", err
 	}
 
-	// Prepare the base query with search conditions
-	baseQuery := `
-		SELECT id, titles, author, description, type, status, cover, cover_visible, current_chap,
-			last_update, publishers, genres, track, viewed_chap, deleted
-		FROM comics
-		WHERE 
-			(	LOWER(titles) LIKE LOWER($1) OR 
-				LOWER(author) LIKE LOWER($1) OR 
-				LOWER(description) LIKE LOWER($1)	)
-	`
-	countQuery := `
-		SELECT COUNT(*)
-		FROM comics
-		WHERE 
-			(	LOWER(titles) LIKE LOWER($1) OR 
-				LOWER(author) LIKE LOWER($1) OR 
-				LOWER(description) LIKE LOWER($1)	)
-	`
-
-	// Prepare search pattern
-	searchPattern := fmt.Sprintf("%%%s%%", query)
+	// The similarity threshold used by <% only applies to this transaction
+	tx, err := r.cl.Begin(ctx)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	defer tx.Rollback(ctx) // nolint:errcheck
+	threshold := strconv.FormatFloat(r.searchThreshold, 'f', -1, 64)
+	if _, err := tx.Exec(ctx, "SELECT set_config('pg_trgm.word_similarity_threshold', $1, true)", threshold); err != nil {
+		return nil, 0, "", fmt.Errorf("error setting search threshold: %w", err)
+	}
+
+	// Titles use the trigram index, author and description a plain substring match
+	whereClause := `
+		WHERE NOT deleted AND
+			(	$1 <% comic_titles_text(titles) OR
+				author ILIKE $2 OR
+				description ILIKE $2	)` + trackingFilter(trackedOnly, uncheckedOnly)
+	searchArgs := []any{strings.ToLower(query), "%" + query + "%"}
 
 	// Count total matching records
-	err = r.cl.QueryRow(ctx, countQuery, searchPattern).Scan(&total)
+	err = tx.QueryRow(ctx, "SELECT COUNT(*) FROM comics"+whereClause, searchArgs...).Scan(&total)
 	if err != nil {
-		return nil, 0, fmt.Errorf("error counting search results: %w", err)
+		return nil, 0, "", fmt.Errorf("error counting search results: %w", err)
 	}
 
 	// Prepare pagination
-	offset := (page - 1) * pageSize
-	baseQuery += ` LIMIT $2 OFFSET $3`
+	pageClause, args, err := keysetPage(whereClause, searchArgs, order, page, pageSize, pageToken)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	baseQuery := `
+		SELECT ` + comicColumns + `, ` + order.expression + `
+		FROM comics` + pageClause
 
 	// Execute search query
-	rows, err := r.cl.Query(ctx, baseQuery, searchPattern, pageSize, offset)
+	rows, err := tx.Query(ctx, baseQuery, args...)
 	if err != nil {
 		err = fmt.Errorf("error searching comics: %w", err)
-		return comics, total, err
+		return comics, total, nextPageToken, err
+	}
+	comics, nextPageToken, err = scanComicPage(rows, order, pageSize)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("error in search results: %w", err)
 	}
+	return comics, total, nextPageToken, nil
+}
+
+// comicColumns are the columns read into a pb.Comic by scanComicPage
+const comicColumns = `id, titles, author, description, com_type, status, cover, cover_visible, current_chap,
+	last_update, published_in, genres, track, viewed_chap, deleted`
+
+// trackingFilter restricts a listing to tracked comics, or to tracked ones
+// with unread chapters
+func trackingFilter(trackedOnly, uncheckedOnly bool) string {
+	switch {
+	case uncheckedOnly:
+		return " AND track = true AND viewed_chap < current_chap"
+	case trackedOnly:
+		return " AND track = true"
+	default:
+		return ""
+	}
+}
+
+// scanComicPage reads the comics of a page, each row ends with its sort key.
+// Returns the comics up to pageSize and the token of the next page
+func scanComicPage(rows pgx.Rows, order pageOrder, pageSize int) ([]*pb.Comic, string, error) {
 	defer rows.Close()
 
+	var comics []*pb.Comic
+	var keys []any
 	for rows.Next() {
 		var comic pb.Comic
 		var coverVisible bool
 		var lastUpdate time.Time
 		var publishers, genres []int32
+		var key any
 
 		err := rows.Scan(
 			&comic.Id,
@@ -587,9 +715,10 @@ func (r *ComicsRepo) SearchComics(ctx context.Context, query string, page, pageS
 			&comic.Track,
 			&comic.ViewedChap,
 			&comic.Deleted,
+			&key,
 		)
 		if err != nil {
-			return nil, 0, fmt.Errorf("error scanning comic row: %w", err)
+			return nil, "", fmt.Errorf("error scanning comic row: %w", err)
 		}
 
 		comic.LastUpdate = timestamppb.New(lastUpdate)
@@ -605,21 +734,166 @@ func (r *ComicsRepo) SearchComics(ctx context.Context, query string, page, pageS
 		}
 
 		comics = append(comics, &comic)
+		keys = append(keys, key)
 	}
-
 	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error in search results: %w", err)
+		return nil, "", err
+	}
+
+	comics, nextPageToken := trimPage(comics, keys, order, pageSize)
+	return comics, nextPageToken, nil
+}
+
+// titleSimilarity scores how well the search query, always $1, matches the
+// titles of a comic, from 0 to 1
+const titleSimilarity = "word_similarity($1, comic_titles_text(titles))"
+
+// pageOrder is the ordering of a comic listing, ties are broken by id
+type pageOrder struct {
+	sort       pb.ComicSortOrder
+	expression string
+	descending bool
+}
+
+var pageOrders = map[pb.ComicSortOrder]pageOrder{
+	pb.ComicSortOrder_TITLE_ASC:    {pb.ComicSortOrder_TITLE_ASC, "comic_titles_text(titles)", false},
+	pb.ComicSortOrder_TITLE_DESC:   {pb.ComicSortOrder_TITLE_DESC, "comic_titles_text(titles)", true},
+	pb.ComicSortOrder_UPDATED_ASC:  {pb.ComicSortOrder_UPDATED_ASC, "last_update", false},
+	pb.ComicSortOrder_UPDATED_DESC: {pb.ComicSortOrder_UPDATED_DESC, "last_update", true},
+	pb.ComicSortOrder_RELEVANCE:    {pb.ComicSortOrder_RELEVANCE, titleSimilarity, true},
+}
+
+// listOrder returns the ordering of GetComics, newest first by default
+func listOrder(sortOrder pb.ComicSortOrder) (pageOrder, error) {
+	if sortOrder == pb.ComicSortOrder_UNSPECIFIED {
+		sortOrder = pb.ComicSortOrder_UPDATED_DESC
+	}
+	order, ok := pageOrders[sortOrder]
+	if !ok || sortOrder == pb.ComicSortOrder_RELEVANCE {
+		return pageOrder{}, fmt.Errorf("%w: sort order %s is not valid for listings", ErrInvalidArgument, sortOrder)
+	}
+	return order, nil
+}
+
+// searchOrder returns the ordering of search results, relevance by default
+func searchOrder(sortOrder pb.ComicSortOrder) (pageOrder, error) {
+	if sortOrder == pb.ComicSortOrder_UNSPECIFIED {
+		sortOrder = pb.ComicSortOrder_RELEVANCE
+	}
+	order, ok := pageOrders[sortOrder]
+	if !ok {
+		return pageOrder{}, fmt.Errorf("%w: unknown sort order %d", ErrInvalidArgument, sortOrder)
+	}
+	return order, nil
+}
+
+// keysetPage appends the ordering and page bounds to a where clause. With a
+// page token the page starts after the comic it points at, otherwise page is
+// turned into an offset. One row more than pageSize is fetched, see trimPage
+func keysetPage(
+	whereClause string,
+	args []any,
+	order pageOrder,
+	page, pageSize int,
+	pageToken string,
+) (string, []any, error) {
+	args = append([]any{}, args...)
+	if pageToken != "" {
+		key, id, err := decodePageToken(pageToken, order)
+		if err != nil {
+			return "", nil, err
+		}
+		operator := ">"
+		if order.descending {
+			operator = "<"
+		}
+		args = append(args, key, id)
+		whereClause += fmt.Sprintf(
+			" AND (%[1]s %[2]s $%[3]d OR (%[1]s = $%[3]d AND id > $%[4]d))",
+			order.expression, operator, len(args)-1, len(args),
+		)
+	}
+
+	direction := "ASC"
+	if order.descending {
+		direction = "DESC"
+	}
+	args = append(args, pageSize+1)
+	clause := fmt.Sprintf("%s ORDER BY %s %s, id LIMIT $%d", whereClause, order.expression, direction, len(args))
+	if pageToken == "" {
+		args = append(args, (page-1)*pageSize)
+		clause += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+	return clause, args, nil
+}
+
+// trimPage drops the extra row fetched by keysetPage and returns the token of
+// the next page when there is one. keys holds the sort key of every comic
+func trimPage(comics []*pb.Comic, keys []any, order pageOrder, pageSize int) ([]*pb.Comic, string) {
+	if len(comics) <= pageSize {
+		return comics, ""
 	}
+	comics = comics[:pageSize]
+	last := comics[pageSize-1]
+	return comics, encodePageToken(order, keys[pageSize-1], last.Id)
+}
+
+// encodePageToken stores the sort order, the id and the sort key of the last
+// comic of a page as "order:id:key"
+func encodePageToken(order pageOrder, key any, id uint32) string {
+	var value string
+	switch key := key.(type) {
+	case time.Time:
+		value = strconv.FormatInt(key.UnixMicro(), 10)
+	case float32:
+		value = strconv.FormatFloat(float64(key), 'g', -1, 32)
+	case string:
+		value = key
+	}
+	raw := fmt.Sprintf("%d:%d:%s", order.sort, id, value)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
 
-	return comics, total, nil
+// decodePageToken parses a page token, it must have been issued for the same
+// sort order
+func decodePageToken(token string, order pageOrder) (any, uint32, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, 0, ErrInvalidPageParams
+	}
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 || parts[0] != strconv.Itoa(int(order.sort)) {
+		return nil, 0, ErrInvalidPageParams
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return nil, 0, ErrInvalidPageParams
+	}
+
+	switch order.sort {
+	case pb.ComicSortOrder_TITLE_ASC, pb.ComicSortOrder_TITLE_DESC:
+		return parts[2], uint32(id), nil
+	case pb.ComicSortOrder_RELEVANCE:
+		rank, err := strconv.ParseFloat(parts[2], 32)
+		if err != nil {
+			return nil, 0, ErrInvalidPageParams
+		}
+		return float32(rank), uint32(id), nil
+	default:
+		micros, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			return nil, 0, ErrInvalidPageParams
+		}
+		return time.UnixMicro(micros), uint32(id), nil
+	}
 }
 
 // GetComicsByTitle retrieves comics by title
 func (r *ComicsRepo) GetComicsByTitle(ctx context.Context, title string) (comics []*pb.Comic, err error) {
 	err = r.withSpan(ctx, "GetComicsByTitle", func(ctx context.Context) error {
 		query := `
-			SELECT id, titles, author, description, type, status, cover, cover_visible, current_chap,
-				last_update, publishers, genres, track, viewed_chap, deleted
+			SELECT id, titles, author, description, com_type, status, cover, cover_visible, current_chap,
+				last_update, published_in, genres, track, viewed_chap, deleted
 			FROM comics
 			WHERE titles ILIKE $1 AND deleted = false`
 
@@ -0,0 +1,259 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"comics/internal/metrics"
+	"comics/internal/tracer"
+	"comics/pkg/pb"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+var (
+	postgresOnce      sync.Once
+	postgresContainer *postgres.PostgresContainer
+	postgresRepo      *ComicsRepo
+	postgresErr       error
+)
+
+// TestMain closes the repo and terminates the Postgres container once the
+// tests are done, when one of them started it
+func TestMain(m *testing.M) {
+	code := m.Run()
+
+	if postgresContainer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		if postgresRepo != nil {
+			if err := postgresRepo.Close(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to close the comics repo")
+			}
+		}
+		if err := postgresContainer.Terminate(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to terminate Postgres container")
+		}
+		cancel()
+	}
+	os.Exit(code)
+}
+
+// newPostgresRepo returns a ComicsRepo on a Postgres container shared by the
+// tests of the package, migrated by runMigrations like NewComicsRepo does and
+// with the comics truncated. Tests are skipped when docker is not available
+func newPostgresRepo(t *testing.T) *ComicsRepo {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	postgresOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		const database = "comics_db_test"
+		postgresContainer, postgresErr = postgres.Run(ctx, "postgres:16-alpine",
+			postgres.WithDatabase(database),
+			postgres.WithUsername("comics"),
+			postgres.WithPassword("comics"),
+			postgres.BasicWaitStrategies(),
+		)
+		if postgresErr != nil {
+			return
+		}
+		connStr, err := postgresContainer.ConnectionString(ctx, "sslmode=disable")
+		if err != nil {
+			postgresErr = err
+			return
+		}
+		cl, err := pgxpool.New(ctx, connStr)
+		if err != nil {
+			postgresErr = err
+			return
+		}
+		tr, err := tracer.NewTracer(ctx, &tracer.Config{ServiceName: "comics-service-test"}, namespace)
+		if err != nil {
+			cl.Close()
+			postgresErr = err
+			return
+		}
+		backoffTimeout = time.Second
+		repo := &ComicsRepo{
+			cl:              cl,
+			metrics:         metrics.NewMetrics("comics-service-test", namespace),
+			tracer:          tr,
+			searchThreshold: defaultSearchThreshold,
+		}
+		// The source is relative to the working directory, the package one
+		// for the tests
+		migrationSource = "file://sql/migrations"
+		if postgresErr = repo.runMigrations(ctx, database); postgresErr != nil {
+			repo.close()
+			return
+		}
+		postgresRepo = repo
+	})
+	require.NoError(t, postgresErr)
+
+	_, err := postgresRepo.cl.Exec(context.Background(), "TRUNCATE comics RESTART IDENTITY")
+	require.NoError(t, err)
+	return postgresRepo
+}
+
+// createComics stores the comics in order, each one updated after the last
+func createComics(t *testing.T, r *ComicsRepo, comics ...*pb.Comic) {
+	t.Helper()
+	for _, comic := range comics {
+		require.NoError(t, r.CreateComic(context.Background(), comic))
+	}
+}
+
+func comicIDs(comics []*pb.Comic) []uint32 {
+	ids := make([]uint32, len(comics))
+	for i, comic := range comics {
+		ids[i] = comic.Id
+	}
+	return ids
+}
+
+func TestPostgresGetComicsSortOrders(t *testing.T) {
+	r := newPostgresRepo(t)
+	ctx := context.Background()
+	createComics(t, r,
+		&pb.Comic{Titles: []string{"Omniscient Reader"}, Author: "Sing Shong"},
+		&pb.Comic{Titles: []string{"Blue Lock"}, Author: "Muneyuki Kaneshiro"},
+		&pb.Comic{Titles: []string{"Solo Leveling"}, Author: "Chugong"},
+	)
+
+	cases := []struct {
+		order pb.ComicSortOrder
+		ids   []uint32
+	}{
+		{pb.ComicSortOrder_UNSPECIFIED, []uint32{3, 2, 1}},
+		{pb.ComicSortOrder_UPDATED_DESC, []uint32{3, 2, 1}},
+		{pb.ComicSortOrder_UPDATED_ASC, []uint32{1, 2, 3}},
+		{pb.ComicSortOrder_TITLE_ASC, []uint32{2, 1, 3}},
+		{pb.ComicSortOrder_TITLE_DESC, []uint32{3, 1, 2}},
+	}
+	for _, tc := range cases {
+		comics, total, nextPageToken, err := r.GetComics(ctx, 1, 10, "", tc.order, false, false)
+		require.NoError(t, err, tc.order)
+		assert.Equal(t, 3, total, tc.order)
+		assert.Equal(t, tc.ids, comicIDs(comics), tc.order)
+		assert.Empty(t, nextPageToken, tc.order)
+	}
+
+	_, _, _, err := r.GetComics(ctx, 1, 10, "", pb.ComicSortOrder_RELEVANCE, false, false)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
+
+func TestPostgresGetComicsPageTokens(t *testing.T) {
+	r := newPostgresRepo(t)
+	ctx := context.Background()
+	createComics(t, r,
+		&pb.Comic{Titles: []string{"Omniscient Reader"}, Author: "Sing Shong"},
+		&pb.Comic{Titles: []string{"Blue Lock"}, Author: "Muneyuki Kaneshiro"},
+		&pb.Comic{Titles: []string{"Solo Leveling"}, Author: "Chugong"},
+	)
+
+	for _, order := range []pb.ComicSortOrder{pb.ComicSortOrder_TITLE_ASC, pb.ComicSortOrder_UPDATED_ASC} {
+		var ids []uint32
+		pageToken := ""
+		for page := 1; ; page++ {
+			comics, _, nextPageToken, err := r.GetComics(ctx, page, 2, pageToken, order, false, false)
+			require.NoError(t, err, order)
+			ids = append(ids, comicIDs(comics)...)
+			if nextPageToken == "" {
+				break
+			}
+			pageToken = nextPageToken
+		}
+		expected, _, _, err := r.GetComics(ctx, 1, 10, "", order, false, false)
+		require.NoError(t, err)
+		assert.Equal(t, comicIDs(expected), ids, order)
+	}
+}
+
+func TestPostgresTrackingFilters(t *testing.T) {
+	r := newPostgresRepo(t)
+	ctx := context.Background()
+	createComics(t, r,
+		&pb.Comic{Titles: []string{"Solo Leveling"}, Author: "Chugong", Track: true, CurrentChap: 10, ViewedChap: 10},
+		&pb.Comic{Titles: []string{"Solo Max-Level Newbie"}, Author: "Maslow", Track: true, CurrentChap: 10, ViewedChap: 2},
+		&pb.Comic{Titles: []string{"Solo Bug Player"}, Author: "Bug", CurrentChap: 10},
+	)
+
+	cases := []struct {
+		trackedOnly, uncheckedOnly bool
+		ids                        []uint32
+	}{
+		{false, false, []uint32{3, 2, 1}},
+		{true, false, []uint32{2, 1}},
+		{false, true, []uint32{2}},
+		{true, true, []uint32{2}},
+	}
+	for _, tc := range cases {
+		comics, total, _, err := r.GetComics(ctx, 1, 10, "", pb.ComicSortOrder_UNSPECIFIED, tc.trackedOnly, tc.uncheckedOnly)
+		require.NoError(t, err)
+		assert.Equal(t, tc.ids, comicIDs(comics), "list tracked %v unchecked %v", tc.trackedOnly, tc.uncheckedOnly)
+		assert.Equal(t, len(tc.ids), total)
+
+		comics, total, _, err = r.SearchComics(
+			ctx, "solo", 1, 10, "", pb.ComicSortOrder_UPDATED_DESC, tc.trackedOnly, tc.uncheckedOnly,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, tc.ids, comicIDs(comics), "search tracked %v unchecked %v", tc.trackedOnly, tc.uncheckedOnly)
+		assert.Equal(t, len(tc.ids), total)
+	}
+}
+
+func TestPostgresSearchComics(t *testing.T) {
+	r := newPostgresRepo(t)
+	ctx := context.Background()
+	createComics(t, r,
+		&pb.Comic{Titles: []string{"Solo Leveling", "Na Honjaman Level Up"}, Author: "Chugong"},
+		&pb.Comic{Titles: []string{"Leveling With The Gods"}, Author: "Ohyeon"},
+		&pb.Comic{Titles: []string{"Tower of God"}, Author: "SIU", Description: "Climb for solo glory"},
+		&pb.Comic{Titles: []string{"Blue Lock"}, Author: "Muneyuki Kaneshiro"},
+	)
+
+	// Typos in the title still match, the closest title ranks first
+	comics, total, _, err := r.SearchComics(ctx, "solo levelin", 1, 10, "", pb.ComicSortOrder_RELEVANCE, false, false)
+	require.NoError(t, err)
+	require.NotEmpty(t, comics)
+	assert.Equal(t, uint32(1), comics[0].Id)
+	assert.Equal(t, len(comics), total)
+	assert.NotContains(t, comicIDs(comics), uint32(4))
+
+	// Author and description are matched as substrings
+	comics, _, _, err = r.SearchComics(ctx, "solo", 1, 10, "", pb.ComicSortOrder_UPDATED_ASC, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{1, 3}, comicIDs(comics))
+	comics, _, _, err = r.SearchComics(ctx, "kaneshiro", 1, 10, "", pb.ComicSortOrder_UNSPECIFIED, false, false)
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{4}, comicIDs(comics))
+
+	// Every sort order can be paged through with tokens
+	for order := range pageOrders {
+		expected, _, _, err := r.SearchComics(ctx, "level", 1, 10, "", order, false, false)
+		require.NoError(t, err, order)
+		var ids []uint32
+		pageToken := ""
+		for page := 1; ; page++ {
+			comics, _, nextPageToken, err := r.SearchComics(ctx, "level", page, 1, pageToken, order, false, false)
+			require.NoError(t, err, order)
+			ids = append(ids, comicIDs(comics)...)
+			if nextPageToken == "" {
+				break
+			}
+			pageToken = nextPageToken
+		}
+		assert.Equal(t, comicIDs(expected), ids, order)
+	}
+}
@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"comics/domain"
+	"comics/internal/quality"
+)
+
+// DataQuality builds the data quality report of the comics outside the trash
+func (r *ComicsRepo) DataQuality(ctx context.Context) (report quality.Report, err error) {
+	err = r.withSpan(ctx, "DataQuality", func(ctx context.Context) error {
+		return r.withRetry(ctx, "DataQuality", func() error {
+			rows, err := r.cl.Query(ctx, `
+				SELECT id, titles, COALESCE(author, ''), COALESCE(description, ''),
+					COALESCE(cover, ''), cover_visible, com_type, status, rating,
+					current_chap, viewed_chap, track
+				FROM comics
+				WHERE NOT deleted`)
+			if err != nil {
+				return fmt.Errorf("error reading comics: %w", err)
+			}
+			defer rows.Close()
+
+			comics := []domain.Comic{}
+			for rows.Next() {
+				var comic domain.Comic
+				err := rows.Scan(
+					&comic.ID,
+					&comic.Titles,
+					&comic.Author,
+					&comic.Description,
+					&comic.Cover,
+					&comic.CoverVisible,
+					&comic.ComType,
+					&comic.Status,
+					&comic.Rating,
+					&comic.CurrentChap,
+					&comic.ViewedChap,
+					&comic.Track,
+				)
+				if err != nil {
+					return fmt.Errorf("error scanning comic row: %w", err)
+				}
+				comics = append(comics, comic)
+			}
+			if err := rows.Err(); err != nil {
+				return fmt.Errorf("error reading comics: %w", err)
+			}
+			report = quality.Build(comics)
+			return nil
+		})
+	})
+	return report, err
+}
@@ -0,0 +1,90 @@
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"comics/pkg/pb"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	orderUpdatedDesc = pageOrders[pb.ComicSortOrder_UPDATED_DESC]
+	orderRelevance   = pageOrders[pb.ComicSortOrder_RELEVANCE]
+	orderTitleAsc    = pageOrders[pb.ComicSortOrder_TITLE_ASC]
+)
+
+func TestPageTokenRoundTrip(t *testing.T) {
+	t.Parallel()
+	lastUpdate := time.UnixMicro(1700000000123456)
+	comics := []*pb.Comic{
+		{Id: 7, LastUpdate: timestamppb.New(lastUpdate)},
+		{Id: 3, LastUpdate: timestamppb.New(lastUpdate)},
+	}
+
+	page, token := trimPage(comics, []any{lastUpdate, lastUpdate}, orderUpdatedDesc, 1)
+	assert.Len(t, page, 1)
+	key, id, err := decodePageToken(token, orderUpdatedDesc)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(7), id)
+	assert.True(t, lastUpdate.Equal(key.(time.Time)))
+
+	_, token = trimPage(comics, []any{float32(0.8181818), float32(0.5)}, orderRelevance, 1)
+	key, id, err = decodePageToken(token, orderRelevance)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(7), id)
+	assert.Equal(t, float32(0.8181818), key)
+
+	_, token = trimPage(comics, []any{"solo: leveling", "tower"}, orderTitleAsc, 1)
+	key, _, err = decodePageToken(token, orderTitleAsc)
+	assert.NoError(t, err)
+	assert.Equal(t, "solo: leveling", key)
+
+	_, token = trimPage(comics, []any{lastUpdate, lastUpdate}, orderUpdatedDesc, 2)
+	assert.Empty(t, token)
+}
+
+func TestPageTokenRejectsOtherOrders(t *testing.T) {
+	t.Parallel()
+	comics := []*pb.Comic{{Id: 1}, {Id: 2}}
+	_, token := trimPage(comics, []any{time.Now(), time.Now()}, orderUpdatedDesc, 1)
+
+	_, _, err := decodePageToken(token, orderRelevance)
+	assert.ErrorIs(t, err, ErrInvalidPageParams)
+	_, _, err = decodePageToken(token, pageOrders[pb.ComicSortOrder_UPDATED_ASC])
+	assert.ErrorIs(t, err, ErrInvalidPageParams)
+	_, _, err = decodePageToken("not a token", orderUpdatedDesc)
+	assert.ErrorIs(t, err, ErrInvalidPageParams)
+}
+
+func TestKeysetPage(t *testing.T) {
+	t.Parallel()
+	clause, args, err := keysetPage(" WHERE NOT deleted", []any{"solo"}, orderRelevance, 3, 20, "")
+	assert.NoError(t, err)
+	assert.Equal(t, " WHERE NOT deleted ORDER BY "+titleSimilarity+" DESC, id LIMIT $2 OFFSET $3", clause)
+	assert.Equal(t, []any{"solo", 21, 40}, args)
+
+	_, token := trimPage([]*pb.Comic{{Id: 4}, {Id: 5}}, []any{float32(0.75), float32(0.5)}, orderRelevance, 1)
+	clause, args, err = keysetPage(" WHERE NOT deleted", []any{"solo"}, orderRelevance, 0, 1, token)
+	assert.NoError(t, err)
+	assert.Equal(t, " WHERE NOT deleted AND ("+titleSimilarity+" < $2 OR ("+titleSimilarity+
+		" = $2 AND id > $3)) ORDER BY "+titleSimilarity+" DESC, id LIMIT $4", clause)
+	assert.Equal(t, []any{"solo", float32(0.75), uint32(4), 2}, args)
+}
+
+func TestSortOrders(t *testing.T) {
+	t.Parallel()
+	order, err := listOrder(pb.ComicSortOrder_UNSPECIFIED)
+	assert.NoError(t, err)
+	assert.Equal(t, orderUpdatedDesc, order)
+	_, err = listOrder(pb.ComicSortOrder_RELEVANCE)
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+
+	order, err = searchOrder(pb.ComicSortOrder_UNSPECIFIED)
+	assert.NoError(t, err)
+	assert.Equal(t, orderRelevance, order)
+	_, err = searchOrder(pb.ComicSortOrder(42))
+	assert.ErrorIs(t, err, ErrInvalidArgument)
+}
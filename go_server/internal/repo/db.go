@@ -11,6 +11,7 @@ var (
 	ErrNotFound          = errors.New("record not found")
 	ErrInvalidPageParams = errors.New("invalid page parameters")
 	ErrInvalidArgument   = errors.New("invalid argument")
+	ErrVersionConflict   = errors.New("record version changed")
 )
 
 // DBConfig holds the DB configuration
@@ -32,6 +33,9 @@ type DBConfig struct {
 	MaxConnLifeTime time.Duration `mapstructure:"DB_MAX_CONN_LIFE_TIME" default:"60m"`
 	ConnectTimeout  time.Duration `mapstructure:"DB_CONN_TIMEOUT" default:"30s"`
 	BackoffTimeout  time.Duration `mapstructure:"DB_BACKOFF_TIMEOUT" default:"15s"`
+	// Search configuration, minimum title similarity between 0 and 1. Only
+	// Postgres searches by similarity, hence the PG_ prefix of DefaultConfig
+	SearchThreshold float64 `mapstructure:"PG_SEARCH_THRESHOLD" default:"0.6"`
 }
 
 // Closable defines a common interface for closing database connections
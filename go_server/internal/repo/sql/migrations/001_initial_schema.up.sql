@@ -1,4 +1,3 @@
--- +migrate Up
 CREATE TABLE IF NOT EXISTS schema_migrations (
     version bigint NOT NULL,
     dirty boolean NOT NULL,
@@ -28,7 +27,3 @@ CREATE TABLE IF NOT EXISTS comics (
 CREATE INDEX IF NOT EXISTS idx_comics_track ON comics(track) WHERE NOT deleted;
 CREATE INDEX IF NOT EXISTS idx_comics_titles ON comics USING gin(titles);
 CREATE INDEX IF NOT EXISTS idx_comics_last_update ON comics(last_update DESC);
-
--- +migrate Down
-DROP TABLE IF EXISTS comics;
-DROP TABLE IF EXISTS schema_migrations;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_comics_last_update ON comics;
+DROP FUNCTION IF EXISTS update_last_update();
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS bump_comics_version ON comics;
+DROP FUNCTION IF EXISTS bump_comic_version();
+ALTER TABLE comics
+DROP COLUMN IF EXISTS version;
@@ -0,0 +1,15 @@
+ALTER TABLE comics
+ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_comic_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER bump_comics_version
+    BEFORE UPDATE ON comics
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_comic_version();
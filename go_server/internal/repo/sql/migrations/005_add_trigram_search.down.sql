@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_comics_titles_trgm;
+DROP FUNCTION IF EXISTS comic_titles_text(VARCHAR(255)[]);
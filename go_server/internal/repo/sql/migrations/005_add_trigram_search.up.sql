@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- array_to_string is only STABLE, index expressions need an IMMUTABLE function
+CREATE OR REPLACE FUNCTION comic_titles_text(titles VARCHAR(255)[])
+RETURNS TEXT AS $$
+    SELECT lower(array_to_string(titles, ' '));
+$$ LANGUAGE sql IMMUTABLE PARALLEL SAFE;
+
+CREATE INDEX IF NOT EXISTS idx_comics_titles_trgm
+    ON comics USING gin (comic_titles_text(titles) gin_trgm_ops);
@@ -0,0 +1,45 @@
+package scrape
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"comics/pkg/pb"
+
+	"golang.org/x/net/html"
+)
+
+// asura reads the updates of Asura Scans, like src/scrape/asura.py
+type asura struct{}
+
+const asuraComicClass = "grid grid-rows-1 grid-cols-12 m-2"
+
+func (asura) Publisher() pb.Publisher {
+	return pb.Publisher_ASURA
+}
+
+func (asura) Parse(doc *html.Node) ([]Comic, error) {
+	return parseItems(doc, classIs("div", asuraComicClass), func(item *html.Node) (Comic, bool, error) {
+		cover := attr(find(item, "div", "div", "a", "img"), "src")
+		divs := findAll(item, tagIs("div"))
+		if len(divs) < 3 {
+			return Comic{}, false, errors.New("asura: comic without details")
+		}
+		info := divs[2]
+		title := strings.TrimSpace(strings.ReplaceAll(text(find(info, "span", "a")), "...", ""))
+		if title == "" || cover == "" {
+			return Comic{}, false, errors.New("asura: comic without title or cover")
+		}
+		// The recommended comics only have the span of the title
+		spans := findAll(info, tagIs("span"))
+		if len(spans) < 2 {
+			return Comic{}, false, nil
+		}
+		chapter := text(find(spans[1], "div", "div", "a", "span", "div", "p"))
+		if chapter == "" {
+			return Comic{}, false, fmt.Errorf("asura: %s without chapter", title)
+		}
+		return Comic{Title: title, Chapter: chapter, Cover: cover, ComType: "manhwa", Status: "ongoing"}, true, nil
+	})
+}
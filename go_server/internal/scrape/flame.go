@@ -0,0 +1,37 @@
+package scrape
+
+import (
+	"errors"
+	"fmt"
+
+	"comics/pkg/pb"
+
+	"golang.org/x/net/html"
+)
+
+// flame reads the updates of Flame Scans, like src/scrape/flame.py
+type flame struct{}
+
+func (flame) Publisher() pb.Publisher {
+	return pb.Publisher_FLAME_SCANS
+}
+
+func (flame) Parse(doc *html.Node) ([]Comic, error) {
+	return parseItems(doc, classIs("", "bsx"), func(item *html.Node) (Comic, bool, error) {
+		cover := attr(find(item, "a", "div", "img"), "src")
+		info := first(item, classIs("div", "bigor"))
+		title := text(first(info, classIs("div", "tt")))
+		if title == "" || cover == "" {
+			return Comic{}, false, errors.New("flame: comic without title or cover")
+		}
+		chapters := findAll(info, classIs("div", "chapter-list"))
+		if len(chapters) == 0 {
+			return Comic{}, false, nil
+		}
+		chapter := text(find(chapters[0], "a", "div", "div"))
+		if chapter == "" {
+			return Comic{}, false, fmt.Errorf("flame: %s without chapter", title)
+		}
+		return Comic{Title: title, Chapter: chapter, Cover: cover, ComType: "manhwa", Status: "ongoing"}, true, nil
+	})
+}
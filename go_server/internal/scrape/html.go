@@ -0,0 +1,99 @@
+package scrape
+
+import (
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// The helpers follow the BeautifulSoup calls of src/scrape and are nil safe,
+// so a missing element shows up as an empty value at the end of a chain.
+
+// findAll returns the descendant elements that match, in document order
+func findAll(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	if n == nil {
+		return nil
+	}
+	found := []*html.Node{}
+	for child := range n.Descendants() {
+		if child.Type == html.ElementNode && match(child) {
+			found = append(found, child)
+		}
+	}
+	return found
+}
+
+// find follows the tags like tag.div.a in BeautifulSoup, each step is the
+// first descendant with that tag
+func find(n *html.Node, tags ...string) *html.Node {
+	for _, tag := range tags {
+		if n == nil {
+			return nil
+		}
+		var next *html.Node
+		for child := range n.Descendants() {
+			if child.Type == html.ElementNode && child.Data == tag {
+				next = child
+				break
+			}
+		}
+		n = next
+	}
+	return n
+}
+
+func tagIs(tag string) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		return n.Data == tag
+	}
+}
+
+// classIs matches the elements with the tag and the class, class holds one
+// name or the whole class attribute like the attrs filter of find_all
+func classIs(tag string, class string) func(*html.Node) bool {
+	return func(n *html.Node) bool {
+		if tag != "" && n.Data != tag {
+			return false
+		}
+		classes := strings.Fields(attr(n, "class"))
+		if strings.Contains(class, " ") {
+			return strings.Join(classes, " ") == class
+		}
+		return slices.Contains(classes, class)
+	}
+}
+
+// first returns the first descendant that matches, or nil
+func first(n *html.Node, match func(*html.Node) bool) *html.Node {
+	if found := findAll(n, match); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+func attr(n *html.Node, key string) string {
+	if n == nil {
+		return ""
+	}
+	for _, attribute := range n.Attr {
+		if attribute.Key == key {
+			return attribute.Val
+		}
+	}
+	return ""
+}
+
+// text returns the trimmed text of the node and its descendants
+func text(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	for child := range n.Descendants() {
+		if child.Type == html.TextNode {
+			b.WriteString(child.Data)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,352 @@
+package scrape
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"comics/internal/service"
+	"comics/pkg/pb"
+
+	"github.com/rs/zerolog/log"
+)
+
+var ErrJobFinished = errors.New("scrape job already finished")
+
+// JobStore keeps the scrape jobs and imports their comics, it is implemented
+// by SQLiteComicService
+type JobStore interface {
+	Import(ctx context.Context, comics []service.ComicJSON, dryRun bool) (service.ImportReport, error)
+	CreateScrapeJob(ctx context.Context, job service.ScrapeJob) (service.ScrapeJob, error)
+	SaveScrapeJob(ctx context.Context, job service.ScrapeJob) error
+	ScrapeJob(ctx context.Context, id int) (service.ScrapeJob, error)
+	ScrapeJobs(ctx context.Context, limit int) ([]service.ScrapeJob, error)
+	InterruptScrapeJobs(ctx context.Context) (int, error)
+}
+
+// Jobs runs scrape jobs in the background. The job records live in the
+// store, only the cancel functions of the running jobs are kept here.
+type Jobs struct {
+	client *Client
+	store  JobStore
+	// ctx is the parent of the job contexts, the jobs stop with it
+	ctx context.Context
+
+	mu      sync.Mutex
+	urls    map[pb.Publisher][]string
+	running map[int]*runningJob
+	// writes serializes the imports and the job saves, SQLite takes one
+	// writer at a time
+	writes sync.Mutex
+	wg     sync.WaitGroup
+}
+
+type runningJob struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	// urls are the pages when the job started
+	urls map[pb.Publisher][]string
+
+	mu  sync.Mutex
+	job service.ScrapeJob
+}
+
+// NewJobs returns the job runner of the pages in urls, its jobs are cancelled
+// when ctx ends. The jobs a previous process left unfinished are marked as
+// interrupted.
+func NewJobs(ctx context.Context, client *Client, store JobStore, urls map[pb.Publisher][]string) (*Jobs, error) {
+	interrupted, err := store.InterruptScrapeJobs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if interrupted > 0 {
+		log.Warn().Int("jobs", interrupted).Msg("Scrape jobs interrupted by a restart")
+	}
+	return &Jobs{
+		client:  client,
+		store:   store,
+		ctx:     ctx,
+		urls:    urls,
+		running: map[int]*runningJob{},
+	}, nil
+}
+
+// Start queues a job for the publishers, every publisher with pages when
+// none is given, and returns it right away.
+func (j *Jobs) Start(ctx context.Context, publishers []pb.Publisher, dryRun bool) (service.ScrapeJob, error) {
+	urls := j.URLs()
+	if len(publishers) == 0 {
+		for publisher := range urls {
+			publishers = append(publishers, publisher)
+		}
+	}
+	slices.Sort(publishers)
+	publishers = slices.Compact(publishers)
+
+	job := service.ScrapeJob{Status: service.ScrapeJobQueued, DryRun: dryRun}
+	for _, publisher := range publishers {
+		if _, ok := Lookup(publisher); !ok || len(urls[publisher]) == 0 {
+			return service.ScrapeJob{}, fmt.Errorf("%w: %s", ErrUnknownPublisher, publisher)
+		}
+		job.Publishers = append(job.Publishers, service.ScrapeJobProgress{
+			Publisher: int(publisher),
+			Status:    service.ScrapeJobQueued,
+			Pages:     len(urls[publisher]),
+		})
+	}
+	job, err := j.store.CreateScrapeJob(ctx, job)
+	if err != nil {
+		return service.ScrapeJob{}, err
+	}
+
+	jobCtx, cancel := context.WithCancel(j.ctx)
+	running := &runningJob{cancel: cancel, done: make(chan struct{}), urls: urls, job: job}
+	j.mu.Lock()
+	j.running[job.ID] = running
+	j.mu.Unlock()
+
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		defer close(running.done)
+		defer cancel()
+		j.run(jobCtx, running)
+		j.mu.Lock()
+		delete(j.running, job.ID)
+		j.mu.Unlock()
+	}()
+	return job, nil
+}
+
+// URLs returns the pages scraped by the new jobs
+func (j *Jobs) URLs() map[pb.Publisher][]string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.urls
+}
+
+// SetURLs changes the pages scraped by the new jobs, the running ones keep
+// theirs
+func (j *Jobs) SetURLs(urls map[pb.Publisher][]string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.urls = urls
+}
+
+func (j *Jobs) Get(ctx context.Context, id int) (service.ScrapeJob, error) {
+	return j.store.ScrapeJob(ctx, id)
+}
+
+// List returns the latest jobs, newest first
+func (j *Jobs) List(ctx context.Context, limit int) ([]service.ScrapeJob, error) {
+	return j.store.ScrapeJobs(ctx, limit)
+}
+
+// Cancel stops a running job through its context and returns it once it is
+// stopped. The pages imported before stay imported.
+func (j *Jobs) Cancel(ctx context.Context, id int) (service.ScrapeJob, error) {
+	j.mu.Lock()
+	running, ok := j.running[id]
+	j.mu.Unlock()
+	if !ok {
+		job, err := j.store.ScrapeJob(ctx, id)
+		if err != nil {
+			return service.ScrapeJob{}, err
+		}
+		if job.Finished() {
+			return job, ErrJobFinished
+		}
+		// Only a job of another process can be unfinished here
+		job.Status = service.ScrapeJobCancelled
+		return job, j.store.SaveScrapeJob(ctx, job)
+	}
+
+	running.cancel()
+	select {
+	case <-running.done:
+	case <-ctx.Done():
+		return service.ScrapeJob{}, ctx.Err()
+	}
+	return j.store.ScrapeJob(ctx, id)
+}
+
+// RunningPublishers returns the publishers being scraped by the running
+// jobs, sorted
+func (j *Jobs) RunningPublishers() []pb.Publisher {
+	j.mu.Lock()
+	running := make([]*runningJob, 0, len(j.running))
+	for _, job := range j.running {
+		running = append(running, job)
+	}
+	j.mu.Unlock()
+
+	publishers := []pb.Publisher{}
+	for _, job := range running {
+		job.update(func(job *service.ScrapeJob) {
+			for _, progress := range job.Publishers {
+				if progress.Status == service.ScrapeJobQueued || progress.Status == service.ScrapeJobRunning {
+					publishers = append(publishers, pb.Publisher(progress.Publisher))
+				}
+			}
+		})
+	}
+	slices.Sort(publishers)
+	return slices.Compact(publishers)
+}
+
+// Wait blocks until the running jobs end and their final state is saved, or
+// until ctx ends
+func (j *Jobs) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		j.wg.Wait()
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run scrapes the publishers concurrently and their pages one after the
+// other, the job is saved after every page
+func (j *Jobs) run(ctx context.Context, running *runningJob) {
+	running.update(func(job *service.ScrapeJob) {
+		job.Status = service.ScrapeJobRunning
+	})
+	j.save(running)
+
+	var wg sync.WaitGroup
+	for i := range len(running.job.Publishers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			j.runPublisher(ctx, running, i)
+		}()
+	}
+	wg.Wait()
+
+	running.update(func(job *service.ScrapeJob) {
+		job.Status = service.ScrapeJobSucceeded
+		failed := 0
+		for _, progress := range job.Publishers {
+			switch progress.Status {
+			case service.ScrapeJobCancelled:
+				job.Status = service.ScrapeJobCancelled
+			case service.ScrapeJobFailed:
+				failed++
+			}
+		}
+		if job.Status != service.ScrapeJobCancelled && failed > 0 && failed == len(job.Publishers) {
+			job.Status = service.ScrapeJobFailed
+		}
+	})
+	j.save(running)
+}
+
+// runPublisher scrapes the pages of the i-th publisher of the job. The
+// publisher fails when none of its pages could be imported.
+func (j *Jobs) runPublisher(ctx context.Context, running *runningJob, i int) {
+	var publisher pb.Publisher
+	var dryRun bool
+	running.update(func(job *service.ScrapeJob) {
+		job.Publishers[i].Status = service.ScrapeJobRunning
+		publisher, dryRun = pb.Publisher(job.Publishers[i].Publisher), job.DryRun
+	})
+	j.save(running)
+
+	imported := 0
+	for _, pageURL := range running.urls[publisher] {
+		if ctx.Err() != nil {
+			break
+		}
+		report, comics, errs := j.scrapePage(ctx, publisher, pageURL, dryRun)
+		// A page cut short by the cancellation isn't counted, one imported
+		// before it is
+		if report == nil && ctx.Err() != nil {
+			break
+		}
+		if report != nil {
+			imported++
+		}
+		running.update(func(job *service.ScrapeJob) {
+			progress := &job.Publishers[i]
+			progress.PagesDone++
+			progress.Comics += comics
+			progress.Errors = append(progress.Errors, errs...)
+			job.Errors = append(job.Errors, errs...)
+			if report != nil {
+				updated := report.Updated + report.Merged
+				progress.Created += report.Created
+				progress.Updated += updated
+				progress.Skipped += report.Skipped
+				job.Created += report.Created
+				job.Updated += updated
+				job.Skipped += report.Skipped
+			}
+		})
+		j.save(running)
+	}
+
+	running.update(func(job *service.ScrapeJob) {
+		progress := &job.Publishers[i]
+		switch {
+		case ctx.Err() != nil:
+			progress.Status = service.ScrapeJobCancelled
+		case imported == 0 && progress.Pages > 0:
+			progress.Status = service.ScrapeJobFailed
+		default:
+			progress.Status = service.ScrapeJobSucceeded
+		}
+	})
+	j.save(running)
+}
+
+// scrapePage scrapes and imports a page, the report is nil when the page
+// couldn't be imported. Errors are prefixed with the publisher.
+func (j *Jobs) scrapePage(
+	ctx context.Context,
+	publisher pb.Publisher,
+	pageURL string,
+	dryRun bool,
+) (*service.ImportReport, int, []string) {
+	errs := []string{}
+	result, err := j.client.Scrape(ctx, publisher, pageURL)
+	if err != nil {
+		return nil, 0, []string{fmt.Sprintf("%s: %v", publisher, err)}
+	}
+	for _, message := range result.Errors {
+		errs = append(errs, fmt.Sprintf("%s: %s", publisher, message))
+	}
+
+	j.writes.Lock()
+	defer j.writes.Unlock()
+	report, err := j.store.Import(ctx, result.Comics, dryRun)
+	if err != nil {
+		return nil, len(result.Comics), append(errs, fmt.Sprintf("%s: import: %v", publisher, err))
+	}
+	return &report, len(result.Comics), errs
+}
+
+// update changes the job under its lock
+func (r *runningJob) update(change func(job *service.ScrapeJob)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	change(&r.job)
+}
+
+// save stores the job, it is not tied to the job context so a cancelled job
+// still records its final state
+func (j *Jobs) save(running *runningJob) {
+	running.mu.Lock()
+	defer running.mu.Unlock()
+	j.writes.Lock()
+	defer j.writes.Unlock()
+	if err := j.store.SaveScrapeJob(context.Background(), running.job); err != nil {
+		log.Error().Err(err).Int("job", running.job.ID).Msg("Failed to save scrape job")
+	}
+}
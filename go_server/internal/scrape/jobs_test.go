@@ -0,0 +1,189 @@
+package scrape
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"comics/internal/service"
+	"comics/pkg/pb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryJobStore keeps the jobs in memory and counts every imported comic as
+// created
+type memoryJobStore struct {
+	mu       sync.Mutex
+	jobs     map[int]service.ScrapeJob
+	imported []service.ComicJSON
+}
+
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{jobs: map[int]service.ScrapeJob{}}
+}
+
+func (s *memoryJobStore) Import(_ context.Context, comics []service.ComicJSON, dryRun bool) (service.ImportReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !dryRun {
+		s.imported = append(s.imported, comics...)
+	}
+	return service.ImportReport{DryRun: dryRun, Created: len(comics)}, nil
+}
+
+func (s *memoryJobStore) CreateScrapeJob(_ context.Context, job service.ScrapeJob) (service.ScrapeJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.ID = len(s.jobs) + 1
+	s.jobs[job.ID] = job
+	return job, nil
+}
+
+func (s *memoryJobStore) SaveScrapeJob(_ context.Context, job service.ScrapeJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.ID]; !ok {
+		return service.ErrScrapeJobNotFound
+	}
+	// The runner keeps changing its copy
+	job.Publishers = append([]service.ScrapeJobProgress{}, job.Publishers...)
+	job.Errors = append([]string{}, job.Errors...)
+	s.jobs[job.ID] = job
+	return nil
+}
+
+func (s *memoryJobStore) ScrapeJob(_ context.Context, id int) (service.ScrapeJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return service.ScrapeJob{}, service.ErrScrapeJobNotFound
+	}
+	return job, nil
+}
+
+func (s *memoryJobStore) ScrapeJobs(context.Context, int) ([]service.ScrapeJob, error) {
+	return nil, nil
+}
+
+func (s *memoryJobStore) InterruptScrapeJobs(context.Context) (int, error) {
+	return 0, nil
+}
+
+func TestJobs(t *testing.T) {
+	server := newFixtureServer(t)
+	store := newMemoryJobStore()
+	ctx := context.Background()
+	jobs, err := NewJobs(ctx, NewClient(), store, map[pb.Publisher][]string{
+		pb.Publisher_FLAME_SCANS: {server.URL + "/missing.html"},
+		pb.Publisher_ASURA:       {server.URL + "/asura.html", server.URL + "/asura.html"},
+	})
+	require.NoError(t, err)
+
+	_, err = jobs.Start(ctx, []pb.Publisher{pb.Publisher_NOVEL_MIC}, false)
+	require.ErrorIs(t, err, ErrUnknownPublisher)
+
+	job, err := jobs.Start(ctx, nil, false)
+	require.NoError(t, err)
+	assert.Equal(t, service.ScrapeJobQueued, job.Status)
+	require.NoError(t, jobs.Wait(ctx))
+
+	job, err = jobs.Get(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, service.ScrapeJobSucceeded, job.Status)
+	assert.Equal(t, 4, job.Created)
+	require.Len(t, job.Publishers, 2)
+
+	asura := job.Publishers[0]
+	assert.Equal(t, int(pb.Publisher_ASURA), asura.Publisher)
+	assert.Equal(t, service.ScrapeJobSucceeded, asura.Status)
+	assert.Equal(t, 2, asura.Pages)
+	assert.Equal(t, 2, asura.PagesDone)
+	assert.Equal(t, 4, asura.Comics)
+	assert.Equal(t, 4, asura.Created)
+	assert.Empty(t, asura.Errors)
+
+	flame := job.Publishers[1]
+	assert.Equal(t, int(pb.Publisher_FLAME_SCANS), flame.Publisher)
+	assert.Equal(t, service.ScrapeJobFailed, flame.Status)
+	assert.Equal(t, 1, flame.PagesDone)
+	require.Len(t, job.Errors, 1)
+	assert.Contains(t, job.Errors[0], "FLAME_SCANS: fetching")
+	assert.Equal(t, job.Errors, flame.Errors)
+	assert.Len(t, store.imported, 4)
+
+	_, err = jobs.Cancel(ctx, job.ID)
+	assert.ErrorIs(t, err, ErrJobFinished)
+	_, err = jobs.Cancel(ctx, 42)
+	assert.ErrorIs(t, err, service.ErrScrapeJobNotFound)
+}
+
+func TestJobsCancel(t *testing.T) {
+	requested := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(requested)
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	store := newMemoryJobStore()
+	ctx := context.Background()
+	jobs, err := NewJobs(ctx, NewClient(), store, map[pb.Publisher][]string{
+		pb.Publisher_ASURA: {server.URL + "/slow", server.URL + "/never"},
+	})
+	require.NoError(t, err)
+
+	job, err := jobs.Start(ctx, []pb.Publisher{pb.Publisher_ASURA}, true)
+	require.NoError(t, err)
+	<-requested
+
+	job, err = jobs.Cancel(ctx, job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, service.ScrapeJobCancelled, job.Status)
+	assert.True(t, job.DryRun)
+	require.Len(t, job.Publishers, 1)
+	assert.Equal(t, service.ScrapeJobCancelled, job.Publishers[0].Status)
+	assert.Equal(t, 0, job.Publishers[0].PagesDone)
+	assert.Empty(t, job.Errors)
+	assert.Empty(t, store.imported)
+}
+
+// cancelingStore cancels the jobs right after an import, as a shutdown
+// between two pages would
+type cancelingStore struct {
+	*memoryJobStore
+	cancel context.CancelFunc
+}
+
+func (s cancelingStore) Import(ctx context.Context, comics []service.ComicJSON, dryRun bool) (service.ImportReport, error) {
+	defer s.cancel()
+	return s.memoryJobStore.Import(ctx, comics, dryRun)
+}
+
+func TestJobsStopWithContext(t *testing.T) {
+	server := newFixtureServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := cancelingStore{newMemoryJobStore(), cancel}
+	jobs, err := NewJobs(ctx, NewClient(), store, map[pb.Publisher][]string{
+		pb.Publisher_ASURA: {server.URL + "/asura.html", server.URL + "/asura.html"},
+	})
+	require.NoError(t, err)
+
+	job, err := jobs.Start(context.Background(), nil, false)
+	require.NoError(t, err)
+	require.NoError(t, jobs.Wait(context.Background()))
+
+	// The page imported before the cancellation is counted
+	job, err = store.ScrapeJob(context.Background(), job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, service.ScrapeJobCancelled, job.Status)
+	assert.Equal(t, 2, job.Created)
+	assert.Equal(t, 1, job.Publishers[0].PagesDone)
+	assert.Equal(t, 2, job.Publishers[0].Comics)
+	assert.Len(t, store.imported, 2)
+}
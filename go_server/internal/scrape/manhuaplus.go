@@ -0,0 +1,35 @@
+package scrape
+
+import (
+	"errors"
+	"fmt"
+
+	"comics/pkg/pb"
+
+	"golang.org/x/net/html"
+)
+
+// manhuaPlus reads the updates of Manhua Plus, like src/scrape/manhuaplus.py.
+// Covers are lazy loaded from data-src.
+type manhuaPlus struct{}
+
+func (manhuaPlus) Publisher() pb.Publisher {
+	return pb.Publisher_MANHUA_PLUS
+}
+
+func (manhuaPlus) Parse(doc *html.Node) ([]Comic, error) {
+	return parseItems(doc, classIs("div", "page-item-detail"), func(item *html.Node) (Comic, bool, error) {
+		cover := attr(find(item, "div", "a", "img"), "data-src")
+		info := first(item, classIs("div", "item-summary"))
+		title := text(find(first(info, classIs("div", "post-title")), "h3", "a"))
+		if title == "" || cover == "" {
+			return Comic{}, false, errors.New("manhuaplus: comic without title or cover")
+		}
+		chapters := findAll(info, classIs("div", "chapter-item"))
+		if len(chapters) == 0 {
+			return Comic{}, false, fmt.Errorf("manhuaplus: %s without chapters", title)
+		}
+		chapter := text(find(chapters[0], "span", "a"))
+		return Comic{Title: title, Chapter: chapter, Cover: cover, ComType: "manhua", Status: "ongoing"}, true, nil
+	})
+}
@@ -0,0 +1,527 @@
+package scrape
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"comics/internal/metrics"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// robotsRetry is how long a robots.txt that couldn't be fetched is
+	// considered missing
+	robotsRetry = 10 * time.Minute
+	// maxCachedBody caps the pages kept for conditional requests
+	maxCachedBody = 2 << 20
+	// maxRetryAfter caps the wait a Retry-After header asks for
+	maxRetryAfter = 5 * time.Minute
+)
+
+var (
+	ErrDisallowed  = errors.New("disallowed by robots.txt")
+	ErrCircuitOpen = errors.New("circuit open, the host failed too often")
+
+	errRetryableStatus = errors.New("retryable response")
+)
+
+// PoliteConfig tunes the PoliteTransport, every limit applies per host
+type PoliteConfig struct {
+	// Rate is the number of requests per second and Burst the requests sent
+	// at once before the rate applies, a robots.txt Crawl-delay can lower it
+	Rate  float64
+	Burst int
+	// Timeout applies to each attempt of a request
+	Timeout time.Duration
+	// Retries of a request that failed with a network error, a 429 or a 5xx,
+	// spaced by the exponential backoff starting at RetryInterval
+	Retries       uint64
+	RetryInterval time.Duration
+	// BreakerFailures failed attempts in a row open the circuit of the host,
+	// a trial request goes through after BreakerCooldown
+	BreakerFailures int
+	BreakerCooldown time.Duration
+	// UserAgent is sent with every request and looked up in robots.txt, so
+	// the rules followed are the ones of the agent the publishers see. The
+	// rules are kept for RobotsTTL.
+	UserAgent string
+	RobotsTTL time.Duration
+	// CacheEntries caps the pages kept for conditional requests
+	CacheEntries int
+}
+
+func DefaultPoliteConfig() PoliteConfig {
+	return PoliteConfig{
+		Rate:            0.5,
+		Burst:           2,
+		Timeout:         requestTimeout,
+		Retries:         3,
+		RetryInterval:   time.Second,
+		BreakerFailures: 5,
+		BreakerCooldown: 5 * time.Minute,
+		UserAgent:       "comics/1.0",
+		RobotsTTL:       24 * time.Hour,
+		CacheEntries:    256,
+	}
+}
+
+// PoliteTransport sends the GET requests of the scrapers without hammering
+// the publishers: it respects robots.txt and a rate limit per host, retries
+// the failed requests with backoff, or after the Retry-After of a 429 or a
+// 503, stops calling the hosts failing in a row
+// and revalidates the pages it has seen with ETag and Last-Modified. Every
+// decision is recorded in the metrics.
+type PoliteTransport struct {
+	base    http.RoundTripper
+	config  PoliteConfig
+	metrics metrics.ScrapeCollector
+	now     func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+	cache map[string]*cachedPage
+}
+
+type hostState struct {
+	limiter *rate.Limiter
+
+	// robotsMu holds the robots.txt fetch, one at a time
+	robotsMu     sync.Mutex
+	robots       *robotsRules
+	robotsExpiry time.Time
+
+	mu       sync.Mutex
+	failures int
+	circuit  int
+	openedAt time.Time
+}
+
+// cachedPage is a page kept with its validators
+type cachedPage struct {
+	etag         string
+	lastModified string
+	header       http.Header
+	body         []byte
+	stored       time.Time
+}
+
+// NewPoliteTransport wraps base, http.DefaultTransport when nil
+func NewPoliteTransport(base http.RoundTripper, config PoliteConfig, collector metrics.ScrapeCollector) *PoliteTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &PoliteTransport{
+		base:    base,
+		config:  config,
+		metrics: collector,
+		now:     time.Now,
+		hosts:   map[string]*hostState{},
+		cache:   map[string]*cachedPage{},
+	}
+}
+
+// NewPoliteClient returns a client sending its requests through a
+// PoliteTransport
+func NewPoliteClient(config PoliteConfig, collector metrics.ScrapeCollector) *Client {
+	return &Client{HTTP: &http.Client{Transport: NewPoliteTransport(http.DefaultTransport, config, collector)}}
+}
+
+// RoundTrip sends a GET request politely, the other methods go straight to
+// the base transport
+func (t *PoliteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.base.RoundTrip(req)
+	}
+	name := req.URL.Host
+	host := t.host(name)
+	if !t.robots(req, host).allowed(req.URL) {
+		t.metrics.RecordDecision(name, metrics.DecisionDisallowed)
+		return nil, fmt.Errorf("%w: %s", ErrDisallowed, req.URL)
+	}
+
+	delays := &retryAfterBackOff{
+		BackOff: backoff.NewExponentialBackOff(backoff.WithInitialInterval(t.config.RetryInterval)),
+	}
+	retries := backoff.WithContext(backoff.WithMaxRetries(delays, t.config.Retries), req.Context())
+	resp, err := backoff.RetryNotifyWithData(func() (*http.Response, error) {
+		resp, err := t.attempt(req, host)
+		var retryAfter *retryAfterError
+		if errors.As(err, &retryAfter) {
+			delays.next = retryAfter.wait
+		}
+		return resp, err
+	}, retries, func(err error, wait time.Duration) {
+		t.metrics.RecordDecision(name, metrics.DecisionRetried)
+		log.Debug().Err(err).Str("host", name).Dur("wait", wait).Msg("Retrying scrape request")
+	})
+	if err != nil && !errors.Is(err, ErrCircuitOpen) && req.Context().Err() == nil {
+		t.metrics.RecordDecision(name, metrics.DecisionGaveUp)
+	}
+	return resp, err
+}
+
+// attempt sends the request once, the errors worth retrying aren't permanent
+func (t *PoliteTransport) attempt(req *http.Request, host *hostState) (*http.Response, error) {
+	name := req.URL.Host
+	if err := t.wait(req.Context(), name, host); err != nil {
+		return nil, backoff.Permanent(err)
+	}
+	if !t.allow(name, host) {
+		t.metrics.RecordDecision(name, metrics.DecisionCircuitOpen)
+		return nil, backoff.Permanent(fmt.Errorf("%w: %s", ErrCircuitOpen, name))
+	}
+	t.metrics.RecordDecision(name, metrics.DecisionAllowed)
+
+	ctx, cancel := context.WithCancel(req.Context())
+	if t.config.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(req.Context(), t.config.Timeout)
+	}
+	attempt := req.Clone(ctx)
+	if t.config.UserAgent != "" {
+		attempt.Header.Set("User-Agent", t.config.UserAgent)
+	}
+	cached := t.cached(req.URL.String())
+	if cached != nil {
+		if cached.etag != "" {
+			attempt.Header.Set("If-None-Match", cached.etag)
+		}
+		if cached.lastModified != "" {
+			attempt.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+	}
+
+	start := t.now()
+	resp, err := t.base.RoundTrip(attempt)
+	if err != nil {
+		cancel()
+		t.metrics.RecordRequest(name, 0, t.now().Sub(start))
+		t.failure(name, host)
+		if req.Context().Err() != nil {
+			return nil, backoff.Permanent(err)
+		}
+		return nil, err
+	}
+	t.metrics.RecordRequest(name, resp.StatusCode, t.now().Sub(start))
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+		discard(resp)
+		cancel()
+		t.failure(name, host)
+		err := fmt.Errorf("%w: %s", errRetryableStatus, resp.Status)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), t.now()); ok {
+				return nil, &retryAfterError{err: err, wait: wait}
+			}
+		}
+		return nil, err
+	}
+	t.success(name, host)
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		discard(resp)
+		cancel()
+		t.metrics.RecordDecision(name, metrics.DecisionNotModified)
+		return cached.response(req), nil
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	if resp.StatusCode == http.StatusOK {
+		return t.store(req, resp)
+	}
+	return resp, nil
+}
+
+func (t *PoliteTransport) host(name string) *hostState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	host, ok := t.hosts[name]
+	if !ok {
+		host = &hostState{limiter: rate.NewLimiter(t.limit(), max(t.config.Burst, 1))}
+		t.hosts[name] = host
+	}
+	return host
+}
+
+func (t *PoliteTransport) limit() rate.Limit {
+	if t.config.Rate <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(t.config.Rate)
+}
+
+// wait blocks until the rate limit of the host lets a request through
+func (t *PoliteTransport) wait(ctx context.Context, name string, host *hostState) error {
+	reservation := host.limiter.Reserve()
+	delay := reservation.Delay()
+	if delay == 0 {
+		return nil
+	}
+	t.metrics.RecordDecision(name, metrics.DecisionThrottled)
+	t.metrics.RecordWait(name, delay)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		return ctx.Err()
+	}
+}
+
+// robots returns the robots.txt rules of the host, fetched again once
+// expired. A Crawl-delay longer than the configured rate slows the host down.
+func (t *PoliteTransport) robots(req *http.Request, host *hostState) *robotsRules {
+	host.robotsMu.Lock()
+	defer host.robotsMu.Unlock()
+	if t.now().Before(host.robotsExpiry) {
+		return host.robots
+	}
+	rules, ttl := t.fetchRobots(req, host)
+	host.robots, host.robotsExpiry = rules, t.now().Add(ttl)
+
+	limit, burst := t.limit(), max(t.config.Burst, 1)
+	if rules != nil && rules.crawlDelay > 0 && rate.Every(rules.crawlDelay) < limit {
+		limit, burst = rate.Every(rules.crawlDelay), 1
+	}
+	host.limiter.SetLimit(limit)
+	host.limiter.SetBurst(burst)
+	return rules
+}
+
+// fetchRobots reads the robots.txt of the host of the request. A missing
+// robots.txt allows everything, as does one that couldn't be fetched until
+// robotsRetry.
+func (t *PoliteTransport) fetchRobots(req *http.Request, host *hostState) (*robotsRules, time.Duration) {
+	name := req.URL.Host
+	robotsURL := &url.URL{Scheme: req.URL.Scheme, Host: name, Path: "/robots.txt"}
+	ctx, cancel := context.WithCancel(req.Context())
+	if t.config.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(req.Context(), t.config.Timeout)
+	}
+	defer cancel()
+	robotsReq, err := http.NewRequestWithContext(ctx, http.MethodGet, robotsURL.String(), nil)
+	if err != nil {
+		return nil, robotsRetry
+	}
+	robotsReq.Header.Set("User-Agent", t.config.UserAgent)
+	if err := t.wait(ctx, name, host); err != nil {
+		return nil, 0
+	}
+
+	start := t.now()
+	resp, err := t.base.RoundTrip(robotsReq)
+	if err != nil {
+		t.metrics.RecordRequest(name, 0, t.now().Sub(start))
+		log.Warn().Err(err).Str("host", name).Msg("robots.txt unavailable, every page allowed")
+		return nil, robotsRetry
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	t.metrics.RecordRequest(name, resp.StatusCode, t.now().Sub(start))
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		t.metrics.RecordDecision(name, metrics.DecisionRobotsLoaded)
+		return parseRobots(resp.Body, t.config.UserAgent), t.config.RobotsTTL
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+		log.Warn().Str("host", name).Str("status", resp.Status).Msg("robots.txt unavailable, every page allowed")
+		return nil, robotsRetry
+	default:
+		return nil, t.config.RobotsTTL
+	}
+}
+
+// retryAfterError is a retryable response asking to wait before the next
+// attempt
+type retryAfterError struct {
+	err  error
+	wait time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return fmt.Sprintf("%v, retry after %s", e.err, e.wait)
+}
+
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+// retryAfterBackOff waits next, when set by the last attempt, instead of the
+// backoff delay
+type retryAfterBackOff struct {
+	backoff.BackOff
+	next time.Duration
+}
+
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	delay := b.BackOff.NextBackOff()
+	if delay != backoff.Stop && b.next > 0 {
+		delay = b.next
+	}
+	b.next = 0
+	return delay
+}
+
+// parseRetryAfter reads a Retry-After header, either seconds or an HTTP date,
+// capped to maxRetryAfter
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		wait = date.Sub(now)
+	} else {
+		return 0, false
+	}
+	return min(max(wait, 0), maxRetryAfter), true
+}
+
+// allow tells whether the circuit of the host lets a request through. An
+// open circuit lets a single trial through once cooled down, its result
+// closes or opens the circuit again.
+func (t *PoliteTransport) allow(name string, host *hostState) bool {
+	host.mu.Lock()
+	defer host.mu.Unlock()
+	switch host.circuit {
+	case metrics.CircuitOpen:
+		if t.now().Sub(host.openedAt) < t.config.BreakerCooldown {
+			return false
+		}
+		host.circuit = metrics.CircuitHalfOpen
+		t.metrics.SetCircuitState(name, host.circuit)
+		return true
+	case metrics.CircuitHalfOpen:
+		return false
+	}
+	return true
+}
+
+func (t *PoliteTransport) failure(name string, host *hostState) {
+	host.mu.Lock()
+	defer host.mu.Unlock()
+	host.failures++
+	if t.config.BreakerFailures <= 0 {
+		return
+	}
+	if host.circuit == metrics.CircuitHalfOpen || host.failures >= t.config.BreakerFailures {
+		if host.circuit != metrics.CircuitOpen {
+			log.Warn().Str("host", name).Int("failures", host.failures).Msg("Scrape circuit opened")
+		}
+		host.circuit = metrics.CircuitOpen
+		host.openedAt = t.now()
+		t.metrics.SetCircuitState(name, host.circuit)
+	}
+}
+
+func (t *PoliteTransport) success(name string, host *hostState) {
+	host.mu.Lock()
+	defer host.mu.Unlock()
+	host.failures = 0
+	if host.circuit != metrics.CircuitClosed {
+		log.Info().Str("host", name).Msg("Scrape circuit closed")
+		host.circuit = metrics.CircuitClosed
+		t.metrics.SetCircuitState(name, host.circuit)
+	}
+}
+
+func (t *PoliteTransport) cached(key string) *cachedPage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cache[key]
+}
+
+// store keeps the page when it has a validator and fits in maxCachedBody,
+// the oldest page makes room when the cache is full
+func (t *PoliteTransport) store(req *http.Request, resp *http.Response) (*http.Response, error) {
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if t.config.CacheEntries <= 0 || (etag == "" && lastModified == "") {
+		return resp, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCachedBody+1))
+	if err != nil {
+		resp.Body.Close() // nolint:errcheck
+		return nil, err
+	}
+	if len(body) > maxCachedBody {
+		resp.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(body), resp.Body), Closer: resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close() // nolint:errcheck
+
+	page := &cachedPage{
+		etag:         etag,
+		lastModified: lastModified,
+		header:       resp.Header.Clone(),
+		body:         body,
+		stored:       t.now(),
+	}
+	t.mu.Lock()
+	key := req.URL.String()
+	if _, ok := t.cache[key]; !ok && len(t.cache) >= t.config.CacheEntries {
+		oldest := ""
+		for key, page := range t.cache {
+			if oldest == "" || page.stored.Before(t.cache[oldest].stored) {
+				oldest = key
+			}
+		}
+		delete(t.cache, oldest)
+	}
+	t.cache[key] = page
+	t.mu.Unlock()
+	t.metrics.RecordDecision(req.URL.Host, metrics.DecisionCacheStored)
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, nil
+}
+
+// response answers the request with the cached page
+func (p *cachedPage) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        p.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(p.body)),
+		ContentLength: int64(len(p.body)),
+		Request:       req,
+	}
+}
+
+// discard closes a response that won't be read, after draining a bit of it
+// so the connection can be reused
+func discard(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+	resp.Body.Close() // nolint:errcheck
+}
+
+// cancelBody ends the context of an attempt once its body is closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,350 @@
+package scrape
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"comics/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingCollector counts the decisions of every host together
+type recordingCollector struct {
+	mu        sync.Mutex
+	decisions map[string]int
+	statuses  []int
+	circuit   int
+}
+
+func newRecordingCollector() *recordingCollector {
+	return &recordingCollector{decisions: map[string]int{}}
+}
+
+func (c *recordingCollector) RecordDecision(_, decision string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.decisions[decision]++
+}
+
+func (c *recordingCollector) RecordRequest(_ string, status int, _ time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.statuses = append(c.statuses, status)
+}
+
+func (c *recordingCollector) RecordWait(string, time.Duration) {}
+
+func (c *recordingCollector) SetCircuitState(_ string, state int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.circuit = state
+}
+
+func testPoliteConfig() PoliteConfig {
+	config := DefaultPoliteConfig()
+	config.Rate = 0
+	config.RetryInterval = time.Millisecond
+	config.BreakerFailures = 2
+	config.BreakerCooldown = time.Minute
+	return config
+}
+
+func politeGet(t *testing.T, transport *PoliteTransport, page string) (string, error) {
+	t.Helper()
+	client := &http.Client{Transport: transport}
+	resp, err := client.Get(page)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	return string(body), nil
+}
+
+func TestPoliteTransportRobots(t *testing.T) {
+	var mu sync.Mutex
+	agents := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents = append(agents, r.UserAgent())
+		mu.Unlock()
+		if r.URL.Path == "/robots.txt" {
+			_, _ = io.WriteString(w, "User-agent: *\nDisallow: /private\nAllow: /private/ok$\n\n"+
+				"User-agent: comics\nDisallow: /hidden\n")
+			return
+		}
+		_, _ = io.WriteString(w, r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+
+	collector := newRecordingCollector()
+	transport := NewPoliteTransport(nil, testPoliteConfig(), collector)
+
+	// The scrapers' browser agent is replaced by the one robots.txt names
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/updates", nil)
+	require.NoError(t, err)
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "/updates", string(body))
+	assert.Equal(t, []string{"comics/1.0", "comics/1.0"}, agents)
+
+	// Only the comics group applies
+	_, err = politeGet(t, transport, server.URL+"/hidden/page")
+	require.ErrorIs(t, err, ErrDisallowed)
+	_, err = politeGet(t, transport, server.URL+"/private/page")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{
+		metrics.DecisionRobotsLoaded: 1,
+		metrics.DecisionAllowed:      2,
+		metrics.DecisionDisallowed:   1,
+	}, collector.decisions)
+	assert.Equal(t, []int{200, 200, 200}, collector.statuses)
+}
+
+func TestPoliteTransportRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(server.Close)
+
+	transport := NewPoliteTransport(nil, testPoliteConfig(), newRecordingCollector())
+	start := time.Now()
+	body, err := politeGet(t, transport, server.URL+"/updates")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", body)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tests := map[string]time.Duration{
+		"120":                           2 * time.Minute,
+		"Sat, 17 Oct 2026 12:00:30 GMT": 30 * time.Second,
+		"Sat, 17 Oct 2026 11:00:00 GMT": 0,
+		"86400":                         maxRetryAfter,
+	}
+	for value, expected := range tests {
+		wait, ok := parseRetryAfter(value, now)
+		assert.True(t, ok, value)
+		assert.Equal(t, expected, wait, value)
+	}
+	for _, value := range []string{"", "soon", "1.5"} {
+		_, ok := parseRetryAfter(value, now)
+		assert.False(t, ok, value)
+	}
+}
+
+func TestPoliteTransportRetries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		if requests.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(server.Close)
+
+	collector := newRecordingCollector()
+	config := testPoliteConfig()
+	config.BreakerFailures = 5
+	transport := NewPoliteTransport(nil, config, collector)
+
+	body, err := politeGet(t, transport, server.URL+"/updates")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", body)
+	assert.Equal(t, 2, collector.decisions[metrics.DecisionRetried])
+	assert.Equal(t, 3, collector.decisions[metrics.DecisionAllowed])
+	assert.Equal(t, []int{404, 503, 503, 200}, collector.statuses)
+
+	// Every retry fails
+	requests.Store(-10)
+	_, err = politeGet(t, transport, server.URL+"/updates")
+	require.ErrorIs(t, err, errRetryableStatus)
+	assert.Equal(t, 1, collector.decisions[metrics.DecisionGaveUp])
+	assert.Equal(t, 5, collector.decisions[metrics.DecisionRetried])
+}
+
+func TestPoliteTransportCircuit(t *testing.T) {
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" || !healthy.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(server.Close)
+
+	collector := newRecordingCollector()
+	transport := NewPoliteTransport(nil, testPoliteConfig(), collector)
+	now := time.Now()
+	transport.now = func() time.Time { return now }
+
+	// The second failure opens the circuit, the third attempt is refused
+	_, err := politeGet(t, transport, server.URL+"/updates")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, metrics.CircuitOpen, collector.circuit)
+	assert.Equal(t, 2, collector.decisions[metrics.DecisionAllowed])
+	assert.Equal(t, 1, collector.decisions[metrics.DecisionCircuitOpen])
+	assert.Zero(t, collector.decisions[metrics.DecisionGaveUp])
+
+	healthy.Store(true)
+	_, err = politeGet(t, transport, server.URL+"/updates")
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	// A trial goes through once cooled down and closes the circuit
+	now = now.Add(time.Minute)
+	body, err := politeGet(t, transport, server.URL+"/updates")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", body)
+	assert.Equal(t, metrics.CircuitClosed, collector.circuit)
+}
+
+func TestPoliteTransportCache(t *testing.T) {
+	var notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, "page "+r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+
+	collector := newRecordingCollector()
+	config := testPoliteConfig()
+	config.CacheEntries = 1
+	transport := NewPoliteTransport(nil, config, collector)
+
+	for range 2 {
+		body, err := politeGet(t, transport, server.URL+"/updates")
+		require.NoError(t, err)
+		assert.Equal(t, "page /updates", body)
+	}
+	assert.Equal(t, int32(1), notModified.Load())
+	assert.Equal(t, 1, collector.decisions[metrics.DecisionNotModified])
+	assert.Equal(t, 1, collector.decisions[metrics.DecisionCacheStored])
+
+	// The cache holds one page, the first one makes room
+	_, err := politeGet(t, transport, server.URL+"/other")
+	require.NoError(t, err)
+	_, err = politeGet(t, transport, server.URL+"/updates")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), notModified.Load())
+	assert.Len(t, transport.cache, 1)
+}
+
+func TestPoliteTransportRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			_, _ = io.WriteString(w, "User-agent: comics\nCrawl-delay: 0.05\n")
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(server.Close)
+
+	collector := newRecordingCollector()
+	config := testPoliteConfig()
+	config.Rate = 1000
+	transport := NewPoliteTransport(nil, config, collector)
+
+	start := time.Now()
+	for range 3 {
+		_, err := politeGet(t, transport, server.URL+"/updates")
+		require.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, 2, collector.decisions[metrics.DecisionThrottled])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/updates", nil)
+	require.NoError(t, err)
+	_, err = (&http.Client{Transport: transport}).Do(req)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParseRobots(t *testing.T) {
+	robots := `
+# Comments and unknown lines are ignored
+Sitemap: https://example.com/sitemap.xml
+
+User-agent: Googlebot
+Disallow: /
+
+User-agent: comics
+User-agent: other
+Disallow: /series/*/chapter
+Allow: /series/*/chapter-1$
+Disallow: /search?
+Crawl-delay: 3
+
+User-agent: *
+Disallow: /series
+`
+	rules := parseRobots(strings.NewReader(robots), "Comics/1.0")
+	assert.Equal(t, 3*time.Second, rules.crawlDelay)
+	tests := map[string]bool{
+		"/":                        true,
+		"/robots.txt":              true,
+		"/series/solo":             true,
+		"/series/solo/chapter-12":  false,
+		"/series/solo/chapter-1":   true,
+		"/series/solo/chapter-1/2": false,
+		"/search?q=solo":           false,
+		"/search":                  true,
+	}
+	for page, allowed := range tests {
+		pageURL, err := url.Parse("https://example.com" + page)
+		require.NoError(t, err)
+		assert.Equal(t, allowed, rules.allowed(pageURL), page)
+	}
+
+	// The agent falls back to the * group
+	rules = parseRobots(strings.NewReader(robots), "someone")
+	assert.False(t, rules.allowed(&url.URL{Path: "/series/solo"}))
+	assert.True(t, rules.allowed(&url.URL{Path: "/latest"}))
+	assert.Zero(t, rules.crawlDelay)
+
+	// Allow wins a tie and nil rules allow everything
+	rules = parseRobots(strings.NewReader("User-agent: *\nDisallow: /a\nAllow: /a\n"), "comics")
+	assert.True(t, rules.allowed(&url.URL{Path: "/a"}))
+	assert.True(t, (*robotsRules)(nil).allowed(&url.URL{Path: "/a"}))
+}
@@ -0,0 +1,46 @@
+package scrape
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"comics/pkg/pb"
+
+	"golang.org/x/net/html"
+)
+
+// realm reads the updates of Realm Scans, like src/scrape/realm.py. The
+// pages give the status of each comic and its type as the class of the
+// chapter list.
+type realm struct{}
+
+func (realm) Publisher() pb.Publisher {
+	return pb.Publisher_REALM_SCANS
+}
+
+func (realm) Parse(doc *html.Node) ([]Comic, error) {
+	return parseItems(doc, classIs("", "uta"), func(item *html.Node) (Comic, bool, error) {
+		link := find(item, "div", "a")
+		cover := attr(find(link, "img"), "src")
+		status := text(find(link, "div", "span"))
+		info := first(item, classIs("div", "luf"))
+		title := text(find(info, "a", "h4"))
+		if title == "" || cover == "" {
+			return Comic{}, false, errors.New("realm: comic without title or cover")
+		}
+		list := find(info, "ul")
+		if list == nil {
+			return Comic{}, false, nil
+		}
+		comType := "manhwa"
+		if classes := strings.Fields(attr(list, "class")); len(classes) > 0 {
+			comType = classes[0]
+		}
+		chapter := text(find(list, "li", "a"))
+		if chapter == "" {
+			return Comic{}, false, fmt.Errorf("realm: %s without chapter", title)
+		}
+		return Comic{Title: title, Chapter: chapter, Cover: cover, ComType: comType, Status: status}, true, nil
+	})
+}
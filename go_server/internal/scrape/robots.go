@@ -0,0 +1,144 @@
+package scrape
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRobotsSize caps the robots.txt read, the rest is ignored like Google does
+const maxRobotsSize = 500 << 10
+
+// robotsRules are the robots.txt rules of an agent. The longest pattern that
+// matches a path wins and Allow wins a tie, nil rules allow everything.
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+	// closed is set by the first rule, a user-agent line after it starts a
+	// new group
+	closed bool
+}
+
+// parseRobots reads the rules of the groups naming the agent, or of the *
+// groups when none does
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	groups := []*robotsGroup{}
+	var group *robotsGroup
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsSize))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		if key == "user-agent" {
+			if group == nil || group.closed {
+				group = &robotsGroup{}
+				groups = append(groups, group)
+			}
+			group.agents = append(group.agents, strings.ToLower(value))
+			continue
+		}
+		if group == nil {
+			continue
+		}
+		switch key {
+		case "allow", "disallow":
+			group.closed = true
+			if value != "" {
+				group.rules = append(group.rules, robotsRule{
+					allow:   key == "allow",
+					length:  len(value),
+					pattern: robotsPattern(value),
+				})
+			}
+		case "crawl-delay":
+			group.closed = true
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				group.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		}
+	}
+
+	agent = strings.ToLower(agent)
+	rules := &robotsRules{}
+	for _, wildcard := range []bool{false, true} {
+		matched := false
+		for _, group := range groups {
+			if !slices.ContainsFunc(group.agents, func(name string) bool {
+				if wildcard {
+					return name == "*"
+				}
+				return name != "*" && name != "" && strings.Contains(agent, name)
+			}) {
+				continue
+			}
+			matched = true
+			rules.rules = append(rules.rules, group.rules...)
+			rules.crawlDelay = max(rules.crawlDelay, group.crawlDelay)
+		}
+		if matched {
+			break
+		}
+	}
+	return rules
+}
+
+// robotsPattern matches the paths starting like the value, * matches any
+// characters and a final $ the end of the path
+func robotsPattern(value string) *regexp.Regexp {
+	anchored := strings.HasSuffix(value, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSuffix(value, "$")), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// allowed tells whether the page can be fetched
+func (r *robotsRules) allowed(page *url.URL) bool {
+	if r == nil {
+		return true
+	}
+	path := page.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+	if page.RawQuery != "" {
+		path += "?" + page.RawQuery
+	}
+
+	longest, allow := -1, true
+	for _, rule := range r.rules {
+		if rule.length < longest || !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > longest || rule.allow {
+			allow = rule.allow
+		}
+		longest = rule.length
+	}
+	return allow
+}
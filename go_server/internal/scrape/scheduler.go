@@ -0,0 +1,313 @@
+package scrape
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"comics/internal/service"
+	"comics/pkg/pb"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrNoSchedule      = errors.New("no schedule for the publisher")
+	ErrInvalidSchedule = errors.New("invalid scrape schedule")
+	ErrAlreadyRunning  = errors.New("the publisher is already being scraped")
+)
+
+// schedulerTick is how often the scheduler looks for due publishers
+const schedulerTick = 15 * time.Second
+
+// jobStarter runs the scheduled scrapes, it is implemented by Jobs
+type jobStarter interface {
+	Start(ctx context.Context, publishers []pb.Publisher, dryRun bool) (service.ScrapeJob, error)
+	RunningPublishers() []pb.Publisher
+}
+
+// SchedulerConfig sets the interval of each scheduled publisher, the jitter
+// added to every interval and the number of publishers scraped at once
+type SchedulerConfig struct {
+	Intervals     map[pb.Publisher]time.Duration
+	Jitter        time.Duration
+	MaxConcurrent int
+}
+
+// ScheduleState is the state of the schedule of a publisher. A run due while
+// the publisher is still being scraped is skipped.
+type ScheduleState struct {
+	Publisher   pb.Publisher `json:"publisher"`
+	Name        string       `json:"name"`
+	Interval    string       `json:"interval"`
+	Paused      bool         `json:"paused"`
+	Running     bool         `json:"running"`
+	NextRun     time.Time    `json:"next_run"`
+	LastRun     *time.Time   `json:"last_run,omitempty"`
+	LastJobID   int          `json:"last_job_id,omitempty"`
+	SkippedRuns int          `json:"skipped_runs"`
+	LastError   string       `json:"last_error,omitempty"`
+}
+
+// Scheduler starts a scrape job for each publisher at its interval. The
+// schedules live in memory, a restart starts them over from the config.
+type Scheduler struct {
+	jobs          jobStarter
+	jitter        time.Duration
+	maxConcurrent int
+	now           func() time.Time
+
+	mu        sync.Mutex
+	schedules map[pb.Publisher]*ScheduleState
+	intervals map[pb.Publisher]time.Duration
+}
+
+func NewScheduler(jobs jobStarter, config SchedulerConfig) *Scheduler {
+	s := &Scheduler{
+		jobs:          jobs,
+		jitter:        config.Jitter,
+		maxConcurrent: max(config.MaxConcurrent, 1),
+		now:           time.Now,
+		schedules:     map[pb.Publisher]*ScheduleState{},
+		intervals:     map[pb.Publisher]time.Duration{},
+	}
+	now := s.now()
+	for publisher, interval := range config.Intervals {
+		s.intervals[publisher] = interval
+		s.schedules[publisher] = &ScheduleState{
+			Publisher: publisher,
+			Name:      publisher.String(),
+			Interval:  interval.String(),
+			NextRun:   s.nextRun(now, interval),
+		}
+	}
+	return s
+}
+
+// Run checks the schedules until the context ends
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(schedulerTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.tick(ctx)
+		}
+	}
+}
+
+// States returns the schedules sorted by publisher
+func (s *Scheduler) States() []ScheduleState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	running := s.jobs.RunningPublishers()
+	states := make([]ScheduleState, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		state := *schedule
+		state.Running = slices.Contains(running, state.Publisher)
+		states = append(states, state)
+	}
+	slices.SortFunc(states, func(a, b ScheduleState) int {
+		return int(a.Publisher - b.Publisher)
+	})
+	return states
+}
+
+// Pause stops the scheduled runs of the publisher, a running scrape goes on
+func (s *Scheduler) Pause(publisher pb.Publisher) (ScheduleState, error) {
+	return s.change(publisher, func(schedule *ScheduleState) error {
+		schedule.Paused = true
+		return nil
+	})
+}
+
+// Resume restarts the scheduled runs of the publisher, a run missed while
+// paused happens at the next check
+func (s *Scheduler) Resume(publisher pb.Publisher) (ScheduleState, error) {
+	return s.change(publisher, func(schedule *ScheduleState) error {
+		schedule.Paused = false
+		return nil
+	})
+}
+
+// Trigger scrapes the publisher now, paused or not, and schedules the next
+// run one interval later. It fails when the publisher is being scraped.
+func (s *Scheduler) Trigger(ctx context.Context, publisher pb.Publisher) (ScheduleState, error) {
+	return s.change(publisher, func(schedule *ScheduleState) error {
+		if slices.Contains(s.jobs.RunningPublishers(), publisher) {
+			return ErrAlreadyRunning
+		}
+		return s.start(ctx, schedule)
+	})
+}
+
+func (s *Scheduler) change(publisher pb.Publisher, change func(*ScheduleState) error) (ScheduleState, error) {
+	s.mu.Lock()
+	schedule, ok := s.schedules[publisher]
+	if !ok {
+		s.mu.Unlock()
+		return ScheduleState{}, fmt.Errorf("%w: %s", ErrNoSchedule, publisher)
+	}
+	err := change(schedule)
+	s.mu.Unlock()
+	if err != nil {
+		return ScheduleState{}, err
+	}
+	for _, state := range s.States() {
+		if state.Publisher == publisher {
+			return state, nil
+		}
+	}
+	return ScheduleState{}, fmt.Errorf("%w: %s", ErrNoSchedule, publisher)
+}
+
+// tick starts the due publishers, oldest first, as long as fewer than
+// maxConcurrent publishers are being scraped. The due publishers over the
+// limit wait for a later tick.
+func (s *Scheduler) tick(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	running := s.jobs.RunningPublishers()
+
+	due := []*ScheduleState{}
+	for _, schedule := range s.schedules {
+		if !schedule.Paused && !now.Before(schedule.NextRun) {
+			due = append(due, schedule)
+		}
+	}
+	slices.SortFunc(due, func(a, b *ScheduleState) int {
+		if order := a.NextRun.Compare(b.NextRun); order != 0 {
+			return order
+		}
+		return int(a.Publisher - b.Publisher)
+	})
+
+	for _, schedule := range due {
+		if slices.Contains(running, schedule.Publisher) {
+			schedule.SkippedRuns++
+			schedule.NextRun = s.nextRun(now, s.intervals[schedule.Publisher])
+			log.Info().Str("publisher", schedule.Name).Msg("Scheduled scrape skipped, still running")
+			continue
+		}
+		if len(running) >= s.maxConcurrent {
+			continue
+		}
+		if err := s.start(ctx, schedule); err != nil {
+			log.Error().Err(err).Str("publisher", schedule.Name).Msg("Scheduled scrape failed to start")
+			continue
+		}
+		running = append(running, schedule.Publisher)
+	}
+}
+
+// start runs a job for the schedule and plans the next one, with s.mu held
+func (s *Scheduler) start(ctx context.Context, schedule *ScheduleState) error {
+	now := s.now()
+	schedule.NextRun = s.nextRun(now, s.intervals[schedule.Publisher])
+	job, err := s.jobs.Start(ctx, []pb.Publisher{schedule.Publisher}, false)
+	if err != nil {
+		schedule.LastError = err.Error()
+		return err
+	}
+	schedule.LastRun = &now
+	schedule.LastJobID = job.ID
+	schedule.LastError = ""
+	return nil
+}
+
+func (s *Scheduler) nextRun(now time.Time, interval time.Duration) time.Time {
+	next := now.Add(interval)
+	if s.jitter > 0 {
+		next = next.Add(rand.N(s.jitter)) // #nosec G404
+	}
+	return next
+}
+
+// ParseInterval reads an interval as a Go duration like "90m", "@every 90m"
+// or one of the cron shorthands @hourly, @daily and @weekly. They are
+// intervals counted from the start, so @midnight, which names a time of the
+// day, is refused.
+func ParseInterval(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "@hourly":
+		return time.Hour, nil
+	case "@daily":
+		return 24 * time.Hour, nil
+	case "@midnight":
+		return 0, fmt.Errorf("%w: %q is a time of the day, use @daily for a 24h interval", ErrInvalidSchedule, value)
+	case "@weekly":
+		return 7 * 24 * time.Hour, nil
+	}
+	interval, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(value, "@every")))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSchedule, value)
+	}
+	if interval < time.Minute {
+		return 0, fmt.Errorf("%w: %q is shorter than a minute", ErrInvalidSchedule, value)
+	}
+	return interval, nil
+}
+
+// ParsePublisher reads a publisher by its name or its number
+func ParsePublisher(value string) (pb.Publisher, error) {
+	value = strings.TrimSpace(value)
+	if number, err := strconv.Atoi(value); err == nil {
+		if _, ok := pb.Publisher_name[int32(number)]; ok && number != 0 { // #nosec G115
+			return pb.Publisher(number), nil // #nosec G115
+		}
+	} else if number, ok := pb.Publisher_value[strings.ToUpper(value)]; ok && number != 0 {
+		return pb.Publisher(number), nil
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownPublisher, value)
+}
+
+// ParseIntervals reads the intervals of the publishers in a comma separated
+// list like "ASURA=30m,4=@hourly,FLAME_SCANS=off". Every publisher with a
+// scraper gets the default interval unless it is empty, off leaves a
+// publisher out.
+func ParseIntervals(defaultInterval string, spec string) (map[pb.Publisher]time.Duration, error) {
+	intervals := map[pb.Publisher]time.Duration{}
+	if strings.TrimSpace(defaultInterval) != "" {
+		interval, err := ParseInterval(defaultInterval)
+		if err != nil {
+			return nil, err
+		}
+		for _, publisher := range Publishers() {
+			intervals[publisher] = interval
+		}
+	}
+	for _, entry := range strings.Split(spec, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q should be publisher=interval", ErrInvalidSchedule, entry)
+		}
+		publisher, err := ParsePublisher(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := Lookup(publisher); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPublisher, publisher)
+		}
+		if strings.TrimSpace(value) == "off" {
+			delete(intervals, publisher)
+			continue
+		}
+		if intervals[publisher], err = ParseInterval(value); err != nil {
+			return nil, err
+		}
+	}
+	return intervals, nil
+}
@@ -0,0 +1,208 @@
+package scrape
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"comics/internal/service"
+	"comics/pkg/pb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJobs records the started publishers, they keep running until finished
+type fakeJobs struct {
+	mu      sync.Mutex
+	started []pb.Publisher
+	running []pb.Publisher
+	err     error
+}
+
+func (f *fakeJobs) Start(_ context.Context, publishers []pb.Publisher, _ bool) (service.ScrapeJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return service.ScrapeJob{}, f.err
+	}
+	f.started = append(f.started, publishers...)
+	f.running = append(f.running, publishers...)
+	return service.ScrapeJob{ID: len(f.started)}, nil
+}
+
+func (f *fakeJobs) RunningPublishers() []pb.Publisher {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.running)
+}
+
+func (f *fakeJobs) finish(publisher pb.Publisher) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.running = slices.DeleteFunc(f.running, func(p pb.Publisher) bool { return p == publisher })
+}
+
+func newTestScheduler(jobs *fakeJobs, maxConcurrent int) (*Scheduler, *time.Time) {
+	scheduler := NewScheduler(jobs, SchedulerConfig{
+		Intervals: map[pb.Publisher]time.Duration{
+			pb.Publisher_ASURA:       time.Hour,
+			pb.Publisher_FLAME_SCANS: time.Hour,
+			pb.Publisher_REALM_SCANS: 2 * time.Hour,
+		},
+		MaxConcurrent: maxConcurrent,
+	})
+	now := time.Now()
+	scheduler.now = func() time.Time { return now }
+	for publisher, schedule := range scheduler.schedules {
+		schedule.NextRun = now.Add(scheduler.intervals[publisher])
+	}
+	return scheduler, &now
+}
+
+func TestSchedulerTick(t *testing.T) {
+	jobs := &fakeJobs{}
+	scheduler, now := newTestScheduler(jobs, 1)
+	ctx := context.Background()
+
+	scheduler.tick(ctx)
+	assert.Empty(t, jobs.started)
+
+	// Only one publisher at a time, the other due one waits
+	*now = now.Add(time.Hour)
+	scheduler.tick(ctx)
+	assert.Equal(t, []pb.Publisher{pb.Publisher_ASURA}, jobs.started)
+
+	jobs.finish(pb.Publisher_ASURA)
+	scheduler.tick(ctx)
+	assert.Equal(t, []pb.Publisher{pb.Publisher_ASURA, pb.Publisher_FLAME_SCANS}, jobs.started)
+
+	// ASURA starts again next to the running FLAME_SCANS, whose run is
+	// skipped, and REALM_SCANS waits for a free slot
+	scheduler.maxConcurrent = 2
+	*now = now.Add(time.Hour)
+	scheduler.tick(ctx)
+	assert.Equal(t, []pb.Publisher{pb.Publisher_ASURA, pb.Publisher_FLAME_SCANS, pb.Publisher_ASURA}, jobs.started)
+
+	// The oldest due publisher goes first, the running ones are skipped
+	scheduler.maxConcurrent = 3
+	*now = now.Add(time.Hour)
+	scheduler.tick(ctx)
+	assert.Equal(t, []pb.Publisher{
+		pb.Publisher_ASURA, pb.Publisher_FLAME_SCANS, pb.Publisher_ASURA, pb.Publisher_REALM_SCANS,
+	}, jobs.started)
+
+	states := scheduler.States()
+	require.Len(t, states, 3)
+	assert.Equal(t, pb.Publisher_ASURA, states[0].Publisher)
+	assert.Equal(t, 1, states[0].SkippedRuns)
+	assert.True(t, states[0].Running)
+	assert.Equal(t, pb.Publisher_FLAME_SCANS, states[1].Publisher)
+	assert.Equal(t, 2, states[1].SkippedRuns)
+	assert.Equal(t, now.Add(time.Hour), states[1].NextRun)
+	assert.Equal(t, pb.Publisher_REALM_SCANS, states[2].Publisher)
+	assert.Equal(t, 4, states[2].LastJobID)
+	assert.Equal(t, *now, *states[2].LastRun)
+	assert.Equal(t, "2h0m0s", states[2].Interval)
+}
+
+func TestSchedulerPauseTrigger(t *testing.T) {
+	jobs := &fakeJobs{}
+	scheduler, now := newTestScheduler(jobs, 3)
+	ctx := context.Background()
+
+	state, err := scheduler.Pause(pb.Publisher_ASURA)
+	require.NoError(t, err)
+	assert.True(t, state.Paused)
+	_, err = scheduler.Pause(pb.Publisher_NOVEL_MIC)
+	assert.ErrorIs(t, err, ErrNoSchedule)
+
+	*now = now.Add(time.Hour)
+	scheduler.tick(ctx)
+	assert.Equal(t, []pb.Publisher{pb.Publisher_FLAME_SCANS}, jobs.started)
+
+	// A paused publisher can still be triggered, the next run moves on
+	state, err = scheduler.Trigger(ctx, pb.Publisher_ASURA)
+	require.NoError(t, err)
+	assert.True(t, state.Paused)
+	assert.True(t, state.Running)
+	assert.Equal(t, now.Add(time.Hour), state.NextRun)
+	_, err = scheduler.Trigger(ctx, pb.Publisher_ASURA)
+	assert.ErrorIs(t, err, ErrAlreadyRunning)
+
+	jobs.finish(pb.Publisher_ASURA)
+	state, err = scheduler.Resume(pb.Publisher_ASURA)
+	require.NoError(t, err)
+	assert.False(t, state.Paused)
+	assert.False(t, state.Running)
+
+	jobs.err = errors.New("database is locked")
+	state, err = scheduler.Trigger(ctx, pb.Publisher_REALM_SCANS)
+	require.Error(t, err)
+	assert.Empty(t, state.Name)
+	assert.Equal(t, "database is locked", scheduler.States()[2].LastError)
+}
+
+func TestParseInterval(t *testing.T) {
+	tests := map[string]time.Duration{
+		"90m":          90 * time.Minute,
+		" @every 2h ":  2 * time.Hour,
+		"@hourly":      time.Hour,
+		"@daily":       24 * time.Hour,
+		"@weekly":      7 * 24 * time.Hour,
+		"1h30m":        90 * time.Minute,
+		"@every 1m0s":  time.Minute,
+		"@every 1440m": 24 * time.Hour,
+	}
+	for value, expected := range tests {
+		interval, err := ParseInterval(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, interval, value)
+	}
+	for _, value := range []string{"", "30s", "@yearly", "@midnight", "*/5 * * * *", "-1h"} {
+		_, err := ParseInterval(value)
+		assert.ErrorIs(t, err, ErrInvalidSchedule, value)
+	}
+}
+
+func TestParseIntervals(t *testing.T) {
+	publisher, err := ParsePublisher("flame_scans")
+	require.NoError(t, err)
+	assert.Equal(t, pb.Publisher_FLAME_SCANS, publisher)
+	publisher, err = ParsePublisher("8")
+	require.NoError(t, err)
+	assert.Equal(t, pb.Publisher_REALM_SCANS, publisher)
+	for _, value := range []string{"0", "99", "UNKNOWN", ""} {
+		_, err = ParsePublisher(value)
+		assert.ErrorIs(t, err, ErrUnknownPublisher, value)
+	}
+
+	intervals, err := ParseIntervals("", "ASURA=30m, 4=@hourly")
+	require.NoError(t, err)
+	assert.Equal(t, map[pb.Publisher]time.Duration{
+		pb.Publisher_ASURA:       30 * time.Minute,
+		pb.Publisher_FLAME_SCANS: time.Hour,
+	}, intervals)
+
+	intervals, err = ParseIntervals("@daily", "MANHUA_PLUS=off,REALM_SCANS=6h")
+	require.NoError(t, err)
+	assert.Equal(t, map[pb.Publisher]time.Duration{
+		pb.Publisher_ASURA:       24 * time.Hour,
+		pb.Publisher_FLAME_SCANS: 24 * time.Hour,
+		pb.Publisher_REALM_SCANS: 6 * time.Hour,
+	}, intervals)
+
+	intervals, err = ParseIntervals("", "")
+	require.NoError(t, err)
+	assert.Empty(t, intervals)
+
+	_, err = ParseIntervals("", "ASURA")
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+	_, err = ParseIntervals("", "NOVEL_MIC=1h")
+	assert.ErrorIs(t, err, ErrUnknownPublisher)
+	_, err = ParseIntervals("10s", "")
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+}
@@ -0,0 +1,303 @@
+// Package scrape reads the latest updates of the publisher sites, it is the
+// Go port of src/scrape. Each publisher has a Scraper that parses its pages
+// into comics ready for SQLiteComicService.Import.
+package scrape
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"comics/internal/identity"
+	"comics/internal/service"
+	"comics/pkg/pb"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	requestTimeout = 10 * time.Second
+	// minimumCoverURLLength rejects covers too short to be an URL
+	minimumCoverURLLength = 10
+	userAgent             = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0 Safari/537.36"
+)
+
+var (
+	ErrUnknownPublisher = errors.New("no scraper for the publisher")
+	ErrNoComics         = errors.New("no comics found on the page")
+
+	chapterNumber = regexp.MustCompile(`\d+`)
+)
+
+// Scraper parses the update pages of a publisher.
+type Scraper interface {
+	Publisher() pb.Publisher
+	// Parse returns the comics of the page, the comics that can't be read
+	// are left out and reported in the error.
+	Parse(doc *html.Node) ([]Comic, error)
+}
+
+// parseItems reads every element that matches with extract, which returns
+// false for the elements that aren't an update like the recommended comics
+func parseItems(
+	doc *html.Node,
+	match func(*html.Node) bool,
+	extract func(*html.Node) (Comic, bool, error),
+) ([]Comic, error) {
+	items := findAll(doc, match)
+	if len(items) == 0 {
+		return nil, ErrNoComics
+	}
+	comics := []Comic{}
+	errs := []error{}
+	for _, item := range items {
+		comic, ok, err := extract(item)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			comics = append(comics, comic)
+		}
+	}
+	return comics, errors.Join(errs...)
+}
+
+// Comic is a comic as shown on a page, before normalization
+type Comic struct {
+	Title   string
+	Chapter string
+	Cover   string
+	ComType string
+	Status  string
+	Author  string
+}
+
+// Result holds the comics read from a page, ready for
+// SQLiteComicService.Import. Errors holds the failures of the page and of
+// the comics left out.
+type Result struct {
+	Publisher pb.Publisher        `json:"publisher"`
+	URL       string              `json:"url"`
+	Comics    []service.ComicJSON `json:"comics"`
+	Errors    []string            `json:"errors,omitempty"`
+}
+
+var scrapers = map[pb.Publisher]Scraper{
+	pb.Publisher_ASURA:       asura{},
+	pb.Publisher_FLAME_SCANS: flame{},
+	pb.Publisher_REALM_SCANS: realm{},
+	pb.Publisher_MANHUA_PLUS: manhuaPlus{},
+}
+
+// DefaultURLs are the update pages of each publisher, like
+// src/scrape/url_switch.json
+var DefaultURLs = map[pb.Publisher][]string{
+	pb.Publisher_ASURA:       {"https://asuracomic.net/", "https://asuracomic.net/page/2"},
+	pb.Publisher_FLAME_SCANS: {"https://flamecomics.xyz/"},
+	pb.Publisher_REALM_SCANS: {"https://rizzfables.com/"},
+	pb.Publisher_MANHUA_PLUS: {"https://manhuaplus.com/"},
+}
+
+// Lookup returns the scraper of a publisher
+func Lookup(publisher pb.Publisher) (Scraper, bool) {
+	scraper, ok := scrapers[publisher]
+	return scraper, ok
+}
+
+// Publishers returns the publishers with a scraper, sorted
+func Publishers() []pb.Publisher {
+	publishers := make([]pb.Publisher, 0, len(scrapers))
+	for publisher := range scrapers {
+		publishers = append(publishers, publisher)
+	}
+	slices.Sort(publishers)
+	return publishers
+}
+
+// Client fetches and parses the publisher pages
+type Client struct {
+	HTTP *http.Client
+}
+
+func NewClient() *Client {
+	return &Client{HTTP: &http.Client{Timeout: requestTimeout}}
+}
+
+// Scrape fetches a page of the publisher and parses it. Only an unknown
+// publisher or a page that can't be fetched return an error, the comics that
+// can't be read are reported in the result.
+func (c *Client) Scrape(ctx context.Context, publisher pb.Publisher, pageURL string) (Result, error) {
+	result := Result{Publisher: publisher, URL: pageURL, Comics: []service.ComicJSON{}}
+	scraper, ok := Lookup(publisher)
+	if !ok {
+		return result, fmt.Errorf("%w: %s", ErrUnknownPublisher, publisher)
+	}
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return result, err
+	}
+	doc, err := c.fetch(ctx, page)
+	if err != nil {
+		return result, err
+	}
+
+	comics, err := scraper.Parse(doc)
+	if err != nil {
+		result.Errors = append(result.Errors, splitErrors(err)...)
+	}
+	for _, comic := range comics {
+		normalized, err := comic.normalize(publisher, page)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		result.Comics = append(result.Comics, normalized)
+	}
+	return result, nil
+}
+
+// ScrapeAll scrapes the pages of every publisher, the publishers run
+// concurrently and their pages one after the other. The results follow the
+// order of Publishers and of the pages, a page that can't be fetched is a
+// result with its error.
+func (c *Client) ScrapeAll(ctx context.Context, urls map[pb.Publisher][]string) []Result {
+	publishers := make([]pb.Publisher, 0, len(urls))
+	for publisher := range urls {
+		publishers = append(publishers, publisher)
+	}
+	slices.Sort(publishers)
+
+	results := make([][]Result, len(publishers))
+	var wg sync.WaitGroup
+	for i, publisher := range publishers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, pageURL := range urls[publisher] {
+				result, err := c.Scrape(ctx, publisher, pageURL)
+				if err != nil {
+					result.Errors = append(result.Errors, err.Error())
+				}
+				results[i] = append(results[i], result)
+			}
+		}()
+	}
+	wg.Wait()
+	return slices.Concat(results...)
+}
+
+// Comics returns the comics of the results, in order
+func Comics(results []Result) []service.ComicJSON {
+	comics := []service.ComicJSON{}
+	for _, result := range results {
+		comics = append(comics, result.Comics...)
+	}
+	return comics
+}
+
+func (c *Client) fetch(ctx context.Context, page *url.URL) (*html.Node, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, page.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() // nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", page, resp.Status)
+	}
+	return html.Parse(resp.Body)
+}
+
+func splitErrors(err error) []string {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		messages := []string{}
+		for _, err := range joined.Unwrap() {
+			messages = append(messages, err.Error())
+		}
+		return messages
+	}
+	return []string{err.Error()}
+}
+
+// normalize converts the comic like _normalize_comic_data in
+// src/scrape/scrapper.py
+func (c Comic) normalize(publisher pb.Publisher, page *url.URL) (service.ComicJSON, error) {
+	title := identity.NormalizeText(c.Title)
+	if title == "" {
+		return service.ComicJSON{}, errors.New("comic without title")
+	}
+	chapter, err := strconv.Atoi(chapterNumber.FindString(c.Chapter))
+	if err != nil {
+		return service.ComicJSON{}, fmt.Errorf("%s: invalid chapter %q", title, c.Chapter)
+	}
+	cover := parseCover(c.Cover, page)
+	return service.ComicJSON{
+		Titles:       []string{title},
+		Author:       identity.NormalizeText(c.Author),
+		ComType:      int(parseType(c.ComType)),
+		Status:       int(parseStatus(c.Status)),
+		Cover:        cover,
+		CoverVisible: true,
+		CurrentChap:  chapter,
+		PublishedIn:  []int{int(publisher)},
+		Genres:       []int{},
+	}, nil
+}
+
+func parseCover(cover string, page *url.URL) string {
+	cover = strings.TrimSpace(cover)
+	if i := strings.Index(cover, "http"); i >= 0 {
+		return cover[i:]
+	}
+	if strings.HasPrefix(cover, "/") {
+		if resolved, err := page.Parse(cover); err == nil {
+			return resolved.String()
+		}
+	}
+	if len(cover) < minimumCoverURLLength {
+		return ""
+	}
+	return cover
+}
+
+func parseStatus(status string) pb.Status {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "completed":
+		return pb.Status_COMPLETED
+	case "ongoing":
+		return pb.Status_ON_AIR
+	case "hiatus", "season end":
+		return pb.Status_BREAK
+	case "dropped":
+		return pb.Status_DROPPED
+	}
+	return pb.Status_STATUS_UNKNOWN
+}
+
+func parseType(comType string) pb.ComicType {
+	comType = strings.ToLower(strings.TrimSpace(strings.Replace(comType, "NEW ", "", 1)))
+	switch comType {
+	case "manga":
+		return pb.ComicType_MANGA
+	case "manhua":
+		return pb.ComicType_MANHUA
+	case "manhwa", "webtoon":
+		return pb.ComicType_MANHWA
+	case "novel":
+		return pb.ComicType_NOVEL
+	}
+	return pb.ComicType_TYPE_UNKNOWN
+}
@@ -0,0 +1,160 @@
+package scrape
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"comics/internal/service"
+	"comics/pkg/pb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFixtureServer serves the pages recorded in testdata
+func newFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.FileServer(http.Dir("testdata")))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func scraped(title string, chapter int, cover string, comType pb.ComicType, status pb.Status, publisher pb.Publisher) service.ComicJSON {
+	return service.ComicJSON{
+		Titles:       []string{title},
+		ComType:      int(comType),
+		Status:       int(status),
+		Cover:        cover,
+		CoverVisible: true,
+		CurrentChap:  chapter,
+		PublishedIn:  []int{int(publisher)},
+		Genres:       []int{},
+	}
+}
+
+func TestScrapers(t *testing.T) {
+	server := newFixtureServer(t)
+	tests := []struct {
+		publisher pb.Publisher
+		page      string
+		comics    []service.ComicJSON
+		errors    []string
+	}{
+		{
+			publisher: pb.Publisher_ASURA,
+			page:      "/asura.html",
+			comics: []service.ComicJSON{
+				scraped("Solo Leveling: Ragnarok", 41, "https://gg.asuracomic.net/storage/media/101/solo.webp",
+					pb.ComicType_MANHWA, pb.Status_ON_AIR, pb.Publisher_ASURA),
+				scraped("Nano Machine", 230, server.URL+"/storage/media/55/nano.webp",
+					pb.ComicType_MANHWA, pb.Status_ON_AIR, pb.Publisher_ASURA),
+			},
+		},
+		{
+			publisher: pb.Publisher_FLAME_SCANS,
+			page:      "/flame.html",
+			comics: []service.ComicJSON{
+				scraped("The Greatest Estate Developer", 165, "https://flamecomics.xyz/wp-content/uploads/2024/01/estate.jpg",
+					pb.ComicType_MANHWA, pb.Status_ON_AIR, pb.Publisher_FLAME_SCANS),
+				scraped("Omniscient Reader's Viewpoint", 210, "https://flamecomics.xyz/wp-content/uploads/2023/05/orv.jpg",
+					pb.ComicType_MANHWA, pb.Status_ON_AIR, pb.Publisher_FLAME_SCANS),
+			},
+		},
+		{
+			publisher: pb.Publisher_REALM_SCANS,
+			page:      "/realm.html",
+			comics: []service.ComicJSON{
+				scraped("The Tutorial is Too Hard", 198, "https://rizzfables.com/assets/images/tutorial.webp",
+					pb.ComicType_MANHWA, pb.Status_ON_AIR, pb.Publisher_REALM_SCANS),
+				scraped("Return of the Mount Hua Sect", 120, "https://rizzfables.com/assets/images/mount-hua.webp",
+					pb.ComicType_MANHUA, pb.Status_COMPLETED, pb.Publisher_REALM_SCANS),
+			},
+			errors: []string{`Broken Entry: invalid chapter "Prologue"`},
+		},
+		{
+			publisher: pb.Publisher_MANHUA_PLUS,
+			page:      "/manhuaplus.html",
+			comics: []service.ComicJSON{
+				scraped("Martial Peak", 3801, "https://manhuaplus.com/wp-content/uploads/2020/martial-peak-193x278.jpg",
+					pb.ComicType_MANHUA, pb.Status_ON_AIR, pb.Publisher_MANHUA_PLUS),
+				scraped("Tales of Demons and Gods", 480, "https://manhuaplus.com/wp-content/uploads/2020/tdg-193x278.jpg",
+					pb.ComicType_MANHUA, pb.Status_ON_AIR, pb.Publisher_MANHUA_PLUS),
+			},
+			errors: []string{"manhuaplus: comic without title or cover"},
+		},
+	}
+
+	client := NewClient()
+	for _, tt := range tests {
+		t.Run(tt.publisher.String(), func(t *testing.T) {
+			result, err := client.Scrape(context.Background(), tt.publisher, server.URL+tt.page)
+			require.NoError(t, err)
+			assert.Equal(t, tt.publisher, result.Publisher)
+			assert.Equal(t, tt.comics, result.Comics)
+			assert.Equal(t, tt.errors, result.Errors)
+		})
+	}
+}
+
+func TestScrapeErrors(t *testing.T) {
+	server := newFixtureServer(t)
+	client := NewClient()
+	ctx := context.Background()
+
+	_, err := client.Scrape(ctx, pb.Publisher_NOVEL_MIC, server.URL+"/asura.html")
+	assert.ErrorIs(t, err, ErrUnknownPublisher)
+
+	result, err := client.Scrape(ctx, pb.Publisher_FLAME_SCANS, server.URL+"/asura.html")
+	require.NoError(t, err)
+	assert.Empty(t, result.Comics)
+	assert.Equal(t, []string{ErrNoComics.Error()}, result.Errors)
+
+	results := client.ScrapeAll(ctx, map[pb.Publisher][]string{
+		pb.Publisher_MANHUA_PLUS: {server.URL + "/missing.html"},
+		pb.Publisher_ASURA:       {server.URL + "/asura.html", server.URL + "/asura.html"},
+	})
+	require.Len(t, results, 3)
+	assert.Equal(t, pb.Publisher_ASURA, results[0].Publisher)
+	assert.Equal(t, pb.Publisher_ASURA, results[1].Publisher)
+	assert.Equal(t, pb.Publisher_MANHUA_PLUS, results[2].Publisher)
+	assert.Contains(t, results[2].Errors[0], "404 Not Found")
+	assert.Len(t, Comics(results), 4)
+}
+
+func TestPublishers(t *testing.T) {
+	assert.Equal(t, []pb.Publisher{
+		pb.Publisher_ASURA, pb.Publisher_MANHUA_PLUS, pb.Publisher_FLAME_SCANS, pb.Publisher_REALM_SCANS,
+	}, Publishers())
+	for _, publisher := range Publishers() {
+		scraper, ok := Lookup(publisher)
+		require.True(t, ok)
+		assert.Equal(t, publisher, scraper.Publisher())
+		assert.NotEmpty(t, DefaultURLs[publisher])
+	}
+}
+
+func TestTargets(t *testing.T) {
+	sites := DefaultSites()
+	require.Len(t, sites, len(Publishers()))
+	assert.Equal(t, "https://asuracomic.net", sites[0].BaseURL)
+	assert.Equal(t, DefaultURLs, Targets(sites))
+
+	sites = []service.PublisherSite{
+		{Publisher: int(pb.Publisher_ASURA), BaseURL: "https://asura.gg", Targets: []string{"/", "/page/2"}, Alive: true},
+		{Publisher: int(pb.Publisher_FLAME_SCANS), BaseURL: "https://flame.gg", Targets: []string{"/"}},
+		{Publisher: int(pb.Publisher_NOVEL_MIC), BaseURL: "https://novelmic.com", Targets: []string{"/"}, Alive: true},
+	}
+	urls := Targets(sites)
+	assert.Equal(t, map[pb.Publisher][]string{
+		pb.Publisher_ASURA: {"https://asura.gg/", "https://asura.gg/page/2"},
+	}, urls)
+
+	jobs, err := NewJobs(context.Background(), NewClient(), newMemoryJobStore(), DefaultURLs)
+	require.NoError(t, err)
+	jobs.SetURLs(urls)
+	_, err = jobs.Start(context.Background(), []pb.Publisher{pb.Publisher_FLAME_SCANS}, true)
+	assert.ErrorIs(t, err, ErrUnknownPublisher)
+	assert.Equal(t, urls, jobs.URLs())
+}
@@ -0,0 +1,44 @@
+package scrape
+
+import (
+	"net/url"
+
+	"comics/internal/service"
+	"comics/pkg/pb"
+)
+
+// DefaultSites are the sites of DefaultURLs, stored once so the admins can
+// move the publishers afterwards
+func DefaultSites() []service.PublisherSite {
+	sites := []service.PublisherSite{}
+	for _, publisher := range Publishers() {
+		pages := DefaultURLs[publisher]
+		if len(pages) == 0 {
+			continue
+		}
+		first, err := url.Parse(pages[0])
+		if err != nil {
+			continue
+		}
+		sites = append(sites, service.PublisherSite{
+			Publisher: int(publisher),
+			BaseURL:   first.Scheme + "://" + first.Host,
+			Targets:   pages,
+			Alive:     true,
+		})
+	}
+	return sites
+}
+
+// Targets returns the pages to scrape of the alive sites with a scraper
+func Targets(sites []service.PublisherSite) map[pb.Publisher][]string {
+	urls := map[pb.Publisher][]string{}
+	for _, site := range sites {
+		publisher := pb.Publisher(site.Publisher) // #nosec G115
+		if _, ok := Lookup(publisher); !ok || !site.Alive {
+			continue
+		}
+		urls[publisher] = site.TargetURLs()
+	}
+	return urls
+}
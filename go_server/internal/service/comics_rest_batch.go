@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"comics/internal/jsonpatch"
+)
+
+const MaxBatchOperations = 500
+
+var (
+	ErrBatchTooLarge      = fmt.Errorf("a batch accepts at most %d operations", MaxBatchOperations)
+	ErrUnknownBatchOp     = errors.New("unknown batch operation")
+	ErrBatchAborted       = errors.New("not applied, another operation of the batch failed")
+	ErrBatchMissingComic  = errors.New("comic object is required")
+	ErrBatchMissingTarget = errors.New("id is required")
+)
+
+// BatchOperation is a single create, update, delete or mark-read step.
+// Comic holds the new comic for create and a merge patch for update.
+type BatchOperation struct {
+	Op      string         `json:"op"`
+	ID      int            `json:"id,omitempty"`
+	Comic   map[string]any `json:"comic,omitempty"`
+	IfMatch string         `json:"if_match,omitempty"`
+}
+
+type BatchResult struct {
+	Index int        `json:"index"`
+	Op    string     `json:"op"`
+	ID    int        `json:"id,omitempty"`
+	Comic *ComicJSON `json:"comic,omitempty"`
+	Err   error      `json:"-"`
+}
+
+// Batch runs the operations in a single transaction. In atomic mode the
+// first failure rolls everything back, otherwise each operation runs in its
+// own savepoint and only the failed ones are undone. Per item errors are
+// reported in the results, the returned error is only set for failures of
+// the transaction itself.
+func (s *SQLiteComicService) Batch(
+	ctx context.Context,
+	operations []BatchOperation,
+	atomic bool,
+) ([]BatchResult, error) {
+	if len(operations) > MaxBatchOperations {
+		return nil, ErrBatchTooLarge
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	results := make([]BatchResult, len(operations))
+	failed := false
+	for i, operation := range operations {
+		results[i] = BatchResult{Index: i, Op: operation.Op, ID: operation.ID}
+		if failed {
+			results[i].Err = ErrBatchAborted
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_item"); err != nil {
+			return nil, err
+		}
+		id, err := runBatchOperation(ctx, tx, operation)
+		if err != nil {
+			if _, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO batch_item"); rollbackErr != nil {
+				return nil, rollbackErr
+			}
+			results[i].Err = err
+			failed = atomic
+		}
+		if _, err := tx.ExecContext(ctx, "RELEASE batch_item"); err != nil {
+			return nil, err
+		}
+		if results[i].Err == nil && operation.Op != "delete" {
+			comic, err := scanComic(tx.QueryRowContext(ctx, baseComicSelect()+" WHERE id = ?", id))
+			if err != nil {
+				return nil, err
+			}
+			results[i].ID = id
+			results[i].Comic = &comic
+		}
+	}
+
+	if failed {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i].Err = ErrBatchAborted
+				results[i].Comic = nil
+			}
+		}
+		return results, nil
+	}
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func runBatchOperation(ctx context.Context, tx *sql.Tx, operation BatchOperation) (int, error) {
+	switch operation.Op {
+	case "update", "delete", "mark-read":
+		if operation.ID == 0 {
+			return 0, ErrBatchMissingTarget
+		}
+	}
+
+	switch operation.Op {
+	case "create":
+		if operation.Comic == nil {
+			return 0, ErrBatchMissingComic
+		}
+		comic, err := newComicFromDocument(operation.Comic)
+		if err != nil {
+			return 0, err
+		}
+		return createComic(ctx, tx, comic)
+	case "update":
+		if operation.Comic == nil {
+			return 0, ErrBatchMissingComic
+		}
+		patch := ComicPatch{Merge: operation.Comic}
+		options := UpdateOptions{Source: -1, IfMatch: operation.IfMatch}
+		return operation.ID, updateComicTx(ctx, tx, operation.ID, options, patch.apply)
+	case "delete":
+		return operation.ID, deleteComic(ctx, tx, operation.ID)
+	case "mark-read":
+		options := UpdateOptions{Source: -1, IfMatch: operation.IfMatch}
+		return operation.ID, updateComicTx(ctx, tx, operation.ID, options, func(current ComicJSON) (ComicJSON, error) {
+			current.ViewedChap = current.CurrentChap
+			return current, nil
+		})
+	default:
+		return 0, fmt.Errorf("%w %q", ErrUnknownBatchOp, operation.Op)
+	}
+}
+
+// newComicFromDocument validates a comic object the same way patched
+// documents are validated, cover_visible defaults to true.
+func newComicFromDocument(fields map[string]any) (ComicJSON, error) {
+	for _, key := range []string{"id", "last_update", "deleted"} {
+		if fields[key] != nil {
+			return ComicJSON{}, &jsonpatch.Error{Path: "/" + key, Message: "field is read-only"}
+		}
+	}
+	doc := jsonpatch.MergePatch(map[string]any{"cover_visible": true}, fields)
+	return decodeComicDocument(ComicJSON{}, doc)
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New(
+		"sort must be one of last_update, title, rating, unread or relevance and order asc or desc",
+	)
+)
+
+// Fields the comic lists can be sorted by
+const (
+	SortLastUpdate = "last_update"
+	SortTitle      = "title"
+	SortRating     = "rating"
+	SortUnread     = "unread"
+	SortRelevance  = "relevance"
+)
+
+// ComicSort is the order of List and Search results, the zero value sorts by
+// last update, newest first. Ties are always broken by ascending id.
+type ComicSort struct {
+	Field     string
+	Ascending bool
+}
+
+// ParseComicSort reads the sort and order query parameters. An empty order
+// picks the natural direction of the field: titles A to Z, everything else
+// highest first.
+func ParseComicSort(field string, direction string) (ComicSort, error) {
+	order := ComicSort{Field: field}
+	switch field {
+	case "", SortLastUpdate, SortRating, SortUnread, SortRelevance:
+	case SortTitle:
+		order.Ascending = true
+	default:
+		return ComicSort{}, ErrInvalidSort
+	}
+	switch strings.ToLower(direction) {
+	case "":
+	case "asc":
+		order.Ascending = true
+	case "desc":
+		order.Ascending = false
+	default:
+		return ComicSort{}, ErrInvalidSort
+	}
+	return order, nil
+}
+
+// field returns the sort field with the default spelled out.
+func (s ComicSort) field() string {
+	if s.Field == "" {
+		return SortLastUpdate
+	}
+	return s.Field
+}
+
+func (s ComicSort) expression() string {
+	switch s.field() {
+	case SortTitle:
+		return "titles COLLATE NOCASE"
+	case SortRating:
+		return "rating"
+	case SortUnread:
+		return "(current_chap - viewed_chap)"
+	case SortRelevance:
+		return "fts_rank"
+	default:
+		return "last_update"
+	}
+}
+
+func (s ComicSort) orderBy() string {
+	if s.Ascending {
+		return s.expression() + " ASC, id"
+	}
+	return s.expression() + " DESC, id"
+}
+
+// value returns the sort key of a comic as used in cursors.
+func (s ComicSort) value(comic ComicJSON) any {
+	switch s.Field {
+	case SortTitle:
+		return strings.Join(comic.Titles, "|")
+	case SortRating:
+		return int64(comic.Rating)
+	case SortUnread:
+		return int64(comic.CurrentChap - comic.ViewedChap)
+	case SortRelevance:
+		return comic.Rank
+	default:
+		return parseLastUpdate(comic.LastUpdate)
+	}
+}
+
+// comicCursor is the position after the last comic of a page.
+type comicCursor struct {
+	Sort  ComicSort
+	Value any
+	ID    int
+}
+
+type cursorToken struct {
+	Field     string `json:"f"`
+	Ascending bool   `json:"a,omitempty"`
+	Value     string `json:"v"`
+	ID        int    `json:"i"`
+}
+
+func encodeCursor(comic ComicJSON, order ComicSort) string {
+	token := cursorToken{Field: order.field(), Ascending: order.Ascending, ID: comic.ID}
+	switch value := order.value(comic).(type) {
+	case string:
+		token.Value = value
+	case int64:
+		token.Value = strconv.FormatInt(value, 10)
+	case float64:
+		token.Value = strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	raw, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor parses a cursor, it must have been issued for the same sort.
+func decodeCursor(encoded string, order ComicSort) (comicCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return comicCursor{}, ErrInvalidCursor
+	}
+	var token cursorToken
+	if err := json.Unmarshal(raw, &token); err != nil {
+		return comicCursor{}, ErrInvalidCursor
+	}
+	if token.Field != order.field() || token.Ascending != order.Ascending {
+		return comicCursor{}, ErrInvalidCursor
+	}
+
+	cursor := comicCursor{Sort: order, Value: token.Value, ID: token.ID}
+	switch order.field() {
+	case SortTitle:
+	case SortRelevance:
+		value, err := strconv.ParseFloat(token.Value, 64)
+		if err != nil {
+			return comicCursor{}, ErrInvalidCursor
+		}
+		cursor.Value = value
+	default:
+		value, err := strconv.ParseInt(token.Value, 10, 64)
+		if err != nil {
+			return comicCursor{}, ErrInvalidCursor
+		}
+		cursor.Value = value
+	}
+	return cursor, nil
+}
+
+// after restricts a query to the comics that follow the cursor.
+func (c comicCursor) after() (string, []any) {
+	operator := "<"
+	if c.Sort.Ascending {
+		operator = ">"
+	}
+	expression := c.Sort.expression()
+	return "(" + expression + " " + operator + " ? OR (" + expression + " = ? AND id > ?))",
+		[]any{c.Value, c.Value, c.ID}
+}
+
+// before selects the comics up to and including the cursor.
+func (c comicCursor) before() (string, []any) {
+	operator := ">"
+	if c.Sort.Ascending {
+		operator = "<"
+	}
+	expression := c.Sort.expression()
+	return "(" + expression + " " + operator + " ? OR (" + expression + " = ? AND id <= ?))",
+		[]any{c.Value, c.Value, c.ID}
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"comics/internal/identity"
+)
+
+// Reasons reported for a duplicate candidate
+const (
+	DuplicateSameTitle       = "same_title"
+	DuplicateSimilarTitle    = "similar_title"
+	DuplicateSharedTitle     = "shared_alternate_title"
+	DuplicateTruncatedTitle  = "truncated_title"
+	DuplicateSameAuthor      = "same_author"
+	DuplicateDifferentAuthor = "different_author"
+	DuplicateSameType        = "same_type"
+)
+
+// minPrefixKeyLength keeps short titles from being seen as the truncated
+// form of every title starting with the same letters
+const minPrefixKeyLength = 6
+
+// DuplicateGroup is a set of comics that are likely the same series, ready to
+// be merged into Suggestion.BaseID.
+type DuplicateGroup struct {
+	Confidence float64         `json:"confidence"`
+	Reasons    []string        `json:"reasons"`
+	Comics     []ComicJSON     `json:"comics"`
+	Suggestion MergeSuggestion `json:"suggestion"`
+}
+
+// MergeSuggestion lists the merges that collapse a group, Requests holds
+// them as calls to the merge endpoint.
+type MergeSuggestion struct {
+	BaseID     int      `json:"base_id"`
+	MergingIDs []int    `json:"merging_ids"`
+	Requests   []string `json:"requests"`
+}
+
+type duplicatePair struct {
+	a, b       int
+	confidence float64
+	reasons    []string
+}
+
+// Duplicates groups the comics outside the trash that look like the same
+// series. Two comics are candidates when their titles match once normalized,
+// share an alternate title or one is the truncated form of the other. The
+// author and type adjust the confidence, and comics Merge would refuse to
+// combine are never grouped. Groups below minConfidence are left out.
+func (s *SQLiteComicService) Duplicates(ctx context.Context, minConfidence float64) ([]DuplicateGroup, error) {
+	rows, err := s.db.QueryContext(ctx, baseComicSelect()+" WHERE deleted = 0 ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	comics, err := scanComics(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := []duplicatePair{}
+	for _, candidate := range duplicateCandidates(comics) {
+		pair := scoreDuplicate(comics[candidate[0]], comics[candidate[1]])
+		pair.a, pair.b = candidate[0], candidate[1]
+		// A rejected pair has no reasons, even a threshold of 0 leaves it out
+		if len(pair.reasons) > 0 && pair.confidence > 0 && pair.confidence >= minConfidence {
+			pairs = append(pairs, pair)
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].confidence > pairs[j].confidence })
+
+	return groupDuplicates(comics, pairs), nil
+}
+
+// duplicateCandidates returns the index pairs of comics sharing a title match
+// key, or where one key starts with the other.
+func duplicateCandidates(comics []ComicJSON) [][2]int {
+	byKey := map[string][]int{}
+	for i, comic := range comics {
+		seen := map[string]bool{}
+		for _, title := range comic.Titles {
+			key := identity.MatchKey(title)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			byKey[key] = append(byKey[key], i)
+		}
+	}
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	seen := map[[2]int]bool{}
+	candidates := [][2]int{}
+	add := func(a, b int) {
+		if a == b {
+			return
+		}
+		pair := [2]int{min(a, b), max(a, b)}
+		if !seen[pair] {
+			seen[pair] = true
+			candidates = append(candidates, pair)
+		}
+	}
+	for i, key := range keys {
+		for x, a := range byKey[key] {
+			for _, b := range byKey[key][x+1:] {
+				add(a, b)
+			}
+		}
+		if len(key) < minPrefixKeyLength {
+			continue
+		}
+		// Sorted keys put every key that extends this one right after it
+		for _, longer := range keys[i+1:] {
+			if !strings.HasPrefix(longer, key) {
+				break
+			}
+			for _, a := range byKey[key] {
+				for _, b := range byKey[longer] {
+					add(a, b)
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+// scoreDuplicate rates how likely two comics are the same series, from 0 to
+// 1. Comics that cannot be merged get no confidence at all.
+func scoreDuplicate(a ComicJSON, b ComicJSON) duplicatePair {
+	if !mergeableTypes(a.ComType, b.ComType) ||
+		identity.IsNovel(identity.PrimaryTitle(a.Titles), a.ComType) !=
+			identity.IsNovel(identity.PrimaryTitle(b.Titles), b.ComType) {
+		return duplicatePair{}
+	}
+
+	pair := duplicatePair{}
+	aKeys, bKeys := titleMatchKeys(a.Titles), titleMatchKeys(b.Titles)
+	switch {
+	case identity.NormalizePrimaryTitle(identity.PrimaryTitle(a.Titles)) ==
+		identity.NormalizePrimaryTitle(identity.PrimaryTitle(b.Titles)):
+		pair.confidence, pair.reasons = 0.6, []string{DuplicateSameTitle}
+	case len(aKeys) > 0 && len(bKeys) > 0 && aKeys[0] == bKeys[0]:
+		pair.confidence, pair.reasons = 0.5, []string{DuplicateSimilarTitle}
+	case sharesKey(aKeys, bKeys):
+		pair.confidence, pair.reasons = 0.4, []string{DuplicateSharedTitle}
+	case sharesPrefix(aKeys, bKeys):
+		pair.confidence, pair.reasons = 0.3, []string{DuplicateTruncatedTitle}
+	default:
+		return duplicatePair{}
+	}
+
+	aAuthor := strings.ToLower(identity.NormalizeText(a.Author))
+	bAuthor := strings.ToLower(identity.NormalizeText(b.Author))
+	switch {
+	case aAuthor == "" || bAuthor == "":
+	case aAuthor == bAuthor:
+		pair.confidence += 0.3
+		pair.reasons = append(pair.reasons, DuplicateSameAuthor)
+	default:
+		pair.confidence -= 0.3
+		pair.reasons = append(pair.reasons, DuplicateDifferentAuthor)
+	}
+	if a.ComType != 0 && a.ComType == b.ComType {
+		pair.confidence += 0.1
+		pair.reasons = append(pair.reasons, DuplicateSameType)
+	}
+	pair.confidence = min(max(pair.confidence, 0), 1)
+	return pair
+}
+
+// groupDuplicates joins the pairs, strongest first, into groups where every
+// comic can be merged into the same base. A group is as confident as the
+// weakest pair that joined it.
+func groupDuplicates(comics []ComicJSON, pairs []duplicatePair) []DuplicateGroup {
+	parent := make([]int, len(comics))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	// groupType is the known type of each group root, 0 while unknown
+	groupType := make([]int, len(comics))
+	for i, comic := range comics {
+		groupType[i] = comic.ComType
+	}
+	confidence := map[int]float64{}
+	reasons := map[int][]string{}
+
+	for _, pair := range pairs {
+		a, b := find(pair.a), find(pair.b)
+		if a == b || !mergeableTypes(groupType[a], groupType[b]) {
+			continue
+		}
+		parent[b] = a
+		groupType[a] = max(groupType[a], groupType[b])
+		weakest := pair.confidence
+		for _, root := range []int{a, b} {
+			if value, ok := confidence[root]; ok {
+				weakest = min(weakest, value)
+			}
+		}
+		confidence[a] = weakest
+		reasons[a] = mergeStrings(mergeStrings(reasons[a], reasons[b]), pair.reasons)
+		delete(confidence, b)
+		delete(reasons, b)
+	}
+
+	members := map[int][]ComicJSON{}
+	for i, comic := range comics {
+		root := find(i)
+		members[root] = append(members[root], comic)
+	}
+	groups := []DuplicateGroup{}
+	for root, group := range members {
+		if len(group) < 2 {
+			continue
+		}
+		groups = append(groups, DuplicateGroup{
+			Confidence: confidence[root],
+			Reasons:    reasons[root],
+			Comics:     group,
+			Suggestion: suggestMerge(group, groupType[root]),
+		})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Confidence != groups[j].Confidence {
+			return groups[i].Confidence > groups[j].Confidence
+		}
+		return groups[i].Suggestion.BaseID < groups[j].Suggestion.BaseID
+	})
+	return groups
+}
+
+// suggestMerge keeps the comic the reader follows most closely as the base.
+// When the group has a known type the base must have it, see mergeableTypes.
+func suggestMerge(group []ComicJSON, comType int) MergeSuggestion {
+	base := -1
+	for i, comic := range group {
+		if comic.ComType != comType {
+			continue
+		}
+		if base < 0 || betterMergeBase(comic, group[base]) {
+			base = i
+		}
+	}
+
+	suggestion := MergeSuggestion{BaseID: group[base].ID, MergingIDs: []int{}, Requests: []string{}}
+	for i, comic := range group {
+		if i == base {
+			continue
+		}
+		suggestion.MergingIDs = append(suggestion.MergingIDs, comic.ID)
+		suggestion.Requests = append(
+			suggestion.Requests,
+			fmt.Sprintf("PATCH /comics/%d/%d", suggestion.BaseID, comic.ID),
+		)
+	}
+	return suggestion
+}
+
+func betterMergeBase(comic ComicJSON, current ComicJSON) bool {
+	if comic.Track != current.Track {
+		return comic.Track
+	}
+	if comic.ViewedChap != current.ViewedChap {
+		return comic.ViewedChap > current.ViewedChap
+	}
+	return comic.ID < current.ID
+}
+
+// canMergeType is the type rule of Merge: a comic of unknown type can be
+// merged into any comic, otherwise both types must be the same.
+func canMergeType(baseType int, mergingType int) bool {
+	return mergingType == 0 || baseType == mergingType
+}
+
+// mergeableTypes reports whether one of the comics can be merged into the
+// other, the suggested base is then the one with the known type.
+func mergeableTypes(a int, b int) bool {
+	return canMergeType(a, b) || canMergeType(b, a)
+}
+
+func titleMatchKeys(titles []string) []string {
+	keys := []string{}
+	for _, title := range titles {
+		if key := identity.MatchKey(title); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func sharesKey(a []string, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sharesPrefix(a []string, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			shorter, longer := x, y
+			if len(shorter) > len(longer) {
+				shorter, longer = longer, shorter
+			}
+			if len(shorter) >= minPrefixKeyLength && strings.HasPrefix(longer, shorter) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrPreconditionFailed = errors.New("comic was modified, precondition failed")
+
+// UpdateOptions tunes UpdateWithOptions.
+type UpdateOptions struct {
+	// Source is the publisher that reported a chapter increase, a negative
+	// value infers it from the published_in list
+	Source int
+	// IfMatch holds an If-Match header value, the update is rejected with
+	// ErrPreconditionFailed when it doesn't match the stored comic
+	IfMatch string
+}
+
+// ComicETag returns the strong entity tag of the stored comic version.
+func ComicETag(comic ComicJSON) string {
+	return fmt.Sprintf(`"%d-%d"`, comic.ID, comic.Version)
+}
+
+// ETagMatches applies the If-Match strong comparison against a comic.
+func ETagMatches(ifMatch string, comic ComicJSON) bool {
+	current := ComicETag(comic)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"comics/pkg/pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// exportBatchSize is the number of comics read at once by Export
+const exportBatchSize = 500
+
+// Export calls each with every comic selected by the filter, in the given
+// order. The order is fixed by reading the selected ids first, then the comics
+// are read in batches of those ids so the library never sits in memory as a
+// whole. A comic updated during the export keeps its place and one no longer
+// selected is left out. An error from each stops the export.
+func (s *SQLiteComicService) Export(
+	ctx context.Context,
+	filter ComicFilter,
+	order ComicSort,
+	each func(ComicJSON) error,
+) error {
+	if order.Field == SortRelevance {
+		return ErrRelevanceWithoutSearch
+	}
+	selection := comicFilters("", filter)
+	ids, err := s.selectedIDs(ctx, selection, order)
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(ids); start += exportBatchSize {
+		batch := ids[start:min(start+exportBatchSize, len(ids))]
+		comics, err := s.selectedComics(ctx, selection, batch)
+		if err != nil {
+			return err
+		}
+		for _, id := range batch {
+			comic, ok := comics[id]
+			if !ok {
+				continue
+			}
+			if err := each(comic); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// selectedIDs returns the ids of the selected comics in order
+func (s *SQLiteComicService) selectedIDs(ctx context.Context, selection comicSelection, order ComicSort) ([]int, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		"SELECT id FROM comics "+selection.where+" ORDER BY "+order.orderBy(),
+		selection.args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// selectedComics returns the comics among ids that are still selected, by id
+func (s *SQLiteComicService) selectedComics(
+	ctx context.Context,
+	selection comicSelection,
+	ids []int,
+) (map[int]ComicJSON, error) {
+	args := append([]any{}, selection.args...)
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+	rows, err := s.db.QueryContext(
+		ctx,
+		baseComicSelect()+" "+selection.where+" AND id IN ("+strings.Join(placeholders, ", ")+")",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comics, err := scanComics(rows)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]ComicJSON, len(comics))
+	for _, comic := range comics {
+		byID[comic.ID] = comic
+	}
+	return byID, nil
+}
+
+// Proto converts the comic to the message shared with the gRPC service.
+func (c ComicJSON) Proto() *pb.Comic {
+	coverVisible := c.CoverVisible
+	comic := &pb.Comic{
+		Id:           uint32(max(c.ID, 0)),
+		Titles:       c.Titles,
+		Author:       c.Author,
+		Description:  c.Description,
+		ComType:      pb.ComicType(c.ComType),
+		Status:       pb.Status(c.Status),
+		Cover:        c.Cover,
+		CurrentChap:  uint32(max(c.CurrentChap, 0)),
+		PublishedIn:  make([]pb.Publisher, len(c.PublishedIn)),
+		Genres:       make([]pb.Genre, len(c.Genres)),
+		Rating:       pb.Rating(c.Rating),
+		Track:        c.Track,
+		ViewedChap:   uint32(max(c.ViewedChap, 0)),
+		Deleted:      c.Deleted,
+		CoverVisible: &coverVisible,
+	}
+	if lastUpdate, err := time.Parse(time.RFC3339, c.LastUpdate); err == nil {
+		comic.LastUpdate = timestamppb.New(lastUpdate)
+	}
+	for i, publisher := range c.PublishedIn {
+		comic.PublishedIn[i] = pb.Publisher(publisher)
+	}
+	for i, genre := range c.Genres {
+		comic.Genres[i] = pb.Genre(genre)
+	}
+	return comic
+}
+
+// ComicFromProto converts a message shared with the gRPC service, the
+// inverse of ComicJSON.Proto.
+func ComicFromProto(message *pb.Comic) ComicJSON {
+	comic := ComicJSON{
+		ID:           int(message.GetId()),
+		Titles:       message.GetTitles(),
+		Author:       message.GetAuthor(),
+		Description:  message.GetDescription(),
+		ComType:      int(message.GetComType()),
+		Status:       int(message.GetStatus()),
+		Cover:        message.GetCover(),
+		CurrentChap:  int(message.GetCurrentChap()),
+		PublishedIn:  make([]int, len(message.GetPublishedIn())),
+		Genres:       make([]int, len(message.GetGenres())),
+		Rating:       int(message.GetRating()),
+		Track:        message.GetTrack(),
+		ViewedChap:   int(message.GetViewedChap()),
+		Deleted:      message.GetDeleted(),
+		CoverVisible: message.CoverVisible == nil || message.GetCoverVisible(),
+	}
+	if message.LastUpdate != nil {
+		comic.LastUpdate = message.GetLastUpdate().AsTime().UTC().Format(time.RFC3339)
+	}
+	for i, publisher := range message.GetPublishedIn() {
+		comic.PublishedIn[i] = int(publisher)
+	}
+	for i, genre := range message.GetGenres() {
+		comic.Genres[i] = int(genre)
+	}
+	return comic
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+type ChapterEventJSON struct {
+	ID           int    `json:"id"`
+	ComicID      int    `json:"comic_id"`
+	PreviousChap int    `json:"previous_chap"`
+	CurrentChap  int    `json:"current_chap"`
+	Publisher    int    `json:"publisher"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type ChapterHistoryResult struct {
+	Events      []ChapterEventJSON
+	Total       int
+	TotalPages  int
+	CurrentPage int
+}
+
+// History returns the chapter releases recorded for a comic, newest first.
+func (s *SQLiteComicService) History(
+	ctx context.Context,
+	id int,
+	offset int,
+	limit int,
+) (ChapterHistoryResult, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return ChapterHistoryResult{}, err
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM chapter_events WHERE comic_id = ?",
+		id,
+	).Scan(&total); err != nil {
+		return ChapterHistoryResult{}, err
+	}
+
+	query := `SELECT id, comic_id, previous_chap, current_chap, publisher,
+		CAST(created_at AS TEXT) FROM chapter_events
+		WHERE comic_id = ? ORDER BY created_at DESC, id DESC`
+	args := []any{id}
+	if limit > 0 {
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, offset)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return ChapterHistoryResult{}, err
+	}
+	defer rows.Close()
+
+	events := []ChapterEventJSON{}
+	for rows.Next() {
+		var event ChapterEventJSON
+		var createdAt string
+		if err := rows.Scan(
+			&event.ID,
+			&event.ComicID,
+			&event.PreviousChap,
+			&event.CurrentChap,
+			&event.Publisher,
+			&createdAt,
+		); err != nil {
+			return ChapterHistoryResult{}, err
+		}
+		event.CreatedAt = formatLastUpdate(createdAt)
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return ChapterHistoryResult{}, err
+	}
+
+	totalPages := 1
+	currentPage := 1
+	if limit > 0 {
+		totalPages = (total + limit - 1) / limit
+		currentPage = offset/limit + 1
+	}
+	return ChapterHistoryResult{
+		Events:      events,
+		Total:       total,
+		TotalPages:  totalPages,
+		CurrentPage: currentPage,
+	}, nil
+}
+
+// recordChapterEvent stores a release only when the chapter count increases.
+func recordChapterEvent(
+	ctx context.Context,
+	exec sqlExecutor,
+	comicID int,
+	previous int,
+	current int,
+	publisher int,
+) error {
+	if current <= previous {
+		return nil
+	}
+	_, err := exec.ExecContext(
+		ctx,
+		`INSERT INTO chapter_events (
+			comic_id, previous_chap, current_chap, publisher, created_at
+		) VALUES (?, ?, ?, ?, ?)`,
+		comicID,
+		previous,
+		current,
+		publisher,
+		time.Now().Unix(),
+	)
+	return err
+}
+
+// chapterSource guesses the publisher that reported a release: a publisher
+// added by the update wins, otherwise the first listed one is used.
+func chapterSource(previous []int, updated []int) int {
+	for _, publisher := range updated {
+		if publisher != 0 && !slices.Contains(previous, publisher) {
+			return publisher
+		}
+	}
+	for _, publisher := range updated {
+		if publisher != 0 {
+			return publisher
+		}
+	}
+	return 0
+}
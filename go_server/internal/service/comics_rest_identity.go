@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"comics/internal/identity"
+)
+
+var ErrIdentityConflict = errors.New("another comic already has this identity")
+
+// IdentityConflictError reports the comic that already holds an identity key,
+// it matches ErrIdentityConflict with errors.Is.
+type IdentityConflictError struct {
+	IdentityKey   string
+	ConflictingID int
+}
+
+func (e *IdentityConflictError) Error() string {
+	return fmt.Sprintf("%s: comic %d is %q", ErrIdentityConflict, e.ConflictingID, e.IdentityKey)
+}
+
+func (e *IdentityConflictError) Unwrap() error { return ErrIdentityConflict }
+
+// comicIdentityKey is the identity_key the Python side would store for the
+// comic, see src/db/identity.py.
+func comicIdentityKey(comic ComicJSON) string {
+	return identity.KeyFromTitles(comic.Titles, comic.ComType)
+}
+
+// checkIdentity fails with an *IdentityConflictError when a comic that is not
+// in the trash, other than the excluded ones, already uses the key.
+func checkIdentity(ctx context.Context, tx *sql.Tx, key string, exclude ...int) error {
+	if key == "" {
+		return nil
+	}
+	query := "SELECT id FROM comics WHERE identity_key = ? AND deleted = 0"
+	args := []any{key}
+	if len(exclude) > 0 {
+		query += " AND id NOT IN (?" + strings.Repeat(", ?", len(exclude)-1) + ")"
+		for _, id := range exclude {
+			args = append(args, id)
+		}
+	}
+
+	var conflictingID int
+	err := tx.QueryRowContext(ctx, query+" ORDER BY id LIMIT 1", args...).Scan(&conflictingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return &IdentityConflictError{IdentityKey: key, ConflictingID: conflictingID}
+}
+
+// ensureIdentitySchema adds the identity_key column when the Python side has
+// not done it yet and fills in the missing keys. Like the Python side, the
+// unique index is only created once there are no duplicates left.
+func ensureIdentitySchema(ctx context.Context, db *sql.DB) error {
+	var hasColumn int
+	err := db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM pragma_table_info('comics') WHERE name = 'identity_key'",
+	).Scan(&hasColumn)
+	if err != nil {
+		return err
+	}
+	if hasColumn == 0 {
+		_, err = db.ExecContext(ctx, "ALTER TABLE comics ADD COLUMN identity_key VARCHAR(255) NOT NULL DEFAULT ''")
+		if err != nil {
+			return err
+		}
+	}
+	_, err = db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_comics_identity_key ON comics (identity_key)")
+	if err != nil {
+		return err
+	}
+	if err = backfillIdentityKeys(ctx, db); err != nil {
+		return err
+	}
+
+	var duplicates int
+	err = db.QueryRowContext(ctx, `SELECT COUNT(*) FROM (
+		SELECT identity_key FROM comics
+		WHERE identity_key != '' AND deleted = 0
+		GROUP BY identity_key HAVING COUNT(*) > 1
+	)`).Scan(&duplicates)
+	if err != nil || duplicates > 0 {
+		return err
+	}
+	_, err = db.ExecContext(
+		ctx,
+		`CREATE UNIQUE INDEX IF NOT EXISTS uq_comics_identity_key
+		ON comics (identity_key) WHERE deleted = 0 AND identity_key != ''`,
+	)
+	return err
+}
+
+// backfillIdentityKeys computes the missing keys, rows that would break the
+// unique index keep an empty key until their duplicate is merged.
+func backfillIdentityKeys(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, "SELECT id, titles, com_type FROM comics WHERE COALESCE(identity_key, '') = ''")
+	if err != nil {
+		return err
+	}
+	keys := map[int]string{}
+	for rows.Next() {
+		var id, comType int
+		var titles string
+		if err := rows.Scan(&id, &titles, &comType); err != nil {
+			rows.Close()
+			return err
+		}
+		keys[id] = identity.KeyFromTitles(identity.SplitTitles(titles), comType)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for id, key := range keys {
+		if key == "" {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, "UPDATE OR IGNORE comics SET identity_key = ? WHERE id = ?", key, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Actions reported for each imported row
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportMerged  = "merged"
+	ImportSkipped = "skipped"
+)
+
+// ImportRow reports what happened to a row, Row counts from 1 in the order of
+// the input. MatchedBy is "id" or "identity" when the row matched a comic.
+type ImportRow struct {
+	Row           int             `json:"row"`
+	Action        string          `json:"action"`
+	ID            int             `json:"id,omitempty"`
+	MatchedBy     string          `json:"matched_by,omitempty"`
+	Reason        string          `json:"reason,omitempty"`
+	ConflictingID int             `json:"conflicting_id,omitempty"`
+	Conflicts     []MergeConflict `json:"conflicts,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun  bool        `json:"dry_run"`
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Merged  int         `json:"merged"`
+	Skipped int         `json:"skipped"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+// importUpdate lets every non-empty imported value replace the stored one
+var importUpdate = func() MergeStrategies {
+	strategies := MergeStrategies{}
+	for name := range MergeFields() {
+		strategies[name] = MergeFieldStrategy{Strategy: MergePreferMerging}
+	}
+	return strategies
+}()
+
+// Import applies the comics in a single transaction. A row with the ID of a
+// stored comic updates it, a row with the identity of a stored comic is
+// merged into it with the default merge strategies, and any other row is
+// created. Rows with invalid values or that can't be applied are skipped with
+// a reason. A dry run reports the same without writing anything.
+func (s *SQLiteComicService) Import(ctx context.Context, comics []ComicJSON, dryRun bool) (ImportReport, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ImportReport{}, err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	report := ImportReport{DryRun: dryRun, Rows: []ImportRow{}}
+	for i, comic := range comics {
+		row, err := importComic(ctx, tx, comic)
+		if err != nil {
+			return ImportReport{}, fmt.Errorf("row %d: %w", i+1, err)
+		}
+		row.Row = i + 1
+		switch row.Action {
+		case ImportCreated:
+			report.Created++
+		case ImportUpdated:
+			report.Updated++
+		case ImportMerged:
+			report.Merged++
+		case ImportSkipped:
+			report.Skipped++
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	if dryRun {
+		return report, nil
+	}
+	return report, tx.Commit()
+}
+
+// importComic applies a row inside the import transaction, only unexpected
+// database errors are returned.
+func importComic(ctx context.Context, tx *sql.Tx, comic ComicJSON) (ImportRow, error) {
+	comic.Titles = mergeStrings(comic.Titles, nil)
+	if len(comic.Titles) == 0 {
+		return ImportRow{Action: ImportSkipped, Reason: "the row has no titles"}, nil
+	}
+	if comic.Deleted {
+		return ImportRow{Action: ImportSkipped, ID: comic.ID, Reason: "the row is in the trash"}, nil
+	}
+	if err := checkComicValues(comic); err != nil {
+		return ImportRow{Action: ImportSkipped, ID: comic.ID, Reason: err.Error()}, nil
+	}
+
+	if comic.ID > 0 {
+		current, err := scanComic(tx.QueryRowContext(ctx, baseComicSelect()+" WHERE id = ?", comic.ID))
+		if err == nil {
+			return importInto(ctx, tx, current, comic, "id")
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return ImportRow{}, err
+		}
+	}
+
+	key := comicIdentityKey(comic)
+	if key != "" {
+		current, err := scanComic(tx.QueryRowContext(
+			ctx,
+			baseComicSelect()+" WHERE identity_key = ? AND deleted = 0 ORDER BY id LIMIT 1",
+			key,
+		))
+		if err == nil {
+			return importInto(ctx, tx, current, comic, "identity")
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return ImportRow{}, err
+		}
+	}
+
+	comic.ID = 0
+	id, err := createComic(ctx, tx, comic)
+	var conflict *IdentityConflictError
+	if errors.As(err, &conflict) {
+		return skippedConflict(ImportRow{}, conflict), nil
+	}
+	if err != nil {
+		return ImportRow{}, err
+	}
+	return ImportRow{Action: ImportCreated, ID: id}, nil
+}
+
+// importInto updates the matched comic with the row, by id the row replaces
+// the stored values and by identity it is merged into them.
+func importInto(ctx context.Context, tx *sql.Tx, current ComicJSON, comic ComicJSON, matchedBy string) (ImportRow, error) {
+	row := ImportRow{ID: current.ID, MatchedBy: matchedBy}
+	if current.Deleted {
+		row.Action, row.Reason = ImportSkipped, fmt.Sprintf("comic %d is in the trash", current.ID)
+		return row, nil
+	}
+
+	strategies, action := MergeStrategies(nil), ImportMerged
+	if matchedBy == "id" {
+		strategies, action = importUpdate, ImportUpdated
+	} else if !mergeableTypes(current.ComType, comic.ComType) {
+		row.Action, row.Reason = ImportSkipped, "Comics to merge should be of the same type"
+		return row, nil
+	}
+	merged, conflicts, err := mergeComicValuesWith(current, comic, strategies)
+	if err != nil {
+		return ImportRow{}, err
+	}
+	// A stored comic of unknown type takes the type of the row
+	merged.ComType = valueOrCurrent(comic.ComType, current.ComType)
+	row.Conflicts = conflicts
+	if comicValuesEqual(current, merged) {
+		row.Action, row.Reason = ImportSkipped, "nothing to change"
+		return row, nil
+	}
+
+	err = updateComicTx(ctx, tx, current.ID, UpdateOptions{Source: -1}, func(ComicJSON) (ComicJSON, error) {
+		return merged, nil
+	})
+	var conflict *IdentityConflictError
+	if errors.As(err, &conflict) {
+		return skippedConflict(row, conflict), nil
+	}
+	if err != nil {
+		return ImportRow{}, err
+	}
+	row.Action = action
+	return row, nil
+}
+
+func skippedConflict(row ImportRow, conflict *IdentityConflictError) ImportRow {
+	row.Action = ImportSkipped
+	row.Reason = conflict.Error()
+	row.ConflictingID = conflict.ConflictingID
+	return row
+}
+
+// comicValuesEqual compares the values an import can change
+func comicValuesEqual(a ComicJSON, b ComicJSON) bool {
+	return strings.Join(a.Titles, "|") == strings.Join(b.Titles, "|") &&
+		joinInts(a.PublishedIn) == joinInts(b.PublishedIn) &&
+		joinInts(a.Genres) == joinInts(b.Genres) &&
+		a.CurrentChap == b.CurrentChap && a.ViewedChap == b.ViewedChap &&
+		a.Cover == b.Cover && a.CoverVisible == b.CoverVisible &&
+		a.ComType == b.ComType && a.Status == b.Status && a.Rating == b.Rating &&
+		a.Author == b.Author && a.Description == b.Description && a.Track == b.Track
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMergeNotFound = errors.New("merge not found")
+	ErrMergeReverted = errors.New("merge already reverted")
+	ErrMergeConflict = errors.New("merge can no longer be reverted")
+)
+
+type MergeJSON struct {
+	ID         int    `json:"id"`
+	BaseID     int    `json:"base_id"`
+	MergingID  int    `json:"merging_id"`
+	MergedAt   string `json:"merged_at"`
+	RevertedAt string `json:"reverted_at,omitempty"`
+}
+
+// Merges lists the merge journal entries where the comic was the base.
+func (s *SQLiteComicService) Merges(ctx context.Context, baseID int) ([]MergeJSON, error) {
+	if _, err := s.Get(ctx, baseID); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT id, base_id, merging_id, CAST(merged_at AS TEXT),
+			CAST(COALESCE(reverted_at, '') AS TEXT)
+		FROM comic_merges WHERE base_id = ? ORDER BY merged_at DESC, id DESC`,
+		baseID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	merges := []MergeJSON{}
+	for rows.Next() {
+		var merge MergeJSON
+		var mergedAt, revertedAt string
+		if err := rows.Scan(&merge.ID, &merge.BaseID, &merge.MergingID, &mergedAt, &revertedAt); err != nil {
+			return nil, err
+		}
+		merge.MergedAt = formatLastUpdate(mergedAt)
+		if revertedAt != "" {
+			merge.RevertedAt = formatLastUpdate(revertedAt)
+		}
+		merges = append(merges, merge)
+	}
+	return merges, rows.Err()
+}
+
+// Unmerge restores the comic deleted by a merge and rolls the base comic back
+// to its pre-merge values. It fails with ErrMergeConflict when the base was
+// merged again or changed since, rolling back would lose those changes.
+func (s *SQLiteComicService) Unmerge(ctx context.Context, baseID int, mergeID int) (ComicJSON, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	var mergingID int
+	var baseSnapshot, mergingSnapshot string
+	var mergedSnapshot sql.NullString
+	var reverted bool
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT merging_id, base_snapshot, merging_snapshot, merged_snapshot,
+			reverted_at IS NOT NULL
+		FROM comic_merges WHERE id = ? AND base_id = ?`,
+		mergeID,
+		baseID,
+	).Scan(&mergingID, &baseSnapshot, &mergingSnapshot, &mergedSnapshot, &reverted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ComicJSON{}, ErrMergeNotFound
+	}
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	if reverted {
+		return ComicJSON{}, ErrMergeReverted
+	}
+
+	var original, duplicate ComicJSON
+	if err := json.Unmarshal([]byte(baseSnapshot), &original); err != nil {
+		return ComicJSON{}, err
+	}
+	if err := json.Unmarshal([]byte(mergingSnapshot), &duplicate); err != nil {
+		return ComicJSON{}, err
+	}
+
+	current, err := scanComic(tx.QueryRowContext(ctx, baseComicSelect()+" WHERE id = ?", baseID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ComicJSON{}, ErrComicNotFound
+	}
+	if err != nil {
+		return ComicJSON{}, err
+	}
+
+	var later int
+	err = tx.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM comic_merges WHERE base_id = ? AND id > ? AND reverted_at IS NULL",
+		baseID,
+		mergeID,
+	).Scan(&later)
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	if later > 0 {
+		return ComicJSON{}, fmt.Errorf("%w: revert the %d later merges into comic %d first", ErrMergeConflict, later, baseID)
+	}
+	// Journal entries written before merged_snapshot existed only get the
+	// check above
+	if mergedSnapshot.Valid {
+		var merged ComicJSON
+		if err := json.Unmarshal([]byte(mergedSnapshot.String), &merged); err != nil {
+			return ComicJSON{}, err
+		}
+		if field, changed := changedMergeField(merged, current); changed {
+			return ComicJSON{}, fmt.Errorf("%w: %s of comic %d changed since the merge", ErrMergeConflict, field, baseID)
+		}
+	}
+
+	var inUse int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM comics WHERE id = ?", mergingID).Scan(&inUse)
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	if inUse > 0 {
+		return ComicJSON{}, fmt.Errorf("%w: comic id %d is in use again", ErrMergeConflict, mergingID)
+	}
+
+	identityKey := comicIdentityKey(original)
+	if err = checkIdentity(ctx, tx, identityKey, baseID); err != nil {
+		return ComicJSON{}, err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		`UPDATE comics SET titles = ?, current_chap = ?, cover = ?, last_update = ?,
+			status = ?, published_in = ?, genres = ?, description = ?, author = ?,
+			track = ?, viewed_chap = ?, rating = ?, cover_visible = ?, identity_key = ?
+		WHERE id = ?`,
+		strings.Join(original.Titles, "|"),
+		original.CurrentChap,
+		original.Cover,
+		time.Now().Unix(),
+		original.Status,
+		joinInts(original.PublishedIn),
+		joinInts(original.Genres),
+		original.Description,
+		original.Author,
+		boolInt(original.Track),
+		original.ViewedChap,
+		original.Rating,
+		original.CoverVisible,
+		identityKey,
+		baseID,
+	)
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	if !duplicate.Deleted {
+		if err = checkIdentity(ctx, tx, comicIdentityKey(duplicate)); err != nil {
+			return ComicJSON{}, err
+		}
+	}
+	if err = insertComicSnapshot(ctx, tx, duplicate); err != nil {
+		return ComicJSON{}, err
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		"UPDATE comic_merges SET reverted_at = ? WHERE id = ?",
+		time.Now().Unix(),
+		mergeID,
+	)
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return ComicJSON{}, err
+	}
+	return s.Get(ctx, baseID)
+}
+
+// changedMergeField returns the first field a merge combines that differs
+// between the two comics, empty lists being equal whatever their encoding.
+func changedMergeField(a ComicJSON, b ComicJSON) (string, bool) {
+	for _, field := range mergeFields {
+		if field.empty(a) && field.empty(b) {
+			continue
+		}
+		if !reflect.DeepEqual(field.value(a), field.value(b)) {
+			return field.name, true
+		}
+	}
+	return "", false
+}
+
+// recordMerge stores both rows as they were before the merge was applied and
+// the base as the merge left it.
+func recordMerge(ctx context.Context, exec sqlExecutor, base ComicJSON, duplicate ComicJSON, merged ComicJSON) error {
+	snapshots := []string{}
+	for _, comic := range []ComicJSON{base, duplicate, merged} {
+		snapshot, err := json.Marshal(comic)
+		if err != nil {
+			return err
+		}
+		snapshots = append(snapshots, string(snapshot))
+	}
+	_, err := exec.ExecContext(
+		ctx,
+		`INSERT INTO comic_merges (
+			base_id, merging_id, base_snapshot, merging_snapshot, merged_snapshot, merged_at
+		) VALUES (?, ?, ?, ?, ?, ?)`,
+		base.ID,
+		duplicate.ID,
+		snapshots[0],
+		snapshots[1],
+		snapshots[2],
+		time.Now().Unix(),
+	)
+	return err
+}
+
+// ensureMergeSchema adds the merged_snapshot column to journals created
+// before it existed.
+func ensureMergeSchema(ctx context.Context, db *sql.DB) error {
+	var hasColumn int
+	err := db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM pragma_table_info('comic_merges') WHERE name = 'merged_snapshot'",
+	).Scan(&hasColumn)
+	if err != nil || hasColumn > 0 {
+		return err
+	}
+	_, err = db.ExecContext(ctx, "ALTER TABLE comic_merges ADD COLUMN merged_snapshot TEXT")
+	return err
+}
+
+// insertComicSnapshot re-creates a comic row keeping its original id.
+func insertComicSnapshot(ctx context.Context, exec sqlExecutor, comic ComicJSON) error {
+	_, err := exec.ExecContext(
+		ctx,
+		`INSERT INTO comics (
+			id, titles, current_chap, cover, last_update, com_type, status,
+			published_in, genres, description, author, track, viewed_chap,
+			rating, deleted, cover_visible, identity_key
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		comic.ID,
+		strings.Join(comic.Titles, "|"),
+		comic.CurrentChap,
+		comic.Cover,
+		parseLastUpdate(comic.LastUpdate),
+		comic.ComType,
+		comic.Status,
+		joinInts(comic.PublishedIn),
+		joinInts(comic.Genres),
+		comic.Description,
+		comic.Author,
+		boolInt(comic.Track),
+		comic.ViewedChap,
+		comic.Rating,
+		boolInt(comic.Deleted),
+		comic.CoverVisible,
+		comicIdentityKey(comic),
+	)
+	return err
+}
+
+func parseLastUpdate(value string) int64 {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.Unix()
+	}
+	return time.Now().Unix()
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"comics/pkg/pb"
+)
+
+var (
+	ErrInvalidMergeStrategy = errors.New("invalid merge strategy")
+	ErrInvalidMergedComic   = errors.New("invalid merged comic")
+)
+
+// MergeStrategy decides which value a field gets when two comics are merged.
+type MergeStrategy string
+
+const (
+	// MergePreferBase keeps the base value unless it is empty
+	MergePreferBase MergeStrategy = "prefer_base"
+	// MergePreferMerging takes the merging value unless it is empty
+	MergePreferMerging MergeStrategy = "prefer_merging"
+	// MergeMax keeps the highest value, for booleans either one being set
+	MergeMax MergeStrategy = "max"
+	// MergeUnion keeps every value of both lists
+	MergeUnion MergeStrategy = "union"
+	// MergeValue sets the field to an explicit value
+	MergeValue MergeStrategy = "value"
+)
+
+// MergeFieldStrategy is the strategy of a field, Value is only read by
+// MergeValue. In JSON it is either {"strategy": "value", "value": 10} or the
+// strategy name alone.
+type MergeFieldStrategy struct {
+	Strategy MergeStrategy   `json:"strategy"`
+	Value    json.RawMessage `json:"value,omitempty"`
+}
+
+func (m *MergeFieldStrategy) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		m.Value = nil
+		return json.Unmarshal(data, &m.Strategy)
+	}
+	type plain MergeFieldStrategy
+	return json.Unmarshal(data, (*plain)(m))
+}
+
+// MergeStrategies maps the JSON name of a field to its strategy, the fields
+// left out keep the default strategy listed by MergeFields.
+type MergeStrategies map[string]MergeFieldStrategy
+
+// MergeConflict is a field where both comics have a different value.
+type MergeConflict struct {
+	Field    string        `json:"field"`
+	Strategy MergeStrategy `json:"strategy"`
+	Base     any           `json:"base"`
+	Merging  any           `json:"merging"`
+	Merged   any           `json:"merged"`
+}
+
+// MergePreview is the result of a merge without applying it.
+type MergePreview struct {
+	Base      ComicJSON       `json:"base"`
+	Merging   ComicJSON       `json:"merging"`
+	Merged    ComicJSON       `json:"merged"`
+	Conflicts []MergeConflict `json:"conflicts"`
+}
+
+type mergeField struct {
+	name       string
+	fallback   MergeStrategy
+	strategies []MergeStrategy
+	value      func(ComicJSON) any
+	empty      func(ComicJSON) bool
+	// merge applies any strategy but MergeValue to merged, a copy of base
+	merge func(merged *ComicJSON, merging ComicJSON, strategy MergeStrategy)
+	set   func(merged *ComicJSON, value json.RawMessage) error
+}
+
+// newMergeField describes a field through its getter and setter. max and
+// union are nil when the field does not support them.
+func newMergeField[T any](
+	name string,
+	fallback MergeStrategy,
+	get func(ComicJSON) T,
+	set func(*ComicJSON, T),
+	empty func(T) bool,
+	maxOf func(T, T) T,
+	union func(T, T) T,
+) mergeField {
+	field := mergeField{
+		name:       name,
+		fallback:   fallback,
+		strategies: []MergeStrategy{MergePreferBase, MergePreferMerging, MergeValue},
+		value:      func(comic ComicJSON) any { return get(comic) },
+		empty:      func(comic ComicJSON) bool { return empty(get(comic)) },
+	}
+	if maxOf != nil {
+		field.strategies = append(field.strategies, MergeMax)
+	}
+	if union != nil {
+		field.strategies = append(field.strategies, MergeUnion)
+	}
+	field.merge = func(merged *ComicJSON, merging ComicJSON, strategy MergeStrategy) {
+		base, incoming := get(*merged), get(merging)
+		switch strategy {
+		case MergePreferBase:
+			if empty(base) && !empty(incoming) {
+				set(merged, incoming)
+			}
+		case MergePreferMerging:
+			if !empty(incoming) {
+				set(merged, incoming)
+			}
+		case MergeMax:
+			set(merged, maxOf(base, incoming))
+		case MergeUnion:
+			set(merged, union(base, incoming))
+		}
+	}
+	field.set = func(merged *ComicJSON, raw json.RawMessage) error {
+		var value T
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("%w: %s value: %v", ErrInvalidMergeStrategy, name, err)
+		}
+		set(merged, value)
+		return nil
+	}
+	return field
+}
+
+func emptyInt(value int) bool         { return value == 0 }
+func emptyString(value string) bool   { return value == "" }
+func emptyList[T any](value []T) bool { return len(value) == 0 }
+func never[T any](T) bool             { return false }
+func either(a bool, b bool) bool      { return a || b }
+func maxInt(a int, b int) int         { return max(a, b) }
+
+// mergeFields lists the fields a merge combines with their default strategy,
+// com_type is left out since Merge only combines comics of the same type.
+var mergeFields = []mergeField{
+	newMergeField("titles", MergeUnion,
+		func(c ComicJSON) []string { return c.Titles },
+		func(c *ComicJSON, v []string) { c.Titles = mergeStrings(v, nil) },
+		emptyList[string], nil, mergeStrings),
+	newMergeField("current_chap", MergeMax,
+		func(c ComicJSON) int { return c.CurrentChap },
+		func(c *ComicJSON, v int) { c.CurrentChap = v },
+		emptyInt, maxInt, nil),
+	newMergeField("viewed_chap", MergeMax,
+		func(c ComicJSON) int { return c.ViewedChap },
+		func(c *ComicJSON, v int) { c.ViewedChap = v },
+		emptyInt, maxInt, nil),
+	newMergeField("track", MergeMax,
+		func(c ComicJSON) bool { return c.Track },
+		func(c *ComicJSON, v bool) { c.Track = v },
+		never[bool], either, nil),
+	newMergeField("rating", MergePreferBase,
+		func(c ComicJSON) int { return c.Rating },
+		func(c *ComicJSON, v int) { c.Rating = v },
+		emptyInt, maxInt, nil),
+	// Every status value is meaningful, so the base status is never empty
+	newMergeField("status", MergePreferBase,
+		func(c ComicJSON) int { return c.Status },
+		func(c *ComicJSON, v int) { c.Status = v },
+		never[int], nil, nil),
+	newMergeField("author", MergePreferBase,
+		func(c ComicJSON) string { return c.Author },
+		func(c *ComicJSON, v string) { c.Author = v },
+		emptyString, nil, nil),
+	newMergeField("description", MergePreferBase,
+		func(c ComicJSON) string { return c.Description },
+		func(c *ComicJSON, v string) { c.Description = v },
+		emptyString, nil, nil),
+	// A hidden cover counts as no cover
+	newMergeField("cover", MergePreferBase,
+		func(c ComicJSON) string {
+			if !c.CoverVisible {
+				return ""
+			}
+			return c.Cover
+		},
+		func(c *ComicJSON, v string) { c.Cover, c.CoverVisible = v, v != "" },
+		emptyString, nil, nil),
+	newMergeField("published_in", MergeUnion,
+		func(c ComicJSON) []int { return c.PublishedIn },
+		func(c *ComicJSON, v []int) { c.PublishedIn = mergeInts(v, nil) },
+		emptyList[int], nil, mergeInts),
+	newMergeField("genres", MergeUnion,
+		func(c ComicJSON) []int { return c.Genres },
+		func(c *ComicJSON, v []int) { c.Genres = mergeInts(v, nil) },
+		emptyList[int], nil, mergeInts),
+}
+
+// MergeFields returns the default strategy of every field a merge combines.
+func MergeFields() map[string]MergeStrategy {
+	fields := map[string]MergeStrategy{}
+	for _, field := range mergeFields {
+		fields[field.name] = field.fallback
+	}
+	return fields
+}
+
+// Validate fails with ErrInvalidMergeStrategy on unknown fields and on
+// strategies a field does not support.
+func (m MergeStrategies) Validate() error {
+	known := map[string]mergeField{}
+	for _, field := range mergeFields {
+		known[field.name] = field
+	}
+	for name, strategy := range m {
+		field, ok := known[name]
+		if !ok {
+			return fmt.Errorf("%w: unknown field %s", ErrInvalidMergeStrategy, name)
+		}
+		if !slices.Contains(field.strategies, strategy.Strategy) {
+			return fmt.Errorf("%w: %s does not support %q", ErrInvalidMergeStrategy, name, strategy.Strategy)
+		}
+		if strategy.Strategy == MergeValue && len(strategy.Value) == 0 {
+			return fmt.Errorf("%w: %s needs a value", ErrInvalidMergeStrategy, name)
+		}
+	}
+	return nil
+}
+
+// mergeComicValuesWith merges the duplicate into the base field by field and
+// reports the fields where both comics had a different value.
+func mergeComicValuesWith(base ComicJSON, duplicate ComicJSON, strategies MergeStrategies) (ComicJSON, []MergeConflict, error) {
+	if err := strategies.Validate(); err != nil {
+		return ComicJSON{}, nil, err
+	}
+	merged := base
+	conflicts := []MergeConflict{}
+	for _, field := range mergeFields {
+		strategy, ok := strategies[field.name]
+		if !ok {
+			strategy = MergeFieldStrategy{Strategy: field.fallback}
+		}
+		if strategy.Strategy == MergeValue {
+			if err := field.set(&merged, strategy.Value); err != nil {
+				return ComicJSON{}, nil, err
+			}
+		} else {
+			field.merge(&merged, duplicate, strategy.Strategy)
+		}
+
+		if field.empty(base) || field.empty(duplicate) ||
+			reflect.DeepEqual(field.value(base), field.value(duplicate)) {
+			continue
+		}
+		conflicts = append(conflicts, MergeConflict{
+			Field:    field.name,
+			Strategy: strategy.Strategy,
+			Base:     field.value(base),
+			Merging:  field.value(duplicate),
+			Merged:   field.value(merged),
+		})
+	}
+	return merged, conflicts, nil
+}
+
+// comicValueError reports a value a stored comic can't have, Field is the
+// JSON name of the field.
+type comicValueError struct {
+	Field   string
+	Message string
+}
+
+func (e *comicValueError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// checkComicValues applies the rules of the patch documents to a whole comic.
+// The titles column is pipe separated and the enums are those of pkg/pb.
+func checkComicValues(comic ComicJSON) *comicValueError {
+	if len(comic.Titles) == 0 {
+		return &comicValueError{"titles", "must have at least one title"}
+	}
+	for _, title := range comic.Titles {
+		if strings.TrimSpace(title) == "" || strings.Contains(title, "|") {
+			return &comicValueError{"titles", `must be non-empty strings without "|"`}
+		}
+	}
+	for _, value := range []struct {
+		name  string
+		value int
+	}{{"current_chap", comic.CurrentChap}, {"viewed_chap", comic.ViewedChap}} {
+		if value.value < 0 {
+			return &comicValueError{value.name, "must not be negative"}
+		}
+	}
+	if _, ok := pb.Rating_name[int32(comic.Rating)]; !ok {
+		return &comicValueError{"rating", fmt.Sprintf("must be between 0 and %d", pb.Rating_SSS_RATED)}
+	}
+	if _, ok := pb.Status_name[int32(comic.Status)]; !ok {
+		return &comicValueError{"status", fmt.Sprintf("must be a known value, not %d", comic.Status)}
+	}
+	if _, ok := pb.ComicType_name[int32(comic.ComType)]; !ok {
+		return &comicValueError{"com_type", fmt.Sprintf("must be a known value, not %d", comic.ComType)}
+	}
+	for _, values := range []struct {
+		name   string
+		values []int
+	}{{"published_in", comic.PublishedIn}, {"genres", comic.Genres}} {
+		if slices.ContainsFunc(values.values, func(value int) bool { return value < 0 }) {
+			return &comicValueError{values.name, "must not be negative"}
+		}
+	}
+	return nil
+}
+
+// validateMergedComic checks the merge result, explicit values could
+// otherwise store anything.
+func validateMergedComic(comic ComicJSON) error {
+	if err := checkComicValues(comic); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidMergedComic, err)
+	}
+	return nil
+}
+
+// PreviewMerge returns what Merge would store without changing anything,
+// failing the same way Merge would.
+func (s *SQLiteComicService) PreviewMerge(
+	ctx context.Context,
+	baseID int,
+	mergingID int,
+	strategies MergeStrategies,
+) (MergePreview, error) {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return MergePreview{}, err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	preview, err := planMerge(ctx, tx, baseID, mergingID, strategies)
+	if err != nil {
+		return MergePreview{}, err
+	}
+	return preview, tx.Commit()
+}
+
+// planMerge loads both comics and merges their values, checking the type
+// rule and the identity of the result.
+func planMerge(ctx context.Context, tx *sql.Tx, baseID int, mergingID int, strategies MergeStrategies) (MergePreview, error) {
+	if baseID == mergingID {
+		return MergePreview{}, fmt.Errorf("Comics cannot merge with themselves")
+	}
+
+	base, err := scanComic(tx.QueryRowContext(ctx, baseComicSelect()+" WHERE id = ?", baseID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return MergePreview{}, ErrComicNotFound
+	}
+	if err != nil {
+		return MergePreview{}, err
+	}
+
+	duplicate, err := scanComic(tx.QueryRowContext(ctx, baseComicSelect()+" WHERE id = ?", mergingID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return MergePreview{}, ErrComicNotFound
+	}
+	if err != nil {
+		return MergePreview{}, err
+	}
+	if !canMergeType(base.ComType, duplicate.ComType) {
+		return MergePreview{}, fmt.Errorf("Comics to merge should be of the same type")
+	}
+
+	merged, conflicts, err := mergeComicValuesWith(base, duplicate, strategies)
+	if err != nil {
+		return MergePreview{}, err
+	}
+	if err = validateMergedComic(merged); err != nil {
+		return MergePreview{}, err
+	}
+	if err = checkIdentity(ctx, tx, comicIdentityKey(merged), baseID, mergingID); err != nil {
+		return MergePreview{}, err
+	}
+	return MergePreview{Base: base, Merging: duplicate, Merged: merged, Conflicts: conflicts}, nil
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"comics/internal/jsonpatch"
+)
+
+// ErrInvalidPatch is wrapped by the errors of a patched comic with values no
+// comic can have
+var ErrInvalidPatch = errors.New("invalid patch")
+
+// ComicPatch is either an RFC 7396 merge patch or a list of RFC 6902
+// operations, exactly one of them is set.
+type ComicPatch struct {
+	Merge      map[string]any
+	Operations []jsonpatch.Operation
+}
+
+// readOnlyComicFields can appear in a patched document as long as their value
+// is left unchanged.
+var readOnlyComicFields = map[string]bool{"id": true, "last_update": true, "deleted": true}
+
+// Patch applies a merge patch or JSON patch to the stored comic. Unlike
+// Update, explicit nulls and zero values are written as they are, errors in
+// the resulting document are returned as *jsonpatch.Error.
+func (s *SQLiteComicService) Patch(
+	ctx context.Context,
+	id int,
+	patch ComicPatch,
+	options UpdateOptions,
+) (ComicJSON, error) {
+	return s.updateComic(ctx, id, options, patch.apply)
+}
+
+// apply returns the comic with the patch applied.
+func (p ComicPatch) apply(current ComicJSON) (ComicJSON, error) {
+	encoded, err := json.Marshal(current)
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	var doc any
+	if err := json.Unmarshal(encoded, &doc); err != nil {
+		return ComicJSON{}, err
+	}
+
+	if p.Operations != nil {
+		doc, err = jsonpatch.Apply(doc, p.Operations)
+		if err != nil {
+			return ComicJSON{}, err
+		}
+	} else {
+		doc = jsonpatch.MergePatch(doc, p.Merge)
+	}
+
+	updated, err := decodeComicDocument(current, doc)
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	// A new cover is shown unless the same patch says otherwise
+	if updated.Cover != current.Cover && !p.touches("cover_visible") {
+		updated.CoverVisible = true
+	}
+	return updated, nil
+}
+
+// touches reports whether the patch addresses the top level field.
+func (p ComicPatch) touches(field string) bool {
+	if p.Operations == nil {
+		_, ok := p.Merge[field]
+		return ok
+	}
+	for _, operation := range p.Operations {
+		for _, path := range []string{operation.Path, operation.From} {
+			if path == "/"+field || strings.HasPrefix(path, "/"+field+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeComicDocument validates a patched comic document. Missing and null
+// fields take their zero value, read-only fields must keep the stored one and
+// the enums and the rating must be known values.
+func decodeComicDocument(current ComicJSON, doc any) (ComicJSON, error) {
+	fields, ok := doc.(map[string]any)
+	if !ok {
+		return ComicJSON{}, &jsonpatch.Error{Path: "", Message: "comic must be a JSON object"}
+	}
+
+	comic := ComicJSON{
+		ID:         current.ID,
+		LastUpdate: current.LastUpdate,
+		Deleted:    current.Deleted,
+		Version:    current.Version,
+	}
+	var original map[string]any
+	encoded, err := json.Marshal(current)
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	if err := json.Unmarshal(encoded, &original); err != nil {
+		return ComicJSON{}, err
+	}
+
+	for key, value := range fields {
+		path := "/" + strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+		if readOnlyComicFields[key] {
+			if value != nil && !jsonEqual(value, original[key]) {
+				return ComicJSON{}, &jsonpatch.Error{Path: path, Message: "field is read-only"}
+			}
+			continue
+		}
+
+		var err error
+		switch key {
+		case "titles":
+			comic.Titles, err = documentStrings(path, value)
+		case "cover":
+			comic.Cover, err = documentString(path, value)
+		case "description":
+			comic.Description, err = documentString(path, value)
+		case "author":
+			comic.Author, err = documentString(path, value)
+		case "cover_visible":
+			comic.CoverVisible, err = documentBool(path, value)
+		case "track":
+			comic.Track, err = documentBool(path, value)
+		case "current_chap":
+			comic.CurrentChap, err = documentInt(path, value)
+		case "viewed_chap":
+			comic.ViewedChap, err = documentInt(path, value)
+		case "com_type":
+			comic.ComType, err = documentInt(path, value)
+		case "status":
+			comic.Status, err = documentInt(path, value)
+		case "rating":
+			comic.Rating, err = documentInt(path, value)
+		case "published_in":
+			comic.PublishedIn, err = documentInts(path, value)
+		case "genres":
+			comic.Genres, err = documentInts(path, value)
+		default:
+			err = &jsonpatch.Error{Path: path, Message: "unknown comic field"}
+		}
+		if err != nil {
+			return ComicJSON{}, err
+		}
+	}
+
+	if err := checkComicValues(comic); err != nil {
+		return ComicJSON{}, &jsonpatch.Error{Path: "/" + err.Field, Message: err.Message, Err: ErrInvalidPatch}
+	}
+	return comic, nil
+}
+
+func documentString(path string, value any) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	parsed, ok := value.(string)
+	if !ok {
+		return "", &jsonpatch.Error{Path: path, Message: "must be a string"}
+	}
+	return parsed, nil
+}
+
+func documentBool(path string, value any) (bool, error) {
+	if value == nil {
+		return false, nil
+	}
+	parsed, ok := value.(bool)
+	if !ok {
+		return false, &jsonpatch.Error{Path: path, Message: "must be a boolean"}
+	}
+	return parsed, nil
+}
+
+func documentInt(path string, value any) (int, error) {
+	if value == nil {
+		return 0, nil
+	}
+	parsed, ok := value.(float64)
+	if !ok || parsed != math.Trunc(parsed) || parsed > math.MaxInt32 {
+		return 0, &jsonpatch.Error{Path: path, Message: "must be an integer"}
+	}
+	if parsed < 0 {
+		return 0, &jsonpatch.Error{Path: path, Message: "must not be negative"}
+	}
+	return int(parsed), nil
+}
+
+func documentStrings(path string, value any) ([]string, error) {
+	if value == nil {
+		return []string{}, nil
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, &jsonpatch.Error{Path: path, Message: "must be an array of strings"}
+	}
+	values := make([]string, 0, len(items))
+	for i, item := range items {
+		parsed, ok := item.(string)
+		if !ok || strings.TrimSpace(parsed) == "" {
+			return nil, &jsonpatch.Error{Path: fmt.Sprintf("%s/%d", path, i), Message: "must be a non-empty string"}
+		}
+		if strings.Contains(parsed, "|") {
+			return nil, &jsonpatch.Error{Path: fmt.Sprintf("%s/%d", path, i), Message: `must not contain "|"`}
+		}
+		values = append(values, parsed)
+	}
+	return values, nil
+}
+
+func documentInts(path string, value any) ([]int, error) {
+	if value == nil {
+		return []int{}, nil
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, &jsonpatch.Error{Path: path, Message: "must be an array of integers"}
+	}
+	values := make([]int, 0, len(items))
+	for i, item := range items {
+		parsed, err := documentInt(fmt.Sprintf("%s/%d", path, i), item)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, parsed)
+	}
+	return values, nil
+}
+
+func jsonEqual(a any, b any) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(left) == string(right)
+}
@@ -0,0 +1,410 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"comics/pkg/pb"
+)
+
+var (
+	ErrPublisherSiteNotFound = errors.New("publisher site not found")
+	ErrPublisherSiteExists   = errors.New("publisher site already exists")
+	ErrInvalidPublisherSite  = errors.New("invalid publisher site")
+)
+
+// PublisherSite is where a publisher lives, the Go side of
+// src/scrape/url_switch.json. The targets are the update pages to scrape,
+// kept relative to BaseURL so they follow the publisher when it moves. The
+// templates build the series and chapter URLs from {base}, {slug} and
+// {chapter}, they are only stored for now: the scrapers take the links from
+// the scraped pages. Dead publishers aren't scraped.
+type PublisherSite struct {
+	Publisher       int      `json:"publisher"`
+	Name            string   `json:"name"`
+	BaseURL         string   `json:"base_url"`
+	PreviousDomains []string `json:"previous_domains"`
+	Targets         []string `json:"targets"`
+	SeriesTemplate  string   `json:"series_template,omitempty"`
+	ChapterTemplate string   `json:"chapter_template,omitempty"`
+	Alive           bool     `json:"alive"`
+	UpdatedAt       string   `json:"updated_at,omitempty"`
+}
+
+// Host returns the domain of the base URL
+func (s PublisherSite) Host() string {
+	base, err := url.Parse(s.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(base.Host)
+}
+
+// TargetURLs returns the absolute URLs of the targets
+func (s PublisherSite) TargetURLs() []string {
+	urls := make([]string, len(s.Targets))
+	for i, target := range s.Targets {
+		if strings.HasPrefix(target, "/") {
+			target = s.BaseURL + target
+		}
+		urls[i] = target
+	}
+	return urls
+}
+
+// SeriesURL returns the URL of a series, empty without a template
+func (s PublisherSite) SeriesURL(slug string) string {
+	return s.expand(s.SeriesTemplate, slug, 0)
+}
+
+// ChapterURL returns the URL of a chapter of a series, empty without a
+// template
+func (s PublisherSite) ChapterURL(slug string, chapter int) string {
+	return s.expand(s.ChapterTemplate, slug, chapter)
+}
+
+func (s PublisherSite) expand(template, slug string, chapter int) string {
+	if template == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		"{base}", s.BaseURL,
+		"{slug}", url.PathEscape(slug),
+		"{chapter}", strconv.Itoa(chapter),
+	).Replace(template)
+}
+
+// normalize validates the site. The base URL loses its trailing slash, the
+// domains are lowered hosts without the current one and the targets on the
+// site become paths.
+func (s PublisherSite) normalize() (PublisherSite, error) {
+	if _, ok := pb.Publisher_name[int32(s.Publisher)]; !ok || s.Publisher == 0 { // #nosec G115
+		return PublisherSite{}, fmt.Errorf("%w: unknown publisher %d", ErrInvalidPublisherSite, s.Publisher)
+	}
+	s.Name = pb.Publisher(s.Publisher).String() // #nosec G115
+
+	base, err := url.Parse(strings.TrimSpace(s.BaseURL))
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return PublisherSite{}, fmt.Errorf("%w: base_url %q must be an http(s) URL", ErrInvalidPublisherSite, s.BaseURL)
+	}
+	host := strings.ToLower(base.Host)
+	s.BaseURL = base.Scheme + "://" + host + strings.TrimRight(base.EscapedPath(), "/")
+
+	domains := []string{}
+	for _, domain := range s.PreviousDomains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if parsed, err := url.Parse(domain); err == nil && parsed.Host != "" {
+			domain = strings.ToLower(parsed.Host)
+		}
+		if domain != "" && domain != host && !slices.Contains(domains, domain) {
+			domains = append(domains, domain)
+		}
+	}
+	s.PreviousDomains = domains
+
+	targets := []string{}
+	for _, target := range s.Targets {
+		target = strings.TrimSpace(target)
+		if parsed, err := url.Parse(target); err == nil && parsed.Host != "" {
+			domain := strings.ToLower(parsed.Host)
+			if domain == host || slices.Contains(domains, domain) {
+				target = strings.TrimPrefix(parsed.EscapedPath(), strings.TrimPrefix(s.BaseURL, base.Scheme+"://"+host))
+				if parsed.RawQuery != "" {
+					target += "?" + parsed.RawQuery
+				}
+			}
+		} else if err != nil || target == "" {
+			return PublisherSite{}, fmt.Errorf("%w: invalid target %q", ErrInvalidPublisherSite, target)
+		}
+		if !strings.Contains(target, "://") && !strings.HasPrefix(target, "/") {
+			target = "/" + target
+		}
+		if !slices.Contains(targets, target) {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		targets = []string{"/"}
+	}
+	s.Targets = targets
+
+	s.SeriesTemplate = strings.TrimSpace(s.SeriesTemplate)
+	s.ChapterTemplate = strings.TrimSpace(s.ChapterTemplate)
+	if s.SeriesTemplate != "" && !strings.Contains(s.SeriesTemplate, "{slug}") {
+		return PublisherSite{}, fmt.Errorf("%w: series_template needs {slug}", ErrInvalidPublisherSite)
+	}
+	if s.ChapterTemplate != "" && !strings.Contains(s.ChapterTemplate, "{chapter}") {
+		return PublisherSite{}, fmt.Errorf("%w: chapter_template needs {chapter}", ErrInvalidPublisherSite)
+	}
+	return s, nil
+}
+
+// PublisherSites returns the sites sorted by publisher
+func (s *SQLiteComicService) PublisherSites(ctx context.Context) ([]PublisherSite, error) {
+	rows, err := s.db.QueryContext(ctx, publisherSiteSelect+" ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sites := []PublisherSite{}
+	for rows.Next() {
+		site, err := scanPublisherSite(rows)
+		if err != nil {
+			return nil, err
+		}
+		sites = append(sites, site)
+	}
+	return sites, rows.Err()
+}
+
+func (s *SQLiteComicService) PublisherSite(ctx context.Context, publisher int) (PublisherSite, error) {
+	return publisherSite(ctx, s.db, publisher)
+}
+
+// CreatePublisherSite stores the site of a publisher without one
+func (s *SQLiteComicService) CreatePublisherSite(ctx context.Context, site PublisherSite) (PublisherSite, error) {
+	site, err := site.normalize()
+	if err != nil {
+		return PublisherSite{}, err
+	}
+	created, err := insertPublisherSite(ctx, s.db, site)
+	if err != nil {
+		return PublisherSite{}, err
+	}
+	if !created {
+		return PublisherSite{}, fmt.Errorf("%w: %s", ErrPublisherSiteExists, site.Name)
+	}
+	return s.PublisherSite(ctx, site.Publisher)
+}
+
+// SeedPublisherSites stores the sites of the publishers without one and
+// returns how many were added, the sites already stored are left as they are
+func (s *SQLiteComicService) SeedPublisherSites(ctx context.Context, sites []PublisherSite) (int, error) {
+	seeded := 0
+	for _, site := range sites {
+		site, err := site.normalize()
+		if err != nil {
+			return seeded, err
+		}
+		created, err := insertPublisherSite(ctx, s.db, site)
+		if err != nil {
+			return seeded, err
+		}
+		if created {
+			seeded++
+		}
+	}
+	return seeded, nil
+}
+
+// UpdatePublisherSite replaces the site of a publisher. A new domain moves
+// the publisher: the old one joins the previous domains, and the covers of
+// its comics on any previous domain, or a subdomain of one, are rewritten to
+// the new domain. It returns the number of rewritten covers.
+func (s *SQLiteComicService) UpdatePublisherSite(ctx context.Context, site PublisherSite) (PublisherSite, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return PublisherSite{}, 0, err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	current, err := publisherSite(ctx, tx, site.Publisher)
+	if err != nil {
+		return PublisherSite{}, 0, err
+	}
+	site.PreviousDomains = append(site.PreviousDomains, current.Host())
+	site, err = site.normalize()
+	if err != nil {
+		return PublisherSite{}, 0, err
+	}
+	domains, targets, err := marshalPublisherSite(site)
+	if err != nil {
+		return PublisherSite{}, 0, err
+	}
+	if _, err := tx.ExecContext(
+		ctx,
+		`UPDATE publisher_sites SET base_url = ?, previous_domains = ?, targets = ?,
+			series_template = ?, chapter_template = ?, alive = ?, updated_at = ?
+		WHERE id = ?`,
+		site.BaseURL,
+		domains,
+		targets,
+		site.SeriesTemplate,
+		site.ChapterTemplate,
+		boolInt(site.Alive),
+		time.Now().Unix(),
+		site.Publisher,
+	); err != nil {
+		return PublisherSite{}, 0, err
+	}
+
+	rewritten := 0
+	for _, domain := range site.PreviousDomains {
+		count, err := rewriteCovers(ctx, tx, site.Publisher, domain, site.Host())
+		if err != nil {
+			return PublisherSite{}, 0, err
+		}
+		rewritten += count
+	}
+	if err := tx.Commit(); err != nil {
+		return PublisherSite{}, 0, err
+	}
+	site, err = s.PublisherSite(ctx, site.Publisher)
+	return site, rewritten, err
+}
+
+func (s *SQLiteComicService) DeletePublisherSite(ctx context.Context, publisher int) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM publisher_sites WHERE id = ?", publisher)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrPublisherSiteNotFound
+	}
+	return err
+}
+
+// rewriteCovers moves the covers of the publisher's comics on the domain to
+// the new domain. instr keeps the wildcards of LIKE out of the domain and
+// other publishers' covers are left alone, a CDN can be shared.
+func rewriteCovers(ctx context.Context, tx *sql.Tx, publisher int, from string, to string) (int, error) {
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT id, cover FROM comics
+		WHERE instr('|' || published_in || '|', ?) > 0 AND instr(lower(cover), ?) > 0`,
+		"|"+strconv.Itoa(publisher)+"|",
+		from,
+	)
+	if err != nil {
+		return 0, err
+	}
+	covers := map[int]string{}
+	for rows.Next() {
+		var id int
+		var cover string
+		if err := rows.Scan(&id, &cover); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if rewritten, ok := rewriteHost(cover, from, to); ok {
+			covers[id] = rewritten
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for id, cover := range covers {
+		if _, err := tx.ExecContext(ctx, "UPDATE comics SET cover = ? WHERE id = ?", cover, id); err != nil {
+			return 0, err
+		}
+	}
+	return len(covers), nil
+}
+
+// rewriteHost moves the URL from the domain to the new domain. Subdomains
+// are left alone, the new domain may not serve the same CDN hosts.
+func rewriteHost(raw string, from string, to string) (string, bool) {
+	parsed, err := url.Parse(raw)
+	if err != nil || strings.ToLower(parsed.Host) != from {
+		return "", false
+	}
+	parsed.Host = to
+	return parsed.String(), true
+}
+
+// publisherSiteQuerier is either the database or a transaction
+type publisherSiteQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func publisherSite(ctx context.Context, db publisherSiteQuerier, publisher int) (PublisherSite, error) {
+	site, err := scanPublisherSite(db.QueryRowContext(ctx, publisherSiteSelect+" WHERE id = ?", publisher))
+	if errors.Is(err, sql.ErrNoRows) {
+		return PublisherSite{}, ErrPublisherSiteNotFound
+	}
+	return site, err
+}
+
+// insertPublisherSite stores a normalized site, unless the publisher already
+// has one
+func insertPublisherSite(ctx context.Context, db publisherSiteQuerier, site PublisherSite) (bool, error) {
+	domains, targets, err := marshalPublisherSite(site)
+	if err != nil {
+		return false, err
+	}
+	result, err := db.ExecContext(
+		ctx,
+		`INSERT INTO publisher_sites
+			(id, base_url, previous_domains, targets, series_template, chapter_template, alive, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO NOTHING`,
+		site.Publisher,
+		site.BaseURL,
+		domains,
+		targets,
+		site.SeriesTemplate,
+		site.ChapterTemplate,
+		boolInt(site.Alive),
+		time.Now().Unix(),
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+const publisherSiteSelect = `SELECT id, base_url, previous_domains, targets, series_template,
+	chapter_template, alive, CAST(updated_at AS TEXT) FROM publisher_sites`
+
+func scanPublisherSite(row comicScanner) (PublisherSite, error) {
+	var site PublisherSite
+	var alive int
+	var domains, targets, updatedAt string
+	if err := row.Scan(
+		&site.Publisher,
+		&site.BaseURL,
+		&domains,
+		&targets,
+		&site.SeriesTemplate,
+		&site.ChapterTemplate,
+		&alive,
+		&updatedAt,
+	); err != nil {
+		return PublisherSite{}, err
+	}
+	site.Name = pb.Publisher(site.Publisher).String() // #nosec G115
+	site.Alive = alive != 0
+	if err := json.Unmarshal([]byte(domains), &site.PreviousDomains); err != nil {
+		return PublisherSite{}, err
+	}
+	if err := json.Unmarshal([]byte(targets), &site.Targets); err != nil {
+		return PublisherSite{}, err
+	}
+	site.UpdatedAt = formatLastUpdate(updatedAt)
+	return site, nil
+}
+
+func marshalPublisherSite(site PublisherSite) (string, string, error) {
+	domains, err := json.Marshal(site.PreviousDomains)
+	if err != nil {
+		return "", "", err
+	}
+	targets, err := json.Marshal(site.Targets)
+	if err != nil {
+		return "", "", err
+	}
+	return string(domains), string(targets), nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+
+	"comics/domain"
+	"comics/internal/quality"
+)
+
+// DataQuality builds the data quality report of the comics outside the trash.
+func (s *SQLiteComicService) DataQuality(ctx context.Context) (quality.Report, error) {
+	rows, err := s.db.QueryContext(ctx, baseComicSelect()+" WHERE deleted = 0")
+	if err != nil {
+		return quality.Report{}, err
+	}
+	defer rows.Close()
+	comics, err := scanComics(rows)
+	if err != nil {
+		return quality.Report{}, err
+	}
+
+	records := make([]domain.Comic, len(comics))
+	for i, comic := range comics {
+		records[i] = domain.Comic{
+			ID:           comic.ID,
+			Titles:       comic.Titles,
+			Author:       comic.Author,
+			Description:  comic.Description,
+			Cover:        comic.Cover,
+			CoverVisible: comic.CoverVisible,
+			ComType:      comic.ComType,
+			Status:       comic.Status,
+			Publishers:   comic.PublishedIn,
+			Genres:       comic.Genres,
+			Rating:       comic.Rating,
+			CurrentChap:  comic.CurrentChap,
+			ViewedChap:   comic.ViewedChap,
+			Track:        comic.Track,
+		}
+	}
+	return quality.Build(records), nil
+}
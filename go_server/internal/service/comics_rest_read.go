@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var ErrEmptySelection = errors.New("ids or at least one filter are required")
+
+// MarkRead catches up the selected comics, setting viewed_chap to
+// current_chap. Comics are selected by id when ids is not empty, by the
+// filter otherwise. Returns how many comics changed.
+func (s *SQLiteComicService) MarkRead(ctx context.Context, ids []int, filter ComicFilter) (int, error) {
+	var where string
+	var args []any
+	if len(ids) > 0 {
+		placeholders := make([]string, len(ids))
+		for i, id := range ids {
+			placeholders[i] = "?"
+			args = append(args, id)
+		}
+		where = "WHERE deleted = 0 AND id IN (" + strings.Join(placeholders, ", ") + ")"
+	} else {
+		if filter == (ComicFilter{}) {
+			return 0, ErrEmptySelection
+		}
+		selection := comicFilters("", filter)
+		where, args = selection.where, selection.args
+	}
+
+	result, err := s.db.ExecContext(
+		ctx,
+		"UPDATE comics SET viewed_chap = current_chap "+where+" AND viewed_chap != current_chap",
+		args...,
+	)
+	if err != nil {
+		return 0, err
+	}
+	affected, err := result.RowsAffected()
+	return int(affected), err
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+)
+
+// comicSchemaStatements are the tables owned by the Go service. The comics
+// table itself is managed by the Python side, so only auxiliary tables are
+// created here and every statement must be idempotent.
+var comicSchemaStatements = []string{
+	`CREATE TABLE IF NOT EXISTS chapter_events (
+		id            INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		comic_id      INTEGER NOT NULL,
+		previous_chap INTEGER NOT NULL DEFAULT 0,
+		current_chap  INTEGER NOT NULL,
+		publisher     INTEGER NOT NULL DEFAULT 0,
+		created_at    INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_chapter_events_comic
+		ON chapter_events(comic_id, created_at DESC, id DESC)`,
+	`CREATE TABLE IF NOT EXISTS comic_merges (
+		id               INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		base_id          INTEGER NOT NULL,
+		merging_id       INTEGER NOT NULL,
+		base_snapshot    TEXT    NOT NULL,
+		merging_snapshot TEXT    NOT NULL,
+		merged_snapshot  TEXT,
+		merged_at        INTEGER NOT NULL,
+		reverted_at      INTEGER
+	)`,
+	`CREATE INDEX IF NOT EXISTS idx_comic_merges_base
+		ON comic_merges(base_id, merged_at DESC)`,
+	`CREATE TABLE IF NOT EXISTS comic_trash (
+		comic_id   INTEGER NOT NULL PRIMARY KEY,
+		deleted_at INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS comic_versions (
+		comic_id INTEGER NOT NULL PRIMARY KEY,
+		version  INTEGER NOT NULL DEFAULT 0
+	)`,
+	// Scrape jobs outlive the process, see comics_rest_scrape_jobs.go
+	`CREATE TABLE IF NOT EXISTS scrape_jobs (
+		id          INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		status      TEXT    NOT NULL,
+		dry_run     INTEGER NOT NULL DEFAULT 0,
+		publishers  TEXT    NOT NULL DEFAULT '[]',
+		errors      TEXT    NOT NULL DEFAULT '[]',
+		created     INTEGER NOT NULL DEFAULT 0,
+		updated     INTEGER NOT NULL DEFAULT 0,
+		skipped     INTEGER NOT NULL DEFAULT 0,
+		created_at  INTEGER NOT NULL,
+		started_at  INTEGER,
+		finished_at INTEGER
+	)`,
+	// Sites of the publishers, see comics_rest_publishers.go
+	`CREATE TABLE IF NOT EXISTS publisher_sites (
+		id               INTEGER NOT NULL PRIMARY KEY,
+		base_url         TEXT    NOT NULL,
+		previous_domains TEXT    NOT NULL DEFAULT '[]',
+		targets          TEXT    NOT NULL DEFAULT '[]',
+		series_template  TEXT    NOT NULL DEFAULT '',
+		chapter_template TEXT    NOT NULL DEFAULT '',
+		alive            INTEGER NOT NULL DEFAULT 1,
+		updated_at       INTEGER NOT NULL
+	)`,
+	// Bumped by SQLite itself so writes from the Python side also count
+	`CREATE TRIGGER IF NOT EXISTS trg_comics_version AFTER UPDATE ON comics
+	BEGIN
+		INSERT INTO comic_versions (comic_id, version) VALUES (NEW.id, 1)
+		ON CONFLICT(comic_id) DO UPDATE SET version = version + 1;
+	END`,
+	// Full-text index over the comics table, see comics_rest_search.go
+	`CREATE VIRTUAL TABLE IF NOT EXISTS comics_fts USING fts5(
+		titles, author, description,
+		content = 'comics', content_rowid = 'id',
+		tokenize = 'unicode61 remove_diacritics 2'
+	)`,
+	`CREATE TRIGGER IF NOT EXISTS trg_comics_fts_insert AFTER INSERT ON comics
+	BEGIN
+		INSERT INTO comics_fts (rowid, titles, author, description)
+		VALUES (NEW.id, NEW.titles, NEW.author, NEW.description);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS trg_comics_fts_delete AFTER DELETE ON comics
+	BEGIN
+		INSERT INTO comics_fts (comics_fts, rowid, titles, author, description)
+		VALUES ('delete', OLD.id, OLD.titles, OLD.author, OLD.description);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS trg_comics_fts_update AFTER UPDATE OF titles, author, description ON comics
+	BEGIN
+		INSERT INTO comics_fts (comics_fts, rowid, titles, author, description)
+		VALUES ('delete', OLD.id, OLD.titles, OLD.author, OLD.description);
+		INSERT INTO comics_fts (rowid, titles, author, description)
+		VALUES (NEW.id, NEW.titles, NEW.author, NEW.description);
+	END`,
+}
+
+func ensureComicSchema(ctx context.Context, db *sql.DB) error {
+	var indexed int
+	err := db.QueryRowContext(
+		ctx,
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'comics_fts'",
+	).Scan(&indexed)
+	if err != nil {
+		return err
+	}
+	if err = ensureIdentitySchema(ctx, db); err != nil {
+		return err
+	}
+	for _, statement := range comicSchemaStatements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	if err = ensureMergeSchema(ctx, db); err != nil {
+		return err
+	}
+	if indexed == 0 {
+		// Index the comics that were stored before the triggers existed
+		_, err = db.ExecContext(ctx, "INSERT INTO comics_fts (comics_fts) VALUES ('rebuild')")
+	}
+	return err
+}
+
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+)
+
+var ErrScrapeJobNotFound = errors.New("scrape job not found")
+
+// Statuses of a scrape job and of its publishers. A job left unfinished by a
+// restart is interrupted.
+const (
+	ScrapeJobQueued      = "queued"
+	ScrapeJobRunning     = "running"
+	ScrapeJobSucceeded   = "succeeded"
+	ScrapeJobFailed      = "failed"
+	ScrapeJobCancelled   = "cancelled"
+	ScrapeJobInterrupted = "interrupted"
+)
+
+// ScrapeJobProgress is the progress of a publisher in a scrape job. Created,
+// Updated and Skipped count the comics of its pages as reported by Import,
+// merges count as updates.
+type ScrapeJobProgress struct {
+	Publisher int      `json:"publisher"`
+	Status    string   `json:"status"`
+	Pages     int      `json:"pages"`
+	PagesDone int      `json:"pages_done"`
+	Comics    int      `json:"comics"`
+	Created   int      `json:"created"`
+	Updated   int      `json:"updated"`
+	Skipped   int      `json:"skipped"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// ScrapeJob is a scrape run of the publishers, the counts are the totals of
+// the publishers
+type ScrapeJob struct {
+	ID         int                 `json:"id"`
+	Status     string              `json:"status"`
+	DryRun     bool                `json:"dry_run"`
+	Publishers []ScrapeJobProgress `json:"publishers"`
+	Created    int                 `json:"created"`
+	Updated    int                 `json:"updated"`
+	Skipped    int                 `json:"skipped"`
+	Errors     []string            `json:"errors"`
+	CreatedAt  string              `json:"created_at"`
+	StartedAt  string              `json:"started_at,omitempty"`
+	FinishedAt string              `json:"finished_at,omitempty"`
+}
+
+// Finished tells whether the job reached a final status
+func (j ScrapeJob) Finished() bool {
+	return j.Status != ScrapeJobQueued && j.Status != ScrapeJobRunning
+}
+
+// CreateScrapeJob stores a new job and returns it with its ID and creation
+// time.
+func (s *SQLiteComicService) CreateScrapeJob(ctx context.Context, job ScrapeJob) (ScrapeJob, error) {
+	publishers, jobErrors, err := marshalScrapeJob(job)
+	if err != nil {
+		return ScrapeJob{}, err
+	}
+	result, err := s.db.ExecContext(
+		ctx,
+		`INSERT INTO scrape_jobs (status, dry_run, publishers, errors, created, updated, skipped, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		job.Status,
+		boolInt(job.DryRun),
+		publishers,
+		jobErrors,
+		job.Created,
+		job.Updated,
+		job.Skipped,
+		time.Now().Unix(),
+	)
+	if err != nil {
+		return ScrapeJob{}, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return ScrapeJob{}, err
+	}
+	return s.ScrapeJob(ctx, int(id))
+}
+
+// SaveScrapeJob stores the state of a job. The start time is set when it
+// leaves the queue and the end time when it reaches a final status.
+func (s *SQLiteComicService) SaveScrapeJob(ctx context.Context, job ScrapeJob) error {
+	publishers, jobErrors, err := marshalScrapeJob(job)
+	if err != nil {
+		return err
+	}
+	now := time.Now().Unix()
+	result, err := s.db.ExecContext(
+		ctx,
+		`UPDATE scrape_jobs SET status = ?, publishers = ?, errors = ?,
+			created = ?, updated = ?, skipped = ?,
+			started_at = CASE WHEN ? THEN COALESCE(started_at, ?) ELSE started_at END,
+			finished_at = CASE WHEN ? THEN COALESCE(finished_at, ?) ELSE NULL END
+		WHERE id = ?`,
+		job.Status,
+		publishers,
+		jobErrors,
+		job.Created,
+		job.Updated,
+		job.Skipped,
+		job.Status != ScrapeJobQueued,
+		now,
+		job.Finished(),
+		now,
+		job.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrScrapeJobNotFound
+	}
+	return err
+}
+
+func (s *SQLiteComicService) ScrapeJob(ctx context.Context, id int) (ScrapeJob, error) {
+	job, err := scanScrapeJob(s.db.QueryRowContext(ctx, scrapeJobSelect+" WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ScrapeJob{}, ErrScrapeJobNotFound
+	}
+	return job, err
+}
+
+// ScrapeJobs returns the latest jobs, newest first
+func (s *SQLiteComicService) ScrapeJobs(ctx context.Context, limit int) ([]ScrapeJob, error) {
+	rows, err := s.db.QueryContext(ctx, scrapeJobSelect+" ORDER BY id DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []ScrapeJob{}
+	for rows.Next() {
+		job, err := scanScrapeJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// InterruptScrapeJobs marks the jobs left queued or running as interrupted,
+// along with their unfinished publishers. It runs at startup, when no job of
+// this process can be running yet.
+func (s *SQLiteComicService) InterruptScrapeJobs(ctx context.Context) (int, error) {
+	rows, err := s.db.QueryContext(
+		ctx,
+		scrapeJobSelect+" WHERE status IN (?, ?)",
+		ScrapeJobQueued,
+		ScrapeJobRunning,
+	)
+	if err != nil {
+		return 0, err
+	}
+	jobs := []ScrapeJob{}
+	for rows.Next() {
+		job, err := scanScrapeJob(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		jobs = append(jobs, job)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, job := range jobs {
+		job.Status = ScrapeJobInterrupted
+		for i := range job.Publishers {
+			if !slices.Contains([]string{ScrapeJobSucceeded, ScrapeJobFailed, ScrapeJobCancelled}, job.Publishers[i].Status) {
+				job.Publishers[i].Status = ScrapeJobInterrupted
+			}
+		}
+		if err := s.SaveScrapeJob(ctx, job); err != nil {
+			return 0, err
+		}
+	}
+	return len(jobs), nil
+}
+
+const scrapeJobSelect = `SELECT id, status, dry_run, publishers, errors, created, updated, skipped,
+	CAST(created_at AS TEXT), COALESCE(CAST(started_at AS TEXT), ''),
+	COALESCE(CAST(finished_at AS TEXT), '') FROM scrape_jobs`
+
+func scanScrapeJob(row comicScanner) (ScrapeJob, error) {
+	var job ScrapeJob
+	var dryRun int
+	var publishers, jobErrors, createdAt, startedAt, finishedAt string
+	if err := row.Scan(
+		&job.ID,
+		&job.Status,
+		&dryRun,
+		&publishers,
+		&jobErrors,
+		&job.Created,
+		&job.Updated,
+		&job.Skipped,
+		&createdAt,
+		&startedAt,
+		&finishedAt,
+	); err != nil {
+		return ScrapeJob{}, err
+	}
+	job.DryRun = dryRun != 0
+	if err := json.Unmarshal([]byte(publishers), &job.Publishers); err != nil {
+		return ScrapeJob{}, err
+	}
+	if err := json.Unmarshal([]byte(jobErrors), &job.Errors); err != nil {
+		return ScrapeJob{}, err
+	}
+	job.CreatedAt = formatLastUpdate(createdAt)
+	job.StartedAt = formatLastUpdate(startedAt)
+	job.FinishedAt = formatLastUpdate(finishedAt)
+	return job, nil
+}
+
+func marshalScrapeJob(job ScrapeJob) (string, string, error) {
+	if job.Publishers == nil {
+		job.Publishers = []ScrapeJobProgress{}
+	}
+	if job.Errors == nil {
+		job.Errors = []string{}
+	}
+	publishers, err := json.Marshal(job.Publishers)
+	if err != nil {
+		return "", "", err
+	}
+	jobErrors, err := json.Marshal(job.Errors)
+	if err != nil {
+		return "", "", err
+	}
+	return string(publishers), string(jobErrors), nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode"
+)
+
+var ErrRelevanceWithoutSearch = errors.New("relevance sort is only available when searching")
+
+// ftsRank weighs matches in titles above author and description, bm25 is
+// negated so that higher means more relevant like the other sort fields.
+const ftsRank = "-bm25(comics_fts, 10.0, 2.0, 1.0)"
+
+// ftsJoin restricts a comic query to the full-text matches and exposes their
+// rank as fts_rank, its only argument is the match query.
+const ftsJoin = `JOIN (
+	SELECT rowid AS fts_id, ` + ftsRank + ` AS fts_rank
+	FROM comics_fts WHERE comics_fts MATCH ?
+) AS fts ON fts.fts_id = comics.id`
+
+// ftsMatchQuery turns user input into an FTS5 query where every word must
+// appear as a word prefix. Returns an empty string when there are no words.
+func ftsMatchQuery(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, `"`+word+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// annotateMatches fills the rank and highlighted snippet of search results.
+func (s *SQLiteComicService) annotateMatches(ctx context.Context, match string, comics []ComicJSON) error {
+	if len(comics) == 0 {
+		return nil
+	}
+	byID := make(map[int]*ComicJSON, len(comics))
+	placeholders := make([]string, 0, len(comics))
+	args := []any{match}
+	for i := range comics {
+		byID[comics[i].ID] = &comics[i]
+		placeholders = append(placeholders, "?")
+		args = append(args, comics[i].ID)
+	}
+
+	rows, err := s.db.QueryContext(
+		ctx,
+		`SELECT rowid, `+ftsRank+`, snippet(comics_fts, -1, '<mark>', '</mark>', '…', 12)
+		FROM comics_fts WHERE comics_fts MATCH ? AND rowid IN (`+strings.Join(placeholders, ", ")+`)`,
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var rank float64
+		var snippet string
+		if err := rows.Scan(&id, &rank, &snippet); err != nil {
+			return err
+		}
+		if comic, ok := byID[id]; ok {
+			comic.Rank = rank
+			comic.Snippet = snippet
+		}
+	}
+	return rows.Err()
+}
@@ -34,6 +34,10 @@ type ComicJSON struct {
 	ViewedChap   int      `json:"viewed_chap"`
 	Rating       int      `json:"rating"`
 	Deleted      bool     `json:"deleted"`
+	Version      int      `json:"-"`
+	// Snippet and Rank are only set on full-text search results
+	Snippet string  `json:"snippet,omitempty"`
+	Rank    float64 `json:"-"`
 }
 
 type ComicListResult struct {
@@ -41,6 +45,7 @@ type ComicListResult struct {
 	Total       int
 	TotalPages  int
 	CurrentPage int
+	NextCursor  string
 }
 
 type SQLiteComicService struct {
@@ -62,6 +67,10 @@ func NewSQLiteComicService(path string) (*SQLiteComicService, error) {
 		db.Close()
 		return nil, err
 	}
+	if err := ensureComicSchema(context.Background(), db); err != nil {
+		db.Close()
+		return nil, err
+	}
 	return &SQLiteComicService{db: db}, nil
 }
 
@@ -90,19 +99,41 @@ func (s *SQLiteComicService) Ping(ctx context.Context) error {
 	return s.db.PingContext(ctx)
 }
 
+// ComicFilter narrows the comics returned by List and Search, zero values
+// and nil pointers disable a filter.
+type ComicFilter struct {
+	OnlyTracked   bool
+	OnlyUnchecked bool
+	Publisher     int
+	Genre         int
+	Status        *int
+	ComType       *int
+	MinRating     *int
+	MaxRating     *int
+	// UnreadOver keeps the comics with more than this many unread chapters
+	UnreadOver   *int
+	UpdatedSince time.Time
+	// IncludeDeleted also returns the comics in the trash
+	IncludeDeleted bool
+}
+
 func (s *SQLiteComicService) List(
 	ctx context.Context,
 	offset int,
 	limit int,
-	onlyTracked bool,
-	onlyUnchecked bool,
+	cursor string,
+	filter ComicFilter,
+	order ComicSort,
 	full bool,
 ) (ComicListResult, error) {
-	where, args := comicFilters("", onlyTracked, onlyUnchecked)
+	if order.Field == SortRelevance {
+		return ComicListResult{}, ErrRelevanceWithoutSearch
+	}
+	selection := comicFilters("", filter)
 	if full {
-		return s.queryComics(ctx, where, args, 0, 0)
+		return s.queryComics(ctx, selection, order, 0, 0, "")
 	}
-	return s.queryComics(ctx, where, args, offset, limit)
+	return s.queryComics(ctx, selection, order, offset, limit, cursor)
 }
 
 func (s *SQLiteComicService) Search(
@@ -110,53 +141,142 @@ func (s *SQLiteComicService) Search(
 	title string,
 	offset int,
 	limit int,
-	onlyTracked bool,
-	onlyUnchecked bool,
+	cursor string,
+	filter ComicFilter,
+	order ComicSort,
 	full bool,
 ) (ComicListResult, error) {
-	where, args := comicFilters(title, onlyTracked, onlyUnchecked)
+	selection := comicFilters(title, filter)
+	if order.Field == SortRelevance && selection.match == "" {
+		return ComicListResult{}, ErrRelevanceWithoutSearch
+	}
 	if full {
-		return s.queryComics(ctx, where, args, 0, 0)
+		return s.queryComics(ctx, selection, order, 0, 0, "")
 	}
-	return s.queryComics(ctx, where, args, offset, limit)
+	return s.queryComics(ctx, selection, order, offset, limit, cursor)
+}
+
+// comicSelection is what follows "FROM comics" in a comic query: optional
+// joins and the WHERE clause, with their arguments in order.
+type comicSelection struct {
+	where string
+	args  []any
+	// match is the full-text query the selection joins on, if any
+	match string
 }
 
-func comicFilters(title string, onlyTracked bool, onlyUnchecked bool) (string, []any) {
-	filters := []string{"deleted = 0"}
+func comicFilters(title string, filter ComicFilter) comicSelection {
+	selection := comicSelection{}
+	deleted := "deleted = 0"
+	if filter.IncludeDeleted {
+		// Keeps a first condition, queries extend the clause with AND
+		deleted = "1 = 1"
+	}
+	filters := []string{deleted}
 	args := []any{}
 	if title != "" {
-		filters = append(filters, "LOWER(titles) LIKE LOWER(?)")
-		args = append(args, "%"+title+"%")
+		selection.match = ftsMatchQuery(title)
+		if selection.match != "" {
+			selection.where = ftsJoin + " "
+			args = append(args, selection.match)
+		} else {
+			filters = append(filters, "LOWER(titles) LIKE LOWER(?)")
+			args = append(args, "%"+title+"%")
+		}
 	}
-	if onlyTracked {
+	if filter.OnlyTracked {
 		filters = append(filters, "track = 1")
 	}
-	if onlyUnchecked {
+	if filter.OnlyUnchecked {
 		filters = append(filters, "track = 1", "current_chap != viewed_chap")
 	}
-	return "WHERE " + strings.Join(filters, " AND "), args
+	if filter.Publisher != 0 {
+		filters = append(filters, "'|' || published_in || '|' LIKE ?")
+		args = append(args, "%|"+strconv.Itoa(filter.Publisher)+"|%")
+	}
+	if filter.Genre != 0 {
+		filters = append(filters, "'|' || genres || '|' LIKE ?")
+		args = append(args, "%|"+strconv.Itoa(filter.Genre)+"|%")
+	}
+	if filter.Status != nil {
+		filters = append(filters, "status = ?")
+		args = append(args, *filter.Status)
+	}
+	if filter.ComType != nil {
+		filters = append(filters, "com_type = ?")
+		args = append(args, *filter.ComType)
+	}
+	if filter.MinRating != nil {
+		filters = append(filters, "rating >= ?")
+		args = append(args, *filter.MinRating)
+	}
+	if filter.MaxRating != nil {
+		filters = append(filters, "rating <= ?")
+		args = append(args, *filter.MaxRating)
+	}
+	if filter.UnreadOver != nil {
+		filters = append(filters, "current_chap - viewed_chap > ?")
+		args = append(args, *filter.UnreadOver)
+	}
+	if !filter.UpdatedSince.IsZero() {
+		filters = append(filters, "last_update >= ?")
+		args = append(args, filter.UpdatedSince.Unix())
+	}
+	selection.where += "WHERE " + strings.Join(filters, " AND ")
+	selection.args = args
+	return selection
 }
 
+// queryComics returns a page of the selected comics. A non-empty
+// cursor continues after the comic it points at and takes precedence over
+// offset, which shifts when comics are updated between requests.
 func (s *SQLiteComicService) queryComics(
 	ctx context.Context,
-	where string,
-	args []any,
+	selection comicSelection,
+	order ComicSort,
 	offset int,
 	limit int,
+	cursor string,
 ) (ComicListResult, error) {
+	where, args := selection.where, selection.args
 	var total int
 	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM comics "+where, args...).Scan(&total); err != nil {
 		return ComicListResult{}, err
 	}
 
-	query := baseComicSelect() + " " + where + " ORDER BY last_update DESC, id"
-	queryArgs := append([]any{}, args...)
+	pageWhere := where
+	pageArgs := append([]any{}, args...)
+	if cursor != "" {
+		position, err := decodeCursor(cursor, order)
+		if err != nil {
+			return ComicListResult{}, err
+		}
+		before, beforeArgs := position.before()
+		err = s.db.QueryRowContext(
+			ctx,
+			"SELECT COUNT(*) FROM comics "+where+" AND "+before,
+			append(append([]any{}, args...), beforeArgs...)...,
+		).Scan(&offset)
+		if err != nil {
+			return ComicListResult{}, err
+		}
+		after, afterArgs := position.after()
+		pageWhere += " AND " + after
+		pageArgs = append(pageArgs, afterArgs...)
+	}
+
+	query := baseComicSelect() + " " + pageWhere + " ORDER BY " + order.orderBy()
 	if limit > 0 {
-		query += " LIMIT ? OFFSET ?"
-		queryArgs = append(queryArgs, limit, offset)
+		// One extra row tells whether there is a next page
+		query += " LIMIT ?"
+		pageArgs = append(pageArgs, limit+1)
+		if cursor == "" {
+			query += " OFFSET ?"
+			pageArgs = append(pageArgs, offset)
+		}
 	}
 
-	rows, err := s.db.QueryContext(ctx, query, queryArgs...)
+	rows, err := s.db.QueryContext(ctx, query, pageArgs...)
 	if err != nil {
 		return ComicListResult{}, err
 	}
@@ -166,18 +286,29 @@ func (s *SQLiteComicService) queryComics(
 	if err != nil {
 		return ComicListResult{}, err
 	}
+	if selection.match != "" {
+		if err := s.annotateMatches(ctx, selection.match, comics); err != nil {
+			return ComicListResult{}, err
+		}
+	}
 
 	totalPages := 1
 	currentPage := 1
+	nextCursor := ""
 	if limit > 0 {
 		totalPages = (total + limit - 1) / limit
 		currentPage = offset/limit + 1
+		if len(comics) > limit {
+			comics = comics[:limit]
+			nextCursor = encodeCursor(comics[limit-1], order)
+		}
 	}
 	return ComicListResult{
 		Comics:      comics,
 		Total:       total,
 		TotalPages:  totalPages,
 		CurrentPage: currentPage,
+		NextCursor:  nextCursor,
 	}, nil
 }
 
@@ -191,18 +322,42 @@ func (s *SQLiteComicService) Get(ctx context.Context, id int) (ComicJSON, error)
 }
 
 func (s *SQLiteComicService) Create(ctx context.Context, comic ComicJSON) (ComicJSON, error) {
-	now := time.Now().Unix()
-	result, err := s.db.ExecContext(
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	id, err := createComic(ctx, tx, comic)
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return ComicJSON{}, err
+	}
+	return s.Get(ctx, id)
+}
+
+// createComic inserts the comic, failing with an *IdentityConflictError when
+// another comic already is the same series.
+func createComic(ctx context.Context, tx *sql.Tx, comic ComicJSON) (int, error) {
+	identityKey := comicIdentityKey(comic)
+	if !comic.Deleted {
+		if err := checkIdentity(ctx, tx, identityKey); err != nil {
+			return 0, err
+		}
+	}
+	result, err := tx.ExecContext(
 		ctx,
 		`INSERT INTO comics (
 			titles, current_chap, cover, last_update, com_type, status,
 			published_in, genres, description, author, track, viewed_chap,
-			rating, deleted, cover_visible
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			rating, deleted, cover_visible, identity_key
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		strings.Join(comic.Titles, "|"),
 		comic.CurrentChap,
 		comic.Cover,
-		now,
+		time.Now().Unix(),
 		comic.ComType,
 		comic.Status,
 		joinInts(comic.PublishedIn),
@@ -214,56 +369,122 @@ func (s *SQLiteComicService) Create(ctx context.Context, comic ComicJSON) (Comic
 		comic.Rating,
 		boolInt(comic.Deleted),
 		coverVisibleOrDefault(comic),
+		identityKey,
 	)
 	if err != nil {
-		return ComicJSON{}, err
+		return 0, err
 	}
 	id, err := result.LastInsertId()
 	if err != nil {
-		return ComicJSON{}, err
+		return 0, err
 	}
-	return s.Get(ctx, int(id))
+	err = recordChapterEvent(ctx, tx, int(id), 0, comic.CurrentChap, chapterSource(nil, comic.PublishedIn))
+	return int(id), err
 }
 
 func (s *SQLiteComicService) Update(ctx context.Context, id int, patch ComicJSON) (ComicJSON, error) {
-	current, err := s.Get(ctx, id)
+	return s.UpdateWithOptions(ctx, id, patch, UpdateOptions{Source: -1})
+}
+
+// UpdateWithOptions works like Update, see UpdateOptions for the extra checks
+// and bookkeeping it supports.
+func (s *SQLiteComicService) UpdateWithOptions(
+	ctx context.Context,
+	id int,
+	patch ComicJSON,
+	options UpdateOptions,
+) (ComicJSON, error) {
+	return s.updateComic(ctx, id, options, func(current ComicJSON) (ComicJSON, error) {
+		if len(patch.Titles) > 0 {
+			current.Titles = patch.Titles
+		}
+		if patch.Cover != "" && patch.Cover != current.Cover {
+			current.Cover = patch.Cover
+			current.CoverVisible = true
+		}
+		current.CurrentChap = valueOrCurrent(patch.CurrentChap, current.CurrentChap)
+		current.ComType = valueOrCurrent(patch.ComType, current.ComType)
+		current.Status = valueOrCurrent(patch.Status, current.Status)
+		current.ViewedChap = valueOrCurrent(patch.ViewedChap, current.ViewedChap)
+		current.Rating = valueOrCurrent(patch.Rating, current.Rating)
+		if len(patch.PublishedIn) > 0 {
+			current.PublishedIn = patch.PublishedIn
+		}
+		if len(patch.Genres) > 0 {
+			current.Genres = patch.Genres
+		}
+		if patch.Description != "" {
+			current.Description = patch.Description
+		}
+		if patch.Author != "" {
+			current.Author = patch.Author
+		}
+		current.Track = patch.Track
+		return current, nil
+	})
+}
+
+// updateComic reads the comic, lets change compute the new values and writes
+// them back in a single transaction.
+func (s *SQLiteComicService) updateComic(
+	ctx context.Context,
+	id int,
+	options UpdateOptions,
+	change func(current ComicJSON) (ComicJSON, error),
+) (ComicJSON, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return ComicJSON{}, err
 	}
+	defer tx.Rollback() // nolint:errcheck
 
-	if len(patch.Titles) > 0 {
-		current.Titles = patch.Titles
+	if err = updateComicTx(ctx, tx, id, options, change); err != nil {
+		return ComicJSON{}, err
 	}
-	if patch.Cover != "" && patch.Cover != current.Cover {
-		current.Cover = patch.Cover
-		current.CoverVisible = true
+	if err = tx.Commit(); err != nil {
+		return ComicJSON{}, err
+	}
+	return s.Get(ctx, id)
+}
+
+func updateComicTx(
+	ctx context.Context,
+	tx *sql.Tx,
+	id int,
+	options UpdateOptions,
+	change func(current ComicJSON) (ComicJSON, error),
+) error {
+	previous, err := scanComic(tx.QueryRowContext(ctx, baseComicSelect()+" WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrComicNotFound
 	}
-	current.CurrentChap = valueOrCurrent(patch.CurrentChap, current.CurrentChap)
-	current.ComType = valueOrCurrent(patch.ComType, current.ComType)
-	current.Status = valueOrCurrent(patch.Status, current.Status)
-	current.ViewedChap = valueOrCurrent(patch.ViewedChap, current.ViewedChap)
-	current.Rating = valueOrCurrent(patch.Rating, current.Rating)
-	if len(patch.PublishedIn) > 0 {
-		current.PublishedIn = patch.PublishedIn
+	if err != nil {
+		return err
 	}
-	if len(patch.Genres) > 0 {
-		current.Genres = patch.Genres
+	if options.IfMatch != "" && !ETagMatches(options.IfMatch, previous) {
+		return ErrPreconditionFailed
 	}
-	if patch.Description != "" {
-		current.Description = patch.Description
+	current, err := change(previous)
+	if err != nil {
+		return err
 	}
-	if patch.Author != "" {
-		current.Author = patch.Author
+	identityKey := comicIdentityKey(current)
+	if !current.Deleted {
+		if err := checkIdentity(ctx, tx, identityKey, id); err != nil {
+			return err
+		}
 	}
-	current.Track = patch.Track
 
-	_, err = s.db.ExecContext(
+	// The version is checked again on write so a concurrent update between
+	// the read and this statement is not overwritten
+	result, err := tx.ExecContext(
 		ctx,
 		`UPDATE comics SET titles = ?, current_chap = ?, cover = ?, last_update = ?,
 			com_type = ?, status = ?, published_in = ?, genres = ?, description = ?,
 			author = ?, track = ?, viewed_chap = ?, rating = ?, deleted = ?,
-			cover_visible = ?
-		WHERE id = ?`,
+			cover_visible = ?, identity_key = ?
+		WHERE id = ?
+			AND COALESCE((SELECT version FROM comic_versions WHERE comic_id = comics.id), 0) = ?`,
 		strings.Join(current.Titles, "|"),
 		current.CurrentChap,
 		current.Cover,
@@ -279,16 +500,43 @@ func (s *SQLiteComicService) Update(ctx context.Context, id int, patch ComicJSON
 		current.Rating,
 		boolInt(current.Deleted),
 		current.CoverVisible,
+		identityKey,
 		id,
+		previous.Version,
 	)
 	if err != nil {
-		return ComicJSON{}, err
+		return err
 	}
-	return s.Get(ctx, id)
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrPreconditionFailed
+	}
+	source := options.Source
+	if source < 0 {
+		source = chapterSource(previous.PublishedIn, current.PublishedIn)
+	}
+	return recordChapterEvent(ctx, tx, id, previous.CurrentChap, current.CurrentChap, source)
 }
 
+// Delete moves a comic to the trash, see Restore and Purge.
 func (s *SQLiteComicService) Delete(ctx context.Context, id int) error {
-	result, err := s.db.ExecContext(ctx, "DELETE FROM comics WHERE id = ?", id)
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	if err = deleteComic(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func deleteComic(ctx context.Context, tx *sql.Tx, id int) error {
+	result, err := tx.ExecContext(ctx, "UPDATE comics SET deleted = 1 WHERE id = ? AND deleted = 0", id)
 	if err != nil {
 		return err
 	}
@@ -299,7 +547,13 @@ func (s *SQLiteComicService) Delete(ctx context.Context, id int) error {
 	if rows == 0 {
 		return ErrComicNotFound
 	}
-	return nil
+	_, err = tx.ExecContext(
+		ctx,
+		"INSERT OR REPLACE INTO comic_trash (comic_id, deleted_at) VALUES (?, ?)",
+		id,
+		time.Now().Unix(),
+	)
+	return err
 }
 
 func (s *SQLiteComicService) UpdateCoverVisibility(
@@ -327,42 +581,38 @@ func (s *SQLiteComicService) UpdateCoverVisibility(
 	return s.Get(ctx, id)
 }
 
-func (s *SQLiteComicService) Merge(ctx context.Context, baseID int, mergingID int) (ComicJSON, error) {
-	if baseID == mergingID {
-		return ComicJSON{}, fmt.Errorf("Comics cannot merge with themselves")
-	}
-
+// Merge merges the duplicate into the base and moves the duplicate to the
+// merge journal. The strategies override how single fields are combined, see
+// mergeFields for the defaults.
+func (s *SQLiteComicService) Merge(
+	ctx context.Context,
+	baseID int,
+	mergingID int,
+	strategies MergeStrategies,
+) (ComicJSON, error) {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return ComicJSON{}, err
 	}
 	defer tx.Rollback() // nolint:errcheck
 
-	base, err := scanComic(tx.QueryRowContext(ctx, baseComicSelect()+" WHERE id = ?", baseID))
-	if errors.Is(err, sql.ErrNoRows) {
-		return ComicJSON{}, ErrComicNotFound
-	}
+	plan, err := planMerge(ctx, tx, baseID, mergingID, strategies)
 	if err != nil {
 		return ComicJSON{}, err
 	}
-
-	duplicate, err := scanComic(tx.QueryRowContext(ctx, baseComicSelect()+" WHERE id = ?", mergingID))
-	if errors.Is(err, sql.ErrNoRows) {
-		return ComicJSON{}, ErrComicNotFound
-	}
-	if err != nil {
+	base, duplicate, merged := plan.Base, plan.Merging, plan.Merged
+	identityKey := comicIdentityKey(merged)
+	// The duplicate usually holds the same identity, it goes first so the
+	// unique index never sees both
+	if _, err = tx.ExecContext(ctx, "DELETE FROM comics WHERE id = ?", mergingID); err != nil {
 		return ComicJSON{}, err
 	}
-	if duplicate.ComType != 0 && base.ComType != duplicate.ComType {
-		return ComicJSON{}, fmt.Errorf("Comics to merge should be of the same type")
-	}
-
-	merged := mergeComicValues(base, duplicate)
 	_, err = tx.ExecContext(
 		ctx,
 		`UPDATE comics SET titles = ?, current_chap = ?, cover = ?, last_update = ?,
 			com_type = ?, status = ?, published_in = ?, genres = ?, description = ?,
-			author = ?, track = ?, viewed_chap = ?, rating = ?, cover_visible = ?
+			author = ?, track = ?, viewed_chap = ?, rating = ?, cover_visible = ?,
+			identity_key = ?
 		WHERE id = ?`,
 		strings.Join(merged.Titles, "|"),
 		merged.CurrentChap,
@@ -378,12 +628,20 @@ func (s *SQLiteComicService) Merge(ctx context.Context, baseID int, mergingID in
 		merged.ViewedChap,
 		merged.Rating,
 		merged.CoverVisible,
+		identityKey,
 		baseID,
 	)
 	if err != nil {
 		return ComicJSON{}, err
 	}
-	if _, err = tx.ExecContext(ctx, "DELETE FROM comics WHERE id = ?", mergingID); err != nil {
+	err = recordChapterEvent(
+		ctx, tx, baseID, base.CurrentChap, merged.CurrentChap,
+		chapterSource(base.PublishedIn, duplicate.PublishedIn),
+	)
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	if err = recordMerge(ctx, tx, base, duplicate, merged); err != nil {
 		return ComicJSON{}, err
 	}
 	if err = tx.Commit(); err != nil {
@@ -395,7 +653,9 @@ func (s *SQLiteComicService) Merge(ctx context.Context, baseID int, mergingID in
 func baseComicSelect() string {
 	return `SELECT id, titles, current_chap, cover, CAST(last_update AS TEXT),
 		com_type, status, published_in, genres, description, author, track,
-		viewed_chap, rating, deleted, cover_visible FROM comics`
+		viewed_chap, rating, deleted, cover_visible,
+		COALESCE((SELECT version FROM comic_versions WHERE comic_id = comics.id), 0)
+		FROM comics`
 }
 
 type comicScanner interface {
@@ -434,6 +694,7 @@ func scanComic(row comicScanner) (ComicJSON, error) {
 		&comic.Rating,
 		&comic.Deleted,
 		&comic.CoverVisible,
+		&comic.Version,
 	); err != nil {
 		return ComicJSON{}, err
 	}
@@ -511,31 +772,6 @@ func coverVisibleOrDefault(comic ComicJSON) bool {
 	return comic.CoverVisible
 }
 
-func mergeComicValues(base ComicJSON, duplicate ComicJSON) ComicJSON {
-	base.Titles = mergeStrings(base.Titles, duplicate.Titles)
-	base.PublishedIn = mergeInts(base.PublishedIn, duplicate.PublishedIn)
-	base.Genres = mergeInts(base.Genres, duplicate.Genres)
-	base.CurrentChap = max(base.CurrentChap, duplicate.CurrentChap)
-	base.ViewedChap = max(base.ViewedChap, duplicate.ViewedChap)
-	base.Track = base.Track || duplicate.Track
-	if base.Rating == 0 {
-		base.Rating = duplicate.Rating
-	}
-	if base.Author == "" {
-		base.Author = duplicate.Author
-	}
-	if base.Description == "" {
-		base.Description = duplicate.Description
-	}
-	if base.Cover == "" || !base.CoverVisible {
-		if duplicate.Cover != "" && duplicate.CoverVisible {
-			base.Cover = duplicate.Cover
-			base.CoverVisible = true
-		}
-	}
-	return base
-}
-
 func mergeStrings(current []string, incoming []string) []string {
 	seen := map[string]bool{}
 	merged := []string{}
@@ -3,10 +3,19 @@ package service
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"comics/internal/identity"
+	"comics/internal/jsonpatch"
+
 	_ "modernc.org/sqlite"
 )
 
@@ -74,7 +83,7 @@ func TestSQLiteComicServiceListAndSearch(t *testing.T) {
 		t.Fatal("expected created comic id")
 	}
 
-	result, err := service.Search(ctx, "sample", 0, 20, false, false, false)
+	result, err := service.Search(ctx, "sample", 0, 20, "", ComicFilter{}, ComicSort{}, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -150,7 +159,7 @@ func TestSQLiteComicServiceMergePrefersVisibleCover(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	merged, err := service.Merge(ctx, base.ID, duplicate.ID)
+	merged, err := service.Merge(ctx, base.ID, duplicate.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -164,3 +173,1496 @@ func TestSQLiteComicServiceMergePrefersVisibleCover(t *testing.T) {
 		t.Fatal("expected duplicate record to be deleted")
 	}
 }
+
+func TestSQLiteComicServiceHistoryRecordsChapterIncreases(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, ComicJSON{
+		Titles:      []string{"History title"},
+		CurrentChap: 5,
+		PublishedIn: []int{1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.UpdateWithOptions(ctx, created.ID, ComicJSON{CurrentChap: 7}, UpdateOptions{Source: 4}); err != nil {
+		t.Fatal(err)
+	}
+	// Same chapter again must not produce a new event
+	if _, err := service.Update(ctx, created.ID, ComicJSON{CurrentChap: 7}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Update(ctx, created.ID, ComicJSON{CurrentChap: 8, PublishedIn: []int{1, 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := service.History(ctx, created.ID, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.Total != 3 || history.TotalPages != 2 || len(history.Events) != 2 {
+		t.Fatalf("expected 3 events in 2 pages, got total=%d pages=%d len=%d",
+			history.Total, history.TotalPages, len(history.Events))
+	}
+	latest := history.Events[0]
+	if latest.PreviousChap != 7 || latest.CurrentChap != 8 || latest.Publisher != 2 {
+		t.Fatalf("unexpected latest event %+v", latest)
+	}
+	if history.Events[1].Publisher != 4 {
+		t.Fatalf("expected explicit source publisher, got %d", history.Events[1].Publisher)
+	}
+
+	if _, err := service.History(ctx, created.ID+100, 0, 20); err != ErrComicNotFound {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestSQLiteComicServiceUnmergeRestoresBothComics(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	base, err := service.Create(ctx, ComicJSON{
+		Titles:      []string{"Solo novel"},
+		CurrentChap: 40,
+		ViewedChap:  30,
+		ComType:     3,
+		PublishedIn: []int{1},
+		Genres:      []int{2},
+		Author:      "Base author",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	duplicate, err := service.Create(ctx, ComicJSON{
+		Titles:      []string{"Solo manhwa"},
+		CurrentChap: 90,
+		ViewedChap:  85,
+		ComType:     3,
+		PublishedIn: []int{3},
+		Genres:      []int{4},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Merge(ctx, base.ID, duplicate.ID, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	merges, err := service.Merges(ctx, base.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merges) != 1 || merges[0].MergingID != duplicate.ID {
+		t.Fatalf("expected one journal entry, got %+v", merges)
+	}
+
+	restored, err := service.Unmerge(ctx, base.ID, merges[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(restored.Titles) != 1 || restored.Titles[0] != "Solo novel" {
+		t.Fatalf("expected base titles rolled back, got %v", restored.Titles)
+	}
+	if restored.CurrentChap != 40 || restored.ViewedChap != 30 {
+		t.Fatalf("expected base chapters rolled back, got %d/%d", restored.ViewedChap, restored.CurrentChap)
+	}
+	if restored.PublishedIn[0] != 1 || restored.Genres[0] != 2 || len(restored.Genres) != 1 {
+		t.Fatalf("expected base publishers and genres rolled back, got %v %v",
+			restored.PublishedIn, restored.Genres)
+	}
+
+	back, err := service.Get(ctx, duplicate.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.Titles[0] != "Solo manhwa" || back.CurrentChap != 90 || back.ViewedChap != 85 {
+		t.Fatalf("expected merged comic restored, got %+v", back)
+	}
+
+	if _, err := service.Unmerge(ctx, base.ID, merges[0].ID); err != ErrMergeReverted {
+		t.Fatalf("expected already reverted error, got %v", err)
+	}
+}
+
+func TestSQLiteComicServiceUnmergeKeepsLaterChanges(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	ids := []int{}
+	for _, comic := range []ComicJSON{
+		{Titles: []string{"Omniscient reader"}, CurrentChap: 10, ComType: 3, PublishedIn: []int{1}},
+		{Titles: []string{"Jeonjijeok dokja sijeom"}, CurrentChap: 20, ComType: 3, PublishedIn: []int{2}},
+		{Titles: []string{"ORV"}, CurrentChap: 30, ComType: 3, PublishedIn: []int{3}},
+	} {
+		created, err := service.Create(ctx, comic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, created.ID)
+	}
+	base := ids[0]
+	for _, merging := range ids[1:] {
+		if _, err := service.Merge(ctx, base, merging, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	merges, err := service.Merges(ctx, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merges) != 2 || merges[0].MergingID != ids[2] {
+		t.Fatalf("expected two journal entries, newest first, got %+v", merges)
+	}
+	first, second := merges[1].ID, merges[0].ID
+
+	// Rolling back the first merge would drop the titles of the second one
+	if _, err := service.Unmerge(ctx, base, first); !errors.Is(err, ErrMergeConflict) {
+		t.Fatalf("expected a merge conflict, got %v", err)
+	}
+	if _, err := service.Get(ctx, ids[1]); !errors.Is(err, ErrComicNotFound) {
+		t.Fatalf("expected the merged comic to stay merged, got %v", err)
+	}
+
+	// Newest first both merges revert
+	restored, err := service.Unmerge(ctx, base, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.Titles, []string{"Omniscient reader", "Jeonjijeok dokja sijeom"}) {
+		t.Fatalf("expected the first merge titles, got %v", restored.Titles)
+	}
+	restored, err = service.Unmerge(ctx, base, first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(restored.Titles, []string{"Omniscient reader"}) || restored.CurrentChap != 10 {
+		t.Fatalf("expected the base rolled back, got %+v", restored)
+	}
+	for _, id := range ids[1:] {
+		if _, err := service.Get(ctx, id); err != nil {
+			t.Fatalf("expected comic %d restored: %v", id, err)
+		}
+	}
+
+	// A change made after the merge blocks the rollback too
+	if _, err := service.Merge(ctx, base, ids[1], nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Update(ctx, base, ComicJSON{Author: "Sing Shong"}); err != nil {
+		t.Fatal(err)
+	}
+	merges, err = service.Merges(ctx, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Unmerge(ctx, base, merges[0].ID); !errors.Is(err, ErrMergeConflict) {
+		t.Fatalf("expected a merge conflict, got %v", err)
+	}
+}
+
+func TestSQLiteComicServiceUnmergeSameIdentity(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	base, err := service.Create(ctx, ComicJSON{Titles: []string{"Solo Leveling"}, ComType: 3, CurrentChap: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Duplicates stored before the unique index keep an empty key
+	result, err := service.db.Exec("INSERT INTO comics (titles, last_update, com_type, current_chap) VALUES ('SOLO LEVELING', 0, 3, 20)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	duplicateID, err := result.LastInsertId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Merge(ctx, base.ID, int(duplicateID), nil); err != nil {
+		t.Fatal(err)
+	}
+	merges, err := service.Merges(ctx, base.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = service.Unmerge(ctx, base.ID, merges[0].ID)
+	var conflict *IdentityConflictError
+	if !errors.As(err, &conflict) || conflict.ConflictingID != base.ID {
+		t.Fatalf("expected an identity conflict with the base, got %v", err)
+	}
+	if _, err := service.Get(ctx, int(duplicateID)); !errors.Is(err, ErrComicNotFound) {
+		t.Fatalf("expected the failed unmerge to change nothing, got %v", err)
+	}
+
+	// Once renamed the duplicate can be restored, the base key is recomputed
+	if _, err := service.db.Exec("UPDATE comic_merges SET merging_snapshot = json_set(merging_snapshot, '$.titles', json_array('Solo Leveling Ragnarok'))"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Unmerge(ctx, base.ID, merges[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	keys := map[int]string{}
+	for _, id := range []int{base.ID, int(duplicateID)} {
+		var key string
+		if err := service.db.QueryRow("SELECT identity_key FROM comics WHERE id = ?", id).Scan(&key); err != nil {
+			t.Fatal(err)
+		}
+		keys[id] = key
+	}
+	if keys[base.ID] != "series:solo leveling" || keys[int(duplicateID)] != "series:solo leveling ragnarok" {
+		t.Fatalf("unexpected identity keys %v", keys)
+	}
+}
+
+func TestSQLiteComicServiceTrashRestoreAndPurge(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	kept, err := service.Create(ctx, ComicJSON{Titles: []string{"Kept in trash"}, CurrentChap: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	purged, err := service.Create(ctx, ComicJSON{Titles: []string{"Purged from trash"}, CurrentChap: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{kept.ID, purged.ID} {
+		if err := service.Delete(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := service.Delete(ctx, kept.ID); err != ErrComicNotFound {
+		t.Fatalf("expected trashed comic to be hidden from delete, got %v", err)
+	}
+
+	listed, err := service.List(ctx, 0, 20, "", ComicFilter{}, ComicSort{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed.Total != 0 {
+		t.Fatalf("expected trashed comics hidden from list, got %d", listed.Total)
+	}
+	trash, err := service.Trash(ctx, 0, 20, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trash.Total != 2 {
+		t.Fatalf("expected two trashed comics, got %d", trash.Total)
+	}
+
+	restored, err := service.Restore(ctx, kept.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Deleted {
+		t.Fatal("expected restored comic to leave the trash")
+	}
+	if _, err := service.Restore(ctx, kept.ID); err != ErrComicNotTrashed {
+		t.Fatalf("expected not trashed error, got %v", err)
+	}
+	if err := service.Purge(ctx, kept.ID); err != ErrComicNotTrashed {
+		t.Fatalf("expected purge to require a trashed comic, got %v", err)
+	}
+
+	count, err := service.PurgeExpired(ctx, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Fatalf("expected recent trash to be kept, purged %d", count)
+	}
+	count, err = service.PurgeExpired(ctx, -time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected one expired comic purged, got %d", count)
+	}
+	if _, err := service.Get(ctx, purged.ID); err != ErrComicNotFound {
+		t.Fatalf("expected purged comic to be gone, got %v", err)
+	}
+}
+
+func TestSQLiteComicServiceUpdateIfMatch(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, ComicJSON{Titles: []string{"Etag title"}, CurrentChap: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag := ComicETag(created)
+
+	updated, err := service.UpdateWithOptions(ctx, created.ID, ComicJSON{CurrentChap: 2}, UpdateOptions{
+		Source:  -1,
+		IfMatch: etag,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ComicETag(updated) == etag {
+		t.Fatal("expected the etag to change after an update")
+	}
+
+	// A writer holding the old etag must not clobber the newer row
+	_, err = service.UpdateWithOptions(ctx, created.ID, ComicJSON{CurrentChap: 3}, UpdateOptions{
+		Source:  -1,
+		IfMatch: etag,
+	})
+	if err != ErrPreconditionFailed {
+		t.Fatalf("expected precondition failure, got %v", err)
+	}
+	current, err := service.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.CurrentChap != 2 {
+		t.Fatalf("expected rejected update to keep chapter 2, got %d", current.CurrentChap)
+	}
+	if _, err := service.UpdateWithOptions(ctx, created.ID, ComicJSON{CurrentChap: 3}, UpdateOptions{
+		Source:  -1,
+		IfMatch: "*",
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSQLiteComicServicePatchWritesZeroValues(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	created, err := service.Create(ctx, ComicJSON{
+		Titles:      []string{"Patch title"},
+		CurrentChap: 10,
+		ViewedChap:  7,
+		Rating:      4,
+		Author:      "Someone",
+		Genres:      []int{2, 5},
+		Track:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patched, err := service.Patch(ctx, created.ID, ComicPatch{
+		Merge: map[string]any{"viewed_chap": float64(0), "author": nil, "track": false},
+	}, UpdateOptions{Source: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if patched.ViewedChap != 0 || patched.Author != "" || patched.Track {
+		t.Fatalf("expected zero values to be written, got %+v", patched)
+	}
+	if patched.CurrentChap != 10 || patched.Rating != 4 {
+		t.Fatalf("expected untouched fields to be kept, got %+v", patched)
+	}
+
+	patched, err = service.Patch(ctx, created.ID, ComicPatch{Operations: []jsonpatch.Operation{
+		{Op: "remove", Path: "/genres/0"},
+		{Op: "add", Path: "/titles/-", Value: []byte(`"Alt title"`)},
+	}}, UpdateOptions{Source: -1, IfMatch: ComicETag(patched)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patched.Genres) != 1 || patched.Genres[0] != 5 || len(patched.Titles) != 2 {
+		t.Fatalf("unexpected json patch result %+v", patched)
+	}
+
+	_, err = service.Patch(ctx, created.ID, ComicPatch{
+		Merge: map[string]any{"genres": []any{float64(1), "x"}},
+	}, UpdateOptions{Source: -1})
+	var patchErr *jsonpatch.Error
+	if !errors.As(err, &patchErr) || patchErr.Path != "/genres/1" {
+		t.Fatalf("expected an error at /genres/1, got %v", err)
+	}
+	_, err = service.Patch(ctx, created.ID, ComicPatch{
+		Merge: map[string]any{"id": float64(created.ID + 1)},
+	}, UpdateOptions{Source: -1})
+	if !errors.As(err, &patchErr) || patchErr.Path != "/id" {
+		t.Fatalf("expected an error at /id, got %v", err)
+	}
+
+	// Both kinds of patch are held to the enums and the rating bounds
+	for path, patch := range map[string]ComicPatch{
+		"/status":   {Merge: map[string]any{"status": float64(42)}},
+		"/com_type": {Merge: map[string]any{"com_type": float64(9)}},
+		"/rating": {Operations: []jsonpatch.Operation{
+			{Op: "replace", Path: "/rating", Value: []byte(`11`)},
+		}},
+	} {
+		_, err = service.Patch(ctx, created.ID, patch, UpdateOptions{Source: -1})
+		if !errors.Is(err, ErrInvalidPatch) || !errors.As(err, &patchErr) || patchErr.Path != path {
+			t.Fatalf("expected an invalid patch at %s, got %v", path, err)
+		}
+	}
+}
+
+func TestSQLiteComicServiceBatch(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	existing, err := service.Create(ctx, ComicJSON{Titles: []string{"Batch title"}, CurrentChap: 9, ViewedChap: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Atomic mode leaves nothing behind when one operation fails
+	results, err := service.Batch(ctx, []BatchOperation{
+		{Op: "create", Comic: map[string]any{"titles": []any{"New batch comic"}}},
+		{Op: "mark-read", ID: existing.ID},
+		{Op: "delete", ID: existing.ID + 100},
+	}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(results[0].Err, ErrBatchAborted) || !errors.Is(results[2].Err, ErrComicNotFound) {
+		t.Fatalf("unexpected atomic results %+v", results)
+	}
+	list, err := service.List(ctx, 0, 20, "", ComicFilter{}, ComicSort{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Total != 1 || list.Comics[0].ViewedChap != 2 {
+		t.Fatalf("expected the failed batch to be rolled back, got %+v", list.Comics)
+	}
+
+	// Per item mode keeps the operations that succeeded
+	results, err = service.Batch(ctx, []BatchOperation{
+		{Op: "create", Comic: map[string]any{"titles": []any{"New batch comic"}, "track": true}},
+		{Op: "mark-read", ID: existing.ID},
+		{Op: "update", ID: existing.ID, Comic: map[string]any{"rating": "high"}},
+		{Op: "delete", ID: existing.ID + 100},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Err != nil || results[0].Comic == nil || !results[0].Comic.CoverVisible {
+		t.Fatalf("unexpected create result %+v", results[0])
+	}
+	if results[1].Err != nil || results[1].Comic.ViewedChap != 9 {
+		t.Fatalf("unexpected mark-read result %+v", results[1])
+	}
+	if results[2].Err == nil || !errors.Is(results[3].Err, ErrComicNotFound) {
+		t.Fatalf("expected the last two operations to fail, got %+v", results[2:])
+	}
+	current, err := service.Get(ctx, existing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.ViewedChap != 9 || current.Rating != 0 {
+		t.Fatalf("expected only the mark-read to be applied, got %+v", current)
+	}
+}
+
+func TestSQLiteComicServiceMarkRead(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	comics := []ComicJSON{
+		{Titles: []string{"Read one"}, CurrentChap: 10, ViewedChap: 3, Track: true, PublishedIn: []int{2, 12}},
+		{Titles: []string{"Read two"}, CurrentChap: 8, ViewedChap: 1, Track: true, PublishedIn: []int{1}},
+		{Titles: []string{"Read three"}, CurrentChap: 5, ViewedChap: 0, PublishedIn: []int{2}},
+	}
+	for i, comic := range comics {
+		created, err := service.Create(ctx, comic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		comics[i] = created
+	}
+
+	if _, err := service.MarkRead(ctx, nil, ComicFilter{}); !errors.Is(err, ErrEmptySelection) {
+		t.Fatalf("expected an empty selection error, got %v", err)
+	}
+
+	// Publisher 2 must not match publisher 12 or 1
+	affected, err := service.MarkRead(ctx, nil, ComicFilter{OnlyTracked: true, Publisher: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected != 1 {
+		t.Fatalf("expected one comic to be marked, got %d", affected)
+	}
+
+	affected, err = service.MarkRead(ctx, []int{comics[0].ID, comics[1].ID}, ComicFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected != 1 {
+		t.Fatalf("expected only the unread comic to be counted, got %d", affected)
+	}
+	current, err := service.Get(ctx, comics[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.ViewedChap != 8 {
+		t.Fatalf("expected viewed_chap 8, got %d", current.ViewedChap)
+	}
+	current, err = service.Get(ctx, comics[2].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.ViewedChap != 0 {
+		t.Fatalf("expected untracked comic to stay unread, got %d", current.ViewedChap)
+	}
+}
+
+func TestSQLiteComicServiceListCursor(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	for _, title := range []string{"Cursor a", "Cursor b", "Cursor c", "Cursor d", "Cursor e"} {
+		if _, err := service.Create(ctx, ComicJSON{Titles: []string{title}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, err := service.List(ctx, 0, 2, "", ComicFilter{}, ComicSort{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Comics) != 2 || first.NextCursor == "" {
+		t.Fatalf("expected a full first page with a cursor, got %+v", first)
+	}
+
+	// An update moves a comic from a later page to the front, the cursor
+	// must neither repeat nor skip the remaining comics
+	moved := first.Comics[0].ID + 4
+	if _, err := service.db.Exec("UPDATE comics SET last_update = last_update + 10 WHERE id = ?", moved); err != nil {
+		t.Fatal(err)
+	}
+
+	seen := map[int]bool{first.Comics[0].ID: true, first.Comics[1].ID: true}
+	cursor := first.NextCursor
+	for cursor != "" {
+		page, err := service.List(ctx, 0, 2, cursor, ComicFilter{}, ComicSort{}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, comic := range page.Comics {
+			if seen[comic.ID] {
+				t.Fatalf("comic %d returned twice", comic.ID)
+			}
+			seen[comic.ID] = true
+		}
+		cursor = page.NextCursor
+	}
+	if len(seen) != 4 {
+		t.Fatalf("expected the 4 comics not moved ahead of the cursor, got %v", seen)
+	}
+
+	if _, err := service.List(ctx, 0, 2, "not-a-cursor", ComicFilter{}, ComicSort{}, false); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected an invalid cursor error, got %v", err)
+	}
+}
+
+func TestSQLiteComicServiceFiltersAndSort(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	comics := []ComicJSON{
+		{Titles: []string{"banana"}, Status: 1, ComType: 3, Rating: 4, CurrentChap: 20, ViewedChap: 2},
+		{Titles: []string{"Apple"}, Status: 1, ComType: 3, Rating: 2, CurrentChap: 9, ViewedChap: 8},
+		{Titles: []string{"cherry"}, Status: 2, ComType: 4, Rating: 5, CurrentChap: 12, ViewedChap: 0},
+		{Titles: []string{"Date"}, Status: 1, ComType: 3, Rating: 0, CurrentChap: 3, ViewedChap: 3},
+	}
+	for _, comic := range comics {
+		if _, err := service.Create(ctx, comic); err != nil {
+			t.Fatal(err)
+		}
+	}
+	titles := func(result ComicListResult) string {
+		names := []string{}
+		for _, comic := range result.Comics {
+			names = append(names, comic.Titles[0])
+		}
+		return strings.Join(names, ",")
+	}
+
+	status, minRating := 1, 1
+	result, err := service.List(ctx, 0, 20, "", ComicFilter{Status: &status, MinRating: &minRating}, ComicSort{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(result); got != "banana,Apple" {
+		t.Fatalf("unexpected filtered comics %s", got)
+	}
+
+	unread := 5
+	result, err = service.List(ctx, 0, 20, "", ComicFilter{UnreadOver: &unread}, ComicSort{Field: SortUnread}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(result); got != "banana,cherry" {
+		t.Fatalf("unexpected unread comics %s", got)
+	}
+
+	order, err := ParseComicSort(SortTitle, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pages []string
+	cursor := ""
+	for {
+		page, err := service.List(ctx, 0, 3, cursor, ComicFilter{}, order, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, titles(page))
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if got := strings.Join(pages, "|"); got != "Apple,banana,cherry|Date" {
+		t.Fatalf("unexpected title pages %s", got)
+	}
+
+	if _, err := service.List(ctx, 0, 3, cursor, ComicFilter{}, ComicSort{Field: SortRating}, false); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected a cursor of another sort to be rejected, got %v", err)
+	}
+	if _, err := ParseComicSort("author", ""); !errors.Is(err, ErrInvalidSort) {
+		t.Fatalf("expected an invalid sort error, got %v", err)
+	}
+}
+
+func TestSQLiteComicServiceFullTextSearch(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	leveling, err := service.Create(ctx, ComicJSON{Titles: []string{"Solo Leveling"}, Author: "Chugong"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Create(ctx, ComicJSON{
+		Titles:      []string{"Another tower"},
+		Description: "A hunter keeps leveling up alone",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Rows written outside the service are indexed by the triggers
+	if _, err := service.db.Exec(
+		"INSERT INTO comics (titles, last_update, author) VALUES ('Unrelated', 0, 'Chugong')",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := ParseComicSort(SortRelevance, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := service.Search(ctx, "level", 0, 20, "", ComicFilter{}, order, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Comics) != 2 || result.Comics[0].ID != leveling.ID {
+		t.Fatalf("expected the title match to rank first, got %+v", result.Comics)
+	}
+	if !strings.Contains(result.Comics[0].Snippet, "<mark>Leveling</mark>") {
+		t.Fatalf("expected a highlighted snippet, got %q", result.Comics[0].Snippet)
+	}
+
+	result, err = service.Search(ctx, "chugong", 0, 20, "", ComicFilter{}, ComicSort{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 2 {
+		t.Fatalf("expected author matches, got %d", result.Total)
+	}
+
+	if _, err := service.Update(ctx, leveling.ID, ComicJSON{Titles: []string{"Only I level up"}}); err != nil {
+		t.Fatal(err)
+	}
+	result, err = service.Search(ctx, "solo", 0, 20, "", ComicFilter{}, ComicSort{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 0 {
+		t.Fatalf("expected the old title to leave the index, got %+v", result.Comics)
+	}
+
+	if _, err := service.List(ctx, 0, 20, "", ComicFilter{}, order, false); !errors.Is(err, ErrRelevanceWithoutSearch) {
+		t.Fatalf("expected relevance to require a search, got %v", err)
+	}
+}
+
+func TestSQLiteComicServiceIdentityConflicts(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	leveling, err := service.Create(ctx, ComicJSON{Titles: []string{"Solo Leveling"}, ComType: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = service.Create(ctx, ComicJSON{Titles: []string{"  solo   LEVELING "}, ComType: 3})
+	var conflict *IdentityConflictError
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrIdentityConflict) {
+		t.Fatalf("expected an identity conflict, got %v", err)
+	}
+	if conflict.ConflictingID != leveling.ID || conflict.IdentityKey != "series:solo leveling" {
+		t.Fatalf("unexpected conflict %+v", conflict)
+	}
+	// The novel is a different series
+	if _, err := service.Create(ctx, ComicJSON{Titles: []string{"Solo Leveling (Novel)"}, ComType: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := service.Create(ctx, ComicJSON{Titles: []string{"Tower of God"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Update(ctx, other.ID, ComicJSON{Titles: []string{"Solo leveling", "Tower of God"}}); !errors.Is(err, ErrIdentityConflict) {
+		t.Fatalf("expected the update to conflict, got %v", err)
+	}
+	if _, err := service.Update(ctx, leveling.ID, ComicJSON{Titles: []string{"Solo leveling", "Na honjaman level up"}}); err != nil {
+		t.Fatalf("a comic never conflicts with itself: %v", err)
+	}
+
+	// Rows written without a key, like old Python versions did, get one on
+	// start up unless it would duplicate another comic
+	for _, title := range []string{"The Beginning After The End", "SOLO LEVELING"} {
+		if _, err := service.db.Exec("INSERT INTO comics (titles, last_update, com_type) VALUES (?, 0, 3)", title); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ensureIdentitySchema(ctx, service.db); err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]string{}
+	rows, err := service.db.Query("SELECT titles, identity_key FROM comics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var titles, key string
+		if err := rows.Scan(&titles, &key); err != nil {
+			t.Fatal(err)
+		}
+		keys[titles] = key
+	}
+	rows.Close()
+	if keys["The Beginning After The End"] != "series:the beginning after the end" || keys["SOLO LEVELING"] != "" {
+		t.Fatalf("unexpected backfilled keys %v", keys)
+	}
+
+	// Merging the duplicate into the original keeps the shared identity
+	var duplicateID int
+	if err := service.db.QueryRow("SELECT id FROM comics WHERE titles = 'SOLO LEVELING'").Scan(&duplicateID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Merge(ctx, leveling.ID, duplicateID, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Comics in the trash do not hold on to their identity
+	if err := service.Delete(ctx, leveling.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Create(ctx, ComicJSON{Titles: []string{"Solo leveling"}, ComType: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Restore(ctx, leveling.ID); !errors.Is(err, ErrIdentityConflict) {
+		t.Fatalf("expected the restore to conflict, got %v", err)
+	}
+}
+
+func TestSQLiteComicServiceDuplicates(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	created := []ComicJSON{}
+	for _, comic := range []ComicJSON{
+		{Titles: []string{"Solo Leveling"}, Author: "Chugong", ComType: 3},
+		{Titles: []string{"Solo leveling!"}, Author: "chugong"},
+		{Titles: []string{"Na Honjaman Level Up", "Solo Leveling"}, ComType: 3, Track: true},
+		{Titles: []string{"Solo Leveling (Novel)"}, Author: "Chugong", ComType: 4},
+		{Titles: []string{"Solo Leveling: Ragnarok"}, Author: "Chugong", ComType: 1},
+		{Titles: []string{"Tower of God"}, Author: "SIU", ComType: 3},
+	} {
+		comic, err := service.Create(ctx, comic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		created = append(created, comic)
+	}
+	leveling, exclaimed, honjaman := created[0], created[1], created[2]
+
+	groups, err := service.Duplicates(ctx, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 {
+		t.Fatalf("expected a single group, got %+v", groups)
+	}
+	group := groups[0]
+	if group.Confidence != 0.5 || len(group.Comics) != 3 {
+		t.Fatalf("unexpected group %+v", group)
+	}
+	for _, reason := range []string{DuplicateSimilarTitle, DuplicateSharedTitle, DuplicateSameAuthor, DuplicateSameType} {
+		if !slices.Contains(group.Reasons, reason) {
+			t.Fatalf("expected reason %q in %v", reason, group.Reasons)
+		}
+	}
+	// The tracked comic is kept and every merge passes the type rule
+	suggestion := group.Suggestion
+	if suggestion.BaseID != honjaman.ID || !slices.Equal(suggestion.MergingIDs, []int{leveling.ID, exclaimed.ID}) {
+		t.Fatalf("unexpected suggestion %+v", suggestion)
+	}
+	if suggestion.Requests[0] != fmt.Sprintf("PATCH /comics/%d/%d", honjaman.ID, leveling.ID) {
+		t.Fatalf("unexpected merge requests %v", suggestion.Requests)
+	}
+
+	groups, err = service.Duplicates(ctx, 0.6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 1 || groups[0].Confidence != 0.8 || groups[0].Suggestion.BaseID != leveling.ID {
+		t.Fatalf("unexpected groups %+v", groups)
+	}
+
+	// Following the suggestion merges the whole group
+	for _, mergingID := range suggestion.MergingIDs {
+		if _, err := service.Merge(ctx, suggestion.BaseID, mergingID, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	groups, err = service.Duplicates(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 0 {
+		t.Fatalf("expected no duplicates left, got %+v", groups)
+	}
+
+	// Without a threshold a novel is still never grouped with its comic
+	for _, title := range []string{"Omniscient Reader", "Omniscient Reader (Novel)"} {
+		if _, err := service.Create(ctx, ComicJSON{Titles: []string{title}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	groups, err = service.Duplicates(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 0 {
+		t.Fatalf("expected the novel to be left out, got %+v", groups)
+	}
+}
+
+func TestSQLiteComicServiceMergeStrategies(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	base, err := service.Create(ctx, ComicJSON{
+		Titles:      []string{"Base title"},
+		CurrentChap: 10,
+		ViewedChap:  4,
+		Author:      "Base author",
+		Rating:      6,
+		ComType:     3,
+		Genres:      []int{2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	duplicate, err := service.Create(ctx, ComicJSON{
+		Titles:      []string{"Duplicate title"},
+		CurrentChap: 12,
+		Author:      "Duplicate author",
+		Description: "Only the duplicate has one",
+		Rating:      9,
+		ComType:     3,
+		Genres:      []int{2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The default strategies are the historical merge rules
+	preview, err := service.PreviewMerge(ctx, base.ID, duplicate.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := preview.Merged
+	if merged.CurrentChap != 12 || merged.ViewedChap != 4 || merged.Author != "Base author" ||
+		merged.Rating != 6 || merged.Description != "Only the duplicate has one" ||
+		!slices.Equal(merged.Titles, []string{"Base title", "Duplicate title"}) {
+		t.Fatalf("unexpected merged comic %+v", merged)
+	}
+	conflicts := map[string]MergeConflict{}
+	for _, conflict := range preview.Conflicts {
+		conflicts[conflict.Field] = conflict
+	}
+	// Values only one side has, or both agree on, are not conflicts
+	for _, field := range []string{"titles", "current_chap", "author", "rating"} {
+		if _, ok := conflicts[field]; !ok {
+			t.Fatalf("expected a %s conflict in %+v", field, preview.Conflicts)
+		}
+	}
+	for _, field := range []string{"viewed_chap", "description", "genres"} {
+		if _, ok := conflicts[field]; ok {
+			t.Fatalf("unexpected %s conflict in %+v", field, preview.Conflicts)
+		}
+	}
+	if conflicts["author"].Strategy != MergePreferBase || conflicts["author"].Merged != "Base author" {
+		t.Fatalf("unexpected author conflict %+v", conflicts["author"])
+	}
+	// Previews change nothing
+	if _, err := service.Get(ctx, duplicate.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var strategies MergeStrategies
+	err = json.Unmarshal([]byte(`{
+		"author": "prefer_merging",
+		"rating": "max",
+		"titles": {"strategy": "value", "value": ["Final title", "Base title"]}
+	}`), &strategies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := service.Merge(ctx, base.ID, duplicate.ID, strategies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Author != "Duplicate author" || result.Rating != 9 ||
+		!slices.Equal(result.Titles, []string{"Final title", "Base title"}) {
+		t.Fatalf("strategies were not applied: %+v", result)
+	}
+
+	other, err := service.Create(ctx, ComicJSON{Titles: []string{"Other title"}, ComType: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, invalid := range []MergeStrategies{
+		{"com_type": {Strategy: MergePreferMerging}},
+		{"author": {Strategy: MergeMax}},
+		{"rating": {Strategy: MergeValue}},
+		{"rating": {Strategy: MergeValue, Value: json.RawMessage(`"high"`)}},
+	} {
+		if _, err := service.Merge(ctx, base.ID, other.ID, invalid); !errors.Is(err, ErrInvalidMergeStrategy) {
+			t.Fatalf("expected %v to be rejected, got %v", invalid, err)
+		}
+	}
+	// Explicit values follow the rules of a patched comic
+	for _, invalid := range []MergeStrategies{
+		{"current_chap": {Strategy: MergeValue, Value: json.RawMessage(`-1`)}},
+		{"rating": {Strategy: MergeValue, Value: json.RawMessage(`11`)}},
+		{"status": {Strategy: MergeValue, Value: json.RawMessage(`42`)}},
+		{"titles": {Strategy: MergeValue, Value: json.RawMessage(`[]`)}},
+		{"titles": {Strategy: MergeValue, Value: json.RawMessage(`["a|b"]`)}},
+		{"genres": {Strategy: MergeValue, Value: json.RawMessage(`[-3]`)}},
+	} {
+		if _, err := service.PreviewMerge(ctx, base.ID, other.ID, invalid); !errors.Is(err, ErrInvalidMergedComic) {
+			t.Fatalf("expected the preview of %v to be rejected, got %v", invalid, err)
+		}
+		if _, err := service.Merge(ctx, base.ID, other.ID, invalid); !errors.Is(err, ErrInvalidMergedComic) {
+			t.Fatalf("expected %v to be rejected, got %v", invalid, err)
+		}
+	}
+	if _, err := service.Get(ctx, other.ID); err != nil {
+		t.Fatalf("expected the rejected merges to keep the comic: %v", err)
+	}
+}
+
+func TestSQLiteComicServiceSplit(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	glued, err := service.Create(ctx, ComicJSON{
+		Titles:      []string{"Solo Leveling", "Na Honjaman Level Up", "Solo Leveling (Novel)"},
+		CurrentChap: 200,
+		ViewedChap:  150,
+		Author:      "Chugong",
+		ComType:     3,
+		PublishedIn: []int{1, 2, 3},
+		Genres:      []int{5},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	novelType, chapters := identity.NovelType, 270
+	result, err := service.Split(ctx, glued.ID, SplitJSON{
+		Titles:      []string{"solo leveling (novel)"},
+		PublishedIn: []int{3},
+		ComType:     &novelType,
+		CurrentChap: &chapters,
+	}, UpdateOptions{IfMatch: ComicETag(glued)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, split := result.Original, result.Split
+	if !slices.Equal(original.Titles, []string{"Solo Leveling", "Na Honjaman Level Up"}) ||
+		!slices.Equal(original.PublishedIn, []int{1, 2}) || original.CurrentChap != 200 {
+		t.Fatalf("unexpected original %+v", original)
+	}
+	if !slices.Equal(split.Titles, []string{"Solo Leveling (Novel)"}) || !slices.Equal(split.PublishedIn, []int{3}) ||
+		split.ComType != identity.NovelType || split.CurrentChap != 270 || split.ViewedChap != 150 ||
+		split.Author != "Chugong" || !slices.Equal(split.Genres, []int{5}) {
+		t.Fatalf("unexpected split comic %+v", split)
+	}
+
+	// Moving the primary title also moves the identity of the series
+	result, err = service.Split(ctx, original.ID, SplitJSON{Titles: []string{"Solo Leveling"}}, UpdateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Create(ctx, ComicJSON{Titles: []string{"Solo leveling"}, ComType: 3}); !errors.Is(err, ErrIdentityConflict) {
+		t.Fatalf("expected the split comic to hold the identity, got %v", err)
+	}
+
+	for _, invalid := range []SplitJSON{
+		{},
+		{Titles: []string{"Tower of God"}},
+		{Titles: []string{"Na Honjaman Level Up"}},
+		{Titles: []string{"Na Honjaman Level Up"}, PublishedIn: []int{3}},
+	} {
+		if _, err := service.Split(ctx, original.ID, invalid, UpdateOptions{}); !errors.Is(err, ErrInvalidSplit) {
+			t.Fatalf("expected %+v to be rejected, got %v", invalid, err)
+		}
+	}
+	// Nothing was written by the rejected splits
+	current, err := service.Get(ctx, original.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(current.Titles, []string{"Na Honjaman Level Up"}) {
+		t.Fatalf("unexpected titles %v", current.Titles)
+	}
+	if _, err := service.Split(ctx, original.ID, SplitJSON{Titles: []string{"x"}}, UpdateOptions{IfMatch: ComicETag(glued)}); !errors.Is(err, ErrPreconditionFailed) {
+		t.Fatalf("expected a stale etag to be rejected, got %v", err)
+	}
+}
+
+func TestSQLiteComicServiceExport(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	ids := []int{}
+	for i := range exportBatchSize + 2 {
+		comic, err := service.Create(ctx, ComicJSON{Titles: []string{fmt.Sprintf("Comic %d", i)}, ComType: 1 + i%2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, comic.ID)
+	}
+	if err := service.Delete(ctx, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	export := func(filter ComicFilter) []int {
+		t.Helper()
+		exported := []int{}
+		err := service.Export(ctx, filter, ComicSort{Field: SortTitle, Ascending: true}, func(comic ComicJSON) error {
+			exported = append(exported, comic.ID)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(exported)
+		return exported
+	}
+	// Every comic is exported once across the batches
+	if exported := export(ComicFilter{}); !slices.Equal(exported, ids[1:]) {
+		t.Fatalf("unexpected export of %d comics", len(exported))
+	}
+	if exported := export(ComicFilter{IncludeDeleted: true}); !slices.Equal(exported, ids) {
+		t.Fatalf("expected the trash in the export, got %d comics", len(exported))
+	}
+	comType := 2
+	if exported := export(ComicFilter{ComType: &comType, IncludeDeleted: true}); len(exported) != (exportBatchSize+2)/2 {
+		t.Fatalf("expected only comics of type 2, got %d", len(exported))
+	}
+
+	// A comic updated during the export is still exported once
+	exported := []int{}
+	err := service.Export(ctx, ComicFilter{}, ComicSort{}, func(comic ComicJSON) error {
+		if len(exported) == 0 {
+			_, err := service.db.ExecContext(ctx, "UPDATE comics SET last_update = last_update + 60 WHERE id = ?", ids[len(ids)-1])
+			if err != nil {
+				return err
+			}
+		}
+		exported = append(exported, comic.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(exported)
+	if !slices.Equal(exported, ids[1:]) {
+		t.Fatalf("expected every comic once despite the update, got %d comics", len(exported))
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = service.Export(ctx, ComicFilter{}, ComicSort{}, func(ComicJSON) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("expected the export to stop on the first error, got %v after %d calls", err, calls)
+	}
+
+	comic, err := service.Get(ctx, ids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := comic.Proto()
+	if message.Id != uint32(comic.ID) || message.Titles[0] != "Comic 1" || message.ComType != 2 ||
+		message.LastUpdate == nil || !message.GetCoverVisible() {
+		t.Fatalf("unexpected proto comic %v", message)
+	}
+}
+
+func TestSQLiteComicServiceImport(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	stored, err := service.Create(ctx, ComicJSON{
+		Titles: []string{"Solo Leveling"}, Author: "Chugong", ComType: 3, CurrentChap: 100, ViewedChap: 90,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tower, err := service.Create(ctx, ComicJSON{Titles: []string{"Tower of God"}, ComType: 3, CurrentChap: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := []ComicJSON{
+		// Matched by id, the row replaces the stored values
+		{ID: tower.ID, Titles: []string{"Tower of God"}, Author: "SIU", ComType: 3, CurrentChap: 550},
+		// Matched by identity, the row is merged
+		{ID: 999, Titles: []string{"solo leveling", "Na Honjaman Level Up"}, Author: "Someone else", CurrentChap: 120},
+		{Titles: []string{"Blue Lock"}, ComType: 1, CurrentChap: 200},
+		// The comic created by the previous row is matched too
+		{Titles: []string{"Blue lock"}, ComType: 2},
+		{Titles: []string{""}},
+		{ID: stored.ID, Titles: []string{"Tower of God"}},
+	}
+
+	report, err := service.Import(ctx, rows, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || report.Created != 1 || report.Updated != 1 || report.Merged != 1 || report.Skipped != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	actions := []string{}
+	for _, row := range report.Rows {
+		actions = append(actions, row.Action)
+	}
+	expected := []string{ImportUpdated, ImportMerged, ImportCreated, ImportSkipped, ImportSkipped, ImportSkipped}
+	if !slices.Equal(actions, expected) {
+		t.Fatalf("unexpected actions %v", actions)
+	}
+	merged := report.Rows[1]
+	if merged.ID != stored.ID || merged.MatchedBy != "identity" || !slices.ContainsFunc(merged.Conflicts, func(conflict MergeConflict) bool {
+		return conflict.Field == "author" && conflict.Merged == "Chugong"
+	}) {
+		t.Fatalf("unexpected merged row %+v", merged)
+	}
+	if report.Rows[3].Reason == "" || report.Rows[5].ConflictingID != tower.ID {
+		t.Fatalf("expected skip reasons, got %+v and %+v", report.Rows[3], report.Rows[5])
+	}
+
+	// The dry run wrote nothing
+	all, err := service.List(ctx, 0, 0, "", ComicFilter{}, ComicSort{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all.Total != 2 {
+		t.Fatalf("expected the dry run to leave 2 comics, got %d", all.Total)
+	}
+
+	applied, err := service.Import(ctx, rows, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied.DryRun = true
+	if !reflect.DeepEqual(applied, report) {
+		t.Fatalf("the real run differs from the dry run:\n%+v\n%+v", applied, report)
+	}
+	updated, err := service.Get(ctx, tower.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Author != "SIU" || updated.CurrentChap != 550 {
+		t.Fatalf("unexpected updated comic %+v", updated)
+	}
+	leveling, err := service.Get(ctx, stored.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leveling.Author != "Chugong" || leveling.CurrentChap != 120 || len(leveling.Titles) != 3 {
+		t.Fatalf("unexpected merged comic %+v", leveling)
+	}
+
+	// Importing the same rows again changes nothing
+	again, err := service.Import(ctx, rows[:2], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Skipped != 2 {
+		t.Fatalf("expected a second import to skip, got %+v", again)
+	}
+}
+
+func TestSQLiteComicServiceImportChecksRows(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	unknown, err := service.Create(ctx, ComicJSON{Titles: []string{"Omniscient Reader"}, CurrentChap: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := []ComicJSON{
+		// A scraped row of a known type updates the comic of unknown type
+		{Titles: []string{"Omniscient Reader"}, ComType: 3, CurrentChap: 20},
+		{Titles: []string{"Lookism|Oegeoji"}},
+		{Titles: []string{"Lookism"}, Rating: 10},
+		{Titles: []string{"Lookism"}, Status: -1},
+		{Titles: []string{"Lookism"}, ComType: 7},
+		{Titles: []string{"Lookism"}, ViewedChap: -3},
+	}
+	report, err := service.Import(ctx, rows, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Merged != 1 || report.Skipped != 5 || report.Created != 0 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	for _, row := range report.Rows[1:] {
+		if row.Action != ImportSkipped || row.Reason == "" {
+			t.Fatalf("expected row %d to be skipped with a reason, got %+v", row.Row, row)
+		}
+	}
+	updated, err := service.Get(ctx, unknown.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ComType != 3 || updated.CurrentChap != 20 {
+		t.Fatalf("expected the row to set the type, got %+v", updated)
+	}
+	all, err := service.List(ctx, 0, 0, "", ComicFilter{}, ComicSort{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all.Total != 1 {
+		t.Fatalf("expected the invalid rows to be left out, got %d comics", all.Total)
+	}
+}
+
+func TestSQLiteComicServiceScrapeJobs(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	job, err := service.CreateScrapeJob(ctx, ScrapeJob{
+		Status:     ScrapeJobQueued,
+		DryRun:     true,
+		Publishers: []ScrapeJobProgress{{Publisher: 1, Status: ScrapeJobQueued, Pages: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.ID == 0 || job.CreatedAt == "" || job.StartedAt != "" || !job.DryRun || len(job.Errors) != 0 {
+		t.Fatalf("unexpected created job %+v", job)
+	}
+
+	job.Status = ScrapeJobRunning
+	job.Publishers[0].PagesDone = 1
+	job.Publishers[0].Created = 3
+	job.Created = 3
+	job.Errors = []string{"ASURA: fetching"}
+	if err := service.SaveScrapeJob(ctx, job); err != nil {
+		t.Fatal(err)
+	}
+	finished, err := service.CreateScrapeJob(ctx, ScrapeJob{Status: ScrapeJobQueued})
+	if err != nil {
+		t.Fatal(err)
+	}
+	finished.Status = ScrapeJobSucceeded
+	if err := service.SaveScrapeJob(ctx, finished); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := service.ScrapeJob(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.StartedAt == "" || stored.FinishedAt != "" || stored.Created != 3 ||
+		stored.Publishers[0].PagesDone != 1 || !slices.Equal(stored.Errors, job.Errors) {
+		t.Fatalf("unexpected saved job %+v", stored)
+	}
+
+	// A restart interrupts the running job only
+	interrupted, err := service.InterruptScrapeJobs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if interrupted != 1 {
+		t.Fatalf("expected 1 interrupted job, got %d", interrupted)
+	}
+	jobs, err := service.ScrapeJobs(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != finished.ID || jobs[0].Status != ScrapeJobSucceeded || jobs[0].FinishedAt == "" {
+		t.Fatalf("unexpected jobs %+v", jobs)
+	}
+	if jobs[1].Status != ScrapeJobInterrupted || jobs[1].Publishers[0].Status != ScrapeJobInterrupted || jobs[1].FinishedAt == "" {
+		t.Fatalf("unexpected interrupted job %+v", jobs[1])
+	}
+
+	if _, err := service.ScrapeJob(ctx, 999); !errors.Is(err, ErrScrapeJobNotFound) {
+		t.Fatalf("expected ErrScrapeJobNotFound, got %v", err)
+	}
+	if err := service.SaveScrapeJob(ctx, ScrapeJob{ID: 999, Status: ScrapeJobRunning}); !errors.Is(err, ErrScrapeJobNotFound) {
+		t.Fatalf("expected ErrScrapeJobNotFound, got %v", err)
+	}
+}
+
+func TestSQLiteComicServicePublisherSites(t *testing.T) {
+	service := newTestComicService(t)
+	ctx := context.Background()
+
+	seeded, err := service.SeedPublisherSites(ctx, []PublisherSite{
+		{Publisher: 1, BaseURL: "https://AsuraComic.net/", Targets: []string{"https://asuracomic.net/", "page/2"}, Alive: true},
+		{Publisher: 4, BaseURL: "https://flamecomics.xyz", Alive: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seeded != 2 {
+		t.Fatalf("expected 2 seeded sites, got %d", seeded)
+	}
+	// Seeding again keeps the stored sites
+	seeded, err = service.SeedPublisherSites(ctx, []PublisherSite{{Publisher: 1, BaseURL: "https://other.net"}})
+	if err != nil || seeded != 0 {
+		t.Fatalf("expected nothing seeded, got %d, %v", seeded, err)
+	}
+
+	site, err := service.PublisherSite(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if site.Name != "ASURA" || site.BaseURL != "https://asuracomic.net" || !site.Alive ||
+		!slices.Equal(site.Targets, []string{"/", "/page/2"}) || len(site.PreviousDomains) != 0 {
+		t.Fatalf("unexpected site %+v", site)
+	}
+	if urls := site.TargetURLs(); !slices.Equal(urls, []string{"https://asuracomic.net/", "https://asuracomic.net/page/2"}) {
+		t.Fatalf("unexpected target URLs %v", urls)
+	}
+
+	if _, err := service.CreatePublisherSite(ctx, PublisherSite{Publisher: 1, BaseURL: "https://asura.gg"}); !errors.Is(err, ErrPublisherSiteExists) {
+		t.Fatalf("expected ErrPublisherSiteExists, got %v", err)
+	}
+	for _, invalid := range []PublisherSite{
+		{Publisher: 99, BaseURL: "https://asura.gg"},
+		{Publisher: 8, BaseURL: "asura.gg"},
+		{Publisher: 8, BaseURL: "ftp://asura.gg"},
+		{Publisher: 8, BaseURL: "https://asura.gg", SeriesTemplate: "{base}/series"},
+		{Publisher: 8, BaseURL: "https://asura.gg", ChapterTemplate: "{base}/series/{slug}"},
+	} {
+		if _, err := service.CreatePublisherSite(ctx, invalid); !errors.Is(err, ErrInvalidPublisherSite) {
+			t.Fatalf("expected ErrInvalidPublisherSite for %+v, got %v", invalid, err)
+		}
+	}
+	realm, err := service.CreatePublisherSite(ctx, PublisherSite{
+		Publisher:       8,
+		BaseURL:         "https://rizzfables.com",
+		SeriesTemplate:  "{base}/series/{slug}",
+		ChapterTemplate: "{base}/chapter/{slug}-chapter-{chapter}",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if realm.Alive || realm.SeriesURL("mount hua") != "https://rizzfables.com/series/mount%20hua" ||
+		realm.ChapterURL("tutorial", 12) != "https://rizzfables.com/chapter/tutorial-chapter-12" {
+		t.Fatalf("unexpected created site %+v", realm)
+	}
+
+	for _, comic := range []ComicJSON{
+		{Cover: "https://gg.asuracomic.net/storage/solo.webp", PublishedIn: []int{4, 1}},
+		{Cover: "https://asuracomic.net/storage/nano.webp", PublishedIn: []int{1}},
+		{Cover: "https://asura.gg/storage/old.webp", PublishedIn: []int{1}},
+		{Cover: "https://flamecomics.xyz/estate.jpg", PublishedIn: []int{4}},
+		{Cover: "https://notasuracomic.net/other.webp", PublishedIn: []int{1}},
+		// Another publisher's cover on a shared host stays
+		{Cover: "https://asura.gg/storage/shared.webp", PublishedIn: []int{11}},
+	} {
+		comic.Titles = []string{comic.Cover}
+		if _, err := service.Create(ctx, comic); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Asura moves, its old domain and the ones listed get rewritten but not
+	// their CDN subdomains
+	site.BaseURL = "https://asuracomic.org"
+	site.PreviousDomains = []string{"https://asura.gg/"}
+	moved, rewritten, err := service.UpdatePublisherSite(ctx, site)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rewritten != 2 {
+		t.Fatalf("expected 2 rewritten covers, got %d", rewritten)
+	}
+	if !slices.Equal(moved.PreviousDomains, []string{"asura.gg", "asuracomic.net"}) ||
+		moved.TargetURLs()[1] != "https://asuracomic.org/page/2" {
+		t.Fatalf("unexpected moved site %+v", moved)
+	}
+	rows, err := service.db.Query("SELECT cover FROM comics ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	covers := []string{}
+	for rows.Next() {
+		var cover string
+		if err := rows.Scan(&cover); err != nil {
+			t.Fatal(err)
+		}
+		covers = append(covers, cover)
+	}
+	rows.Close()
+	if !slices.Equal(covers, []string{
+		"https://gg.asuracomic.net/storage/solo.webp",
+		"https://asuracomic.org/storage/nano.webp",
+		"https://asuracomic.org/storage/old.webp",
+		"https://flamecomics.xyz/estate.jpg",
+		"https://notasuracomic.net/other.webp",
+		"https://asura.gg/storage/shared.webp",
+	}) {
+		t.Fatalf("unexpected covers %v", covers)
+	}
+
+	// Moving back drops the domain from the previous ones
+	moved.BaseURL = "https://asuracomic.net"
+	moved, _, err = service.UpdatePublisherSite(ctx, moved)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(moved.PreviousDomains, []string{"asura.gg", "asuracomic.org"}) {
+		t.Fatalf("unexpected previous domains %v", moved.PreviousDomains)
+	}
+
+	if err := service.DeletePublisherSite(ctx, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.DeletePublisherSite(ctx, 4); !errors.Is(err, ErrPublisherSiteNotFound) {
+		t.Fatalf("expected ErrPublisherSiteNotFound, got %v", err)
+	}
+	if _, _, err := service.UpdatePublisherSite(ctx, PublisherSite{Publisher: 4, BaseURL: "https://flame.gg"}); !errors.Is(err, ErrPublisherSiteNotFound) {
+		t.Fatalf("expected ErrPublisherSiteNotFound, got %v", err)
+	}
+	sites, err := service.PublisherSites(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sites) != 2 || sites[0].Publisher != 1 || sites[1].Publisher != 8 {
+		t.Fatalf("unexpected sites %+v", sites)
+	}
+}
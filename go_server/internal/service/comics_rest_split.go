@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"comics/internal/identity"
+)
+
+var ErrInvalidSplit = errors.New("invalid split")
+
+// SplitJSON selects what moves from a comic to the new one. The new comic
+// keeps the type and chapters of the original unless they are given.
+type SplitJSON struct {
+	Titles      []string `json:"titles"`
+	PublishedIn []int    `json:"published_in"`
+	ComType     *int     `json:"com_type"`
+	CurrentChap *int     `json:"current_chap"`
+	ViewedChap  *int     `json:"viewed_chap"`
+}
+
+type SplitResult struct {
+	Original ComicJSON `json:"original"`
+	Split    ComicJSON `json:"split"`
+}
+
+// Split moves the titles and publishers to a new comic, which copies the
+// remaining values of the original. The original has to keep at least one
+// title. Titles are matched ignoring case and spacing.
+func (s *SQLiteComicService) Split(
+	ctx context.Context,
+	id int,
+	split SplitJSON,
+	options UpdateOptions,
+) (SplitResult, error) {
+	if len(split.Titles) == 0 {
+		return SplitResult{}, fmt.Errorf("%w: titles to split are required", ErrInvalidSplit)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return SplitResult{}, err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	var created ComicJSON
+	// The original gives up its titles first, so the new comic can take over
+	// its identity
+	err = updateComicTx(ctx, tx, id, options, func(current ComicJSON) (ComicJSON, error) {
+		if current.Deleted {
+			return ComicJSON{}, ErrComicNotFound
+		}
+		created = current
+		created.ID = 0
+		var err error
+		if created.Titles, current.Titles, err = separateTitles(current.Titles, split.Titles); err != nil {
+			return ComicJSON{}, err
+		}
+		if created.PublishedIn, current.PublishedIn, err = separateInts(current.PublishedIn, split.PublishedIn); err != nil {
+			return ComicJSON{}, err
+		}
+		created.ComType = splitValue(split.ComType, current.ComType)
+		created.CurrentChap = splitValue(split.CurrentChap, current.CurrentChap)
+		created.ViewedChap = splitValue(split.ViewedChap, current.ViewedChap)
+		return current, nil
+	})
+	if err != nil {
+		return SplitResult{}, err
+	}
+	createdID, err := createComic(ctx, tx, created)
+	if err != nil {
+		return SplitResult{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return SplitResult{}, err
+	}
+
+	result := SplitResult{}
+	if result.Original, err = s.Get(ctx, id); err != nil {
+		return SplitResult{}, err
+	}
+	if result.Split, err = s.Get(ctx, createdID); err != nil {
+		return SplitResult{}, err
+	}
+	return result, nil
+}
+
+// titleMatch compares titles ignoring case and spacing, unlike identity keys
+// the novel marker still matters.
+func titleMatch(title string) string {
+	return strings.ToLower(identity.NormalizeText(title))
+}
+
+func splitValue(value *int, current int) int {
+	if value == nil {
+		return current
+	}
+	return *value
+}
+
+// separateTitles returns the selected titles as the comic stores them and the
+// titles left behind.
+func separateTitles(titles []string, selected []string) ([]string, []string, error) {
+	moved := []string{}
+	remaining := []string{}
+	for _, title := range titles {
+		key := titleMatch(title)
+		if slices.ContainsFunc(selected, func(value string) bool { return titleMatch(value) == key }) {
+			moved = append(moved, title)
+		} else {
+			remaining = append(remaining, title)
+		}
+	}
+	for _, title := range selected {
+		key := titleMatch(title)
+		if !slices.ContainsFunc(moved, func(value string) bool { return titleMatch(value) == key }) {
+			return nil, nil, fmt.Errorf("%w: the comic has no title %q", ErrInvalidSplit, title)
+		}
+	}
+	if len(remaining) == 0 {
+		return nil, nil, fmt.Errorf("%w: the comic must keep at least one title", ErrInvalidSplit)
+	}
+	return moved, remaining, nil
+}
+
+func separateInts(values []int, selected []int) ([]int, []int, error) {
+	moved := []int{}
+	remaining := []int{}
+	for _, value := range values {
+		if slices.Contains(selected, value) {
+			moved = append(moved, value)
+		} else {
+			remaining = append(remaining, value)
+		}
+	}
+	for _, value := range selected {
+		if !slices.Contains(values, value) {
+			return nil, nil, fmt.Errorf("%w: the comic is not published in %d", ErrInvalidSplit, value)
+		}
+	}
+	return moved, remaining, nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrComicNotTrashed = errors.New("comic is not in the trash")
+
+// Trash lists soft-deleted comics, most recently updated first.
+func (s *SQLiteComicService) Trash(
+	ctx context.Context,
+	offset int,
+	limit int,
+	full bool,
+) (ComicListResult, error) {
+	if full {
+		return s.queryComics(ctx, comicSelection{where: "WHERE deleted = 1"}, ComicSort{}, 0, 0, "")
+	}
+	return s.queryComics(ctx, comicSelection{where: "WHERE deleted = 1"}, ComicSort{}, offset, limit, "")
+}
+
+// Restore takes a comic out of the trash.
+func (s *SQLiteComicService) Restore(ctx context.Context, id int) (ComicJSON, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ComicJSON{}, err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	if err = requireTrashed(ctx, tx, id); err != nil {
+		return ComicJSON{}, err
+	}
+	// A comic created meanwhile may have taken over the identity
+	var identityKey string
+	if err = tx.QueryRowContext(ctx, "SELECT identity_key FROM comics WHERE id = ?", id).Scan(&identityKey); err != nil {
+		return ComicJSON{}, err
+	}
+	if err = checkIdentity(ctx, tx, identityKey, id); err != nil {
+		return ComicJSON{}, err
+	}
+	if _, err = tx.ExecContext(ctx, "UPDATE comics SET deleted = 0 WHERE id = ?", id); err != nil {
+		return ComicJSON{}, err
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM comic_trash WHERE comic_id = ?", id); err != nil {
+		return ComicJSON{}, err
+	}
+	if err = tx.Commit(); err != nil {
+		return ComicJSON{}, err
+	}
+	return s.Get(ctx, id)
+}
+
+// Purge permanently removes a trashed comic and its chapter history.
+func (s *SQLiteComicService) Purge(ctx context.Context, id int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	if err = requireTrashed(ctx, tx, id); err != nil {
+		return err
+	}
+	if err = purgeComics(ctx, tx, "id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PurgeExpired permanently removes comics that stayed in the trash longer
+// than the retention period and returns how many were removed.
+func (s *SQLiteComicService) PurgeExpired(ctx context.Context, retention time.Duration) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback() // nolint:errcheck
+
+	// Comics trashed outside this service have no trash record yet, their
+	// retention period starts when they are first seen here.
+	_, err = tx.ExecContext(
+		ctx,
+		`INSERT OR IGNORE INTO comic_trash (comic_id, deleted_at)
+		SELECT id, ? FROM comics WHERE deleted = 1`,
+		time.Now().Unix(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var expired int
+	cutoff := time.Now().Add(-retention).Unix()
+	err = tx.QueryRowContext(
+		ctx,
+		`SELECT COUNT(*) FROM comics WHERE deleted = 1 AND id IN (
+			SELECT comic_id FROM comic_trash WHERE deleted_at <= ?
+		)`,
+		cutoff,
+	).Scan(&expired)
+	if err != nil {
+		return 0, err
+	}
+	err = purgeComics(
+		ctx,
+		tx,
+		"deleted = 1 AND id IN (SELECT comic_id FROM comic_trash WHERE deleted_at <= ?)",
+		cutoff,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return expired, tx.Commit()
+}
+
+func requireTrashed(ctx context.Context, tx *sql.Tx, id int) error {
+	var deleted bool
+	err := tx.QueryRowContext(ctx, "SELECT deleted FROM comics WHERE id = ?", id).Scan(&deleted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrComicNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrComicNotTrashed
+	}
+	return nil
+}
+
+// purgeComics hard deletes the comics matching the condition together with
+// the rows that reference them.
+func purgeComics(ctx context.Context, exec sqlExecutor, condition string, args ...any) error {
+	statements := []string{
+		"DELETE FROM chapter_events WHERE comic_id IN (SELECT id FROM comics WHERE " + condition + ")",
+		"DELETE FROM comics WHERE " + condition,
+	}
+	for _, statement := range statements {
+		if _, err := exec.ExecContext(ctx, statement, args...); err != nil {
+			return err
+		}
+	}
+	_, err := exec.ExecContext(ctx, "DELETE FROM comic_trash WHERE comic_id NOT IN (SELECT id FROM comics)")
+	return err
+}
@@ -0,0 +1,300 @@
+// Package tachiyomi reads the .tachibk backups of Tachiyomi and Mihon and maps
+// their library onto comics.
+//
+// Backups are gzipped protobuf messages written by kotlinx.serialization.
+// Only the fields used here are decoded, see
+// app/src/main/java/eu/kanade/tachiyomi/data/backup/models in Mihon for the
+// whole schema.
+package tachiyomi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var ErrInvalidBackup = errors.New("invalid tachiyomi backup")
+
+// maxBackupSize bounds a decompressed backup, a few kilobytes of gzip could
+// otherwise take the whole memory
+var maxBackupSize = 256 << 20
+
+// Field numbers of the backup messages
+const (
+	backupMangaField      = 1
+	backupCategoriesField = 2
+	backupSourcesField    = 101
+
+	mangaSourceField      = 1
+	mangaURLField         = 2
+	mangaTitleField       = 3
+	mangaArtistField      = 4
+	mangaAuthorField      = 5
+	mangaDescriptionField = 6
+	mangaGenreField       = 7
+	mangaStatusField      = 8
+	mangaThumbnailField   = 9
+	mangaChaptersField    = 16
+	mangaCategoriesField  = 17
+	mangaFavoriteField    = 100
+
+	chapterURLField    = 1
+	chapterNameField   = 2
+	chapterReadField   = 4
+	chapterNumberField = 9
+
+	categoryNameField  = 1
+	categoryOrderField = 2
+
+	sourceNameField = 1
+	sourceIDField   = 2
+)
+
+// Backup is the part of a backup the importer needs
+type Backup struct {
+	Manga      []Manga
+	Categories []Category
+	Sources    []Source
+}
+
+// Manga is an entry of the backup, Favorite tells whether it is in the
+// library and Categories holds the Order of its categories
+type Manga struct {
+	Source      int64
+	URL         string
+	Title       string
+	Artist      string
+	Author      string
+	Description string
+	Genres      []string
+	Status      int
+	Thumbnail   string
+	Chapters    []Chapter
+	Categories  []int64
+	Favorite    bool
+}
+
+// Chapter is a chapter of a manga, Number is negative when the source did
+// not give one
+type Chapter struct {
+	URL    string
+	Name   string
+	Read   bool
+	Number float32
+}
+
+type Category struct {
+	Name  string
+	Order int64
+}
+
+type Source struct {
+	Name string
+	ID   int64
+}
+
+// Read decodes a backup, gzipped as written by the apps or already
+// decompressed. Backups over maxBackupSize once decompressed are invalid.
+func Read(r io.Reader) (*Backup, error) {
+	data, err := readLimited(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		if data, err = readLimited(reader); err != nil {
+			if errors.Is(err, ErrInvalidBackup) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+	}
+	backup, err := decodeBackup(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	return backup, nil
+}
+
+// readLimited reads r, failing with ErrInvalidBackup past maxBackupSize
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(maxBackupSize)+1))
+	if err == nil && len(data) > maxBackupSize {
+		err = fmt.Errorf("%w: larger than %d MiB", ErrInvalidBackup, maxBackupSize>>20)
+	}
+	return data, err
+}
+
+// field is a decoded field, value holds the bytes of length delimited fields
+// and number the value of the other wire types
+type field struct {
+	num    protowire.Number
+	typ    protowire.Type
+	value  []byte
+	number uint64
+}
+
+// decodeFields calls fn with every field of the message, groups are skipped
+func decodeFields(data []byte, fn func(field) error) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		current := field{num: num, typ: typ}
+		switch typ {
+		case protowire.VarintType:
+			current.number, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed32Type:
+			var value uint32
+			value, n = protowire.ConsumeFixed32(data)
+			current.number = uint64(value)
+		case protowire.Fixed64Type:
+			current.number, n = protowire.ConsumeFixed64(data)
+		case protowire.BytesType:
+			current.value, n = protowire.ConsumeBytes(data)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+		if typ == protowire.StartGroupType {
+			continue
+		}
+		if err := fn(current); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// int64s appends a repeated integer, packed or not
+func (f field) int64s(values []int64) ([]int64, error) {
+	if f.typ == protowire.VarintType {
+		return append(values, int64(f.number)), nil // #nosec G115
+	}
+	packed := f.value
+	for len(packed) > 0 {
+		value, n := protowire.ConsumeVarint(packed)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		values = append(values, int64(value)) // #nosec G115
+		packed = packed[n:]
+	}
+	return values, nil
+}
+
+func decodeBackup(data []byte) (*Backup, error) {
+	backup := &Backup{}
+	err := decodeFields(data, func(f field) error {
+		switch f.num {
+		case backupMangaField:
+			manga, err := decodeManga(f.value)
+			if err != nil {
+				return err
+			}
+			backup.Manga = append(backup.Manga, manga)
+		case backupCategoriesField:
+			category := Category{}
+			err := decodeFields(f.value, func(f field) error {
+				switch f.num {
+				case categoryNameField:
+					category.Name = string(f.value)
+				case categoryOrderField:
+					category.Order = int64(f.number) // #nosec G115
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			backup.Categories = append(backup.Categories, category)
+		case backupSourcesField:
+			source := Source{}
+			err := decodeFields(f.value, func(f field) error {
+				switch f.num {
+				case sourceNameField:
+					source.Name = string(f.value)
+				case sourceIDField:
+					source.ID = int64(f.number) // #nosec G115
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			backup.Sources = append(backup.Sources, source)
+		}
+		return nil
+	})
+	return backup, err
+}
+
+func decodeManga(data []byte) (Manga, error) {
+	// favorite defaults to true and is left out when it has that value
+	manga := Manga{Favorite: true}
+	err := decodeFields(data, func(f field) error {
+		var err error
+		switch f.num {
+		case mangaSourceField:
+			manga.Source = int64(f.number) // #nosec G115
+		case mangaURLField:
+			manga.URL = string(f.value)
+		case mangaTitleField:
+			manga.Title = string(f.value)
+		case mangaArtistField:
+			manga.Artist = string(f.value)
+		case mangaAuthorField:
+			manga.Author = string(f.value)
+		case mangaDescriptionField:
+			manga.Description = string(f.value)
+		case mangaGenreField:
+			manga.Genres = append(manga.Genres, string(f.value))
+		case mangaStatusField:
+			manga.Status = int(f.number) // #nosec G115
+		case mangaThumbnailField:
+			manga.Thumbnail = string(f.value)
+		case mangaChaptersField:
+			var chapter Chapter
+			chapter, err = decodeChapter(f.value)
+			manga.Chapters = append(manga.Chapters, chapter)
+		case mangaCategoriesField:
+			manga.Categories, err = f.int64s(manga.Categories)
+		case mangaFavoriteField:
+			manga.Favorite = f.number != 0
+		}
+		return err
+	})
+	return manga, err
+}
+
+func decodeChapter(data []byte) (Chapter, error) {
+	chapter := Chapter{Number: -1}
+	err := decodeFields(data, func(f field) error {
+		switch f.num {
+		case chapterURLField:
+			chapter.URL = string(f.value)
+		case chapterNameField:
+			chapter.Name = string(f.value)
+		case chapterReadField:
+			chapter.Read = f.number != 0
+		case chapterNumberField:
+			chapter.Number = math.Float32frombits(uint32(f.number)) // #nosec G115
+		}
+		return nil
+	})
+	return chapter, err
+}
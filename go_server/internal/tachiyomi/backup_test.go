@@ -0,0 +1,179 @@
+package tachiyomi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"math"
+	"testing"
+
+	"comics/internal/service"
+	"comics/pkg/pb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func appendVarint(b []byte, num protowire.Number, value uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+func appendMessage(b []byte, num protowire.Number, message []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, message)
+}
+
+func chapter(number float32, read bool) []byte {
+	b := appendString(nil, chapterURLField, "/chapter")
+	if read {
+		b = appendVarint(b, chapterReadField, 1)
+	}
+	b = protowire.AppendTag(b, chapterNumberField, protowire.Fixed32Type)
+	return protowire.AppendFixed32(b, math.Float32bits(number))
+}
+
+// testBackup has a manga in the library from a known source, another one
+// only in the history from an unknown source and a source without numbered
+// chapters
+func testBackup() []byte {
+	var solo []byte
+	solo = appendVarint(solo, mangaSourceField, 1)
+	solo = appendString(solo, mangaTitleField, " Solo Leveling ")
+	solo = appendString(solo, mangaArtistField, "Dubu")
+	solo = appendString(solo, mangaAuthorField, "Chugong")
+	solo = appendString(solo, mangaDescriptionField, "Hunters")
+	solo = appendString(solo, mangaGenreField, "Action")
+	solo = appendString(solo, mangaGenreField, "Martial Arts")
+	solo = appendString(solo, mangaGenreField, "Isekai")
+	solo = appendVarint(solo, mangaStatusField, statusCompleted)
+	solo = appendString(solo, mangaThumbnailField, "https://asura.test/solo.webp")
+	solo = appendMessage(solo, mangaChaptersField, chapter(1, true))
+	solo = appendMessage(solo, mangaChaptersField, chapter(2.5, true))
+	solo = appendMessage(solo, mangaChaptersField, chapter(3, false))
+	packed := protowire.AppendVarint(protowire.AppendVarint(nil, 0), 1)
+	solo = appendMessage(solo, mangaCategoriesField, packed)
+
+	var tower []byte
+	tower = appendVarint(tower, mangaSourceField, math.MaxUint64)
+	tower = appendString(tower, mangaTitleField, "Tower of God")
+	tower = appendString(tower, mangaArtistField, "SIU")
+	tower = appendVarint(tower, mangaStatusField, statusOnHiatus)
+	tower = appendMessage(tower, mangaChaptersField, chapter(-1, true))
+	tower = appendMessage(tower, mangaChaptersField, chapter(-1, false))
+	tower = appendVarint(tower, mangaCategoriesField, 2)
+	tower = appendVarint(tower, mangaFavoriteField, 0)
+
+	var backup []byte
+	backup = appendMessage(backup, backupMangaField, solo)
+	backup = appendMessage(backup, backupMangaField, tower)
+	for order, name := range []string{"Reading", "Manhwa", "Comedy"} {
+		category := appendString(nil, categoryNameField, name)
+		category = appendVarint(category, categoryOrderField, uint64(order))
+		backup = appendMessage(backup, backupCategoriesField, category)
+	}
+	source := appendString(nil, sourceNameField, "Asura Scans (EN)")
+	source = appendVarint(source, sourceIDField, 1)
+	backup = appendMessage(backup, backupSourcesField, source)
+	source = appendString(nil, sourceNameField, "MangaDex")
+	source = appendVarint(source, sourceIDField, math.MaxUint64)
+	return appendMessage(backup, backupSourcesField, source)
+}
+
+func TestRead(t *testing.T) {
+	raw := testBackup()
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err := writer.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	for name, data := range map[string][]byte{"gzip": compressed.Bytes(), "raw": raw} {
+		t.Run(name, func(t *testing.T) {
+			backup, err := Read(bytes.NewReader(data))
+			require.NoError(t, err)
+			require.Len(t, backup.Manga, 2)
+			assert.Equal(t, []int64{0, 1}, backup.Manga[0].Categories)
+			assert.True(t, backup.Manga[0].Favorite)
+			assert.Equal(t, float32(2.5), backup.Manga[0].Chapters[1].Number)
+			assert.Equal(t, int64(-1), backup.Manga[1].Source)
+			assert.False(t, backup.Manga[1].Favorite)
+			assert.Len(t, backup.Categories, 3)
+			assert.Equal(t, []Source{{Name: "Asura Scans (EN)", ID: 1}, {Name: "MangaDex", ID: -1}}, backup.Sources)
+		})
+	}
+
+	_, err = Read(bytes.NewReader([]byte{0x1f, 0x8b, 0x00}))
+	assert.ErrorIs(t, err, ErrInvalidBackup)
+	_, err = Read(bytes.NewReader([]byte{0x0a, 0x05}))
+	assert.ErrorIs(t, err, ErrInvalidBackup)
+}
+
+func TestReadTooLarge(t *testing.T) {
+	defer func(size int) { maxBackupSize = size }(maxBackupSize)
+	maxBackupSize = 1 << 20
+
+	var bomb bytes.Buffer
+	writer := gzip.NewWriter(&bomb)
+	_, err := writer.Write(make([]byte, maxBackupSize+1))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	require.Less(t, bomb.Len(), 4096)
+
+	_, err = Read(&bomb)
+	assert.ErrorIs(t, err, ErrInvalidBackup)
+	_, err = Read(bytes.NewReader(make([]byte, maxBackupSize+1)))
+	assert.ErrorIs(t, err, ErrInvalidBackup)
+}
+
+func TestBackupComics(t *testing.T) {
+	backup, err := Read(bytes.NewReader(testBackup()))
+	require.NoError(t, err)
+
+	comics, unknown := backup.Comics()
+	assert.Equal(t, []string{"MangaDex"}, unknown)
+	assert.Equal(t, []service.ComicJSON{
+		{
+			Titles:       []string{"Solo Leveling"},
+			Author:       "Chugong",
+			Description:  "Hunters",
+			ComType:      int(pb.ComicType_MANHWA),
+			Status:       int(pb.Status_COMPLETED),
+			Cover:        "https://asura.test/solo.webp",
+			CoverVisible: true,
+			CurrentChap:  3,
+			ViewedChap:   2,
+			PublishedIn:  []int{int(pb.Publisher_ASURA)},
+			Genres:       []int{int(pb.Genre_ACTION), int(pb.Genre_MARTIAL_ARTS)},
+			Track:        true,
+		},
+		{
+			Titles:       []string{"Tower of God"},
+			Author:       "SIU",
+			Status:       int(pb.Status_BREAK),
+			CoverVisible: true,
+			CurrentChap:  2,
+			ViewedChap:   1,
+			PublishedIn:  []int{},
+			Genres:       []int{int(pb.Genre_COMEDY)},
+		},
+	}, comics)
+}
+
+func TestNameKey(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Asura Scans (EN)": "ASURA",
+		"REAPER_SCANS":     "REAPER",
+		"Isekai Scan":      "ISEKAI",
+		"ManhuaPlus":       "MANHUAPLUS",
+		"Scans":            "SCANS",
+		"Martial Arts":     "MARTIALARTS",
+	} {
+		assert.Equal(t, expected, nameKey(name), name)
+	}
+}
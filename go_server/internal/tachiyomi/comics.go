@@ -0,0 +1,162 @@
+package tachiyomi
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"comics/internal/service"
+	"comics/pkg/pb"
+)
+
+// Manga statuses of the apps
+const (
+	statusOngoing            = 1
+	statusCompleted          = 2
+	statusPublishingFinished = 4
+	statusCancelled          = 5
+	statusOnHiatus           = 6
+)
+
+var statuses = map[int]pb.Status{
+	statusOngoing:            pb.Status_ON_AIR,
+	statusCompleted:          pb.Status_COMPLETED,
+	statusPublishingFinished: pb.Status_COMPLETED,
+	statusCancelled:          pb.Status_DROPPED,
+	statusOnHiatus:           pb.Status_BREAK,
+}
+
+// enumKeys indexes enum values by nameKey of their names
+func enumKeys(names map[string]int32) map[string]int {
+	keys := make(map[string]int, len(names))
+	for name, value := range names {
+		if value != 0 {
+			keys[nameKey(name)] = int(value)
+		}
+	}
+	return keys
+}
+
+var (
+	publisherKeys = enumKeys(pb.Publisher_value)
+	genreKeys     = enumKeys(pb.Genre_value)
+	comicTypeKeys = enumKeys(pb.ComicType_value)
+)
+
+// nameKey reduces a name to its upper case letters and digits, leaving out a
+// trailing language like "(EN)" and a trailing "Scans" or "Scan", so
+// "Asura Scans (EN)" and ASURA match.
+func nameKey(name string) string {
+	if i := strings.LastIndex(name, "("); i > 0 && strings.HasSuffix(strings.TrimSpace(name), ")") {
+		name = name[:i]
+	}
+	key := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return -1
+	}, name)
+	for _, suffix := range []string{"SCANS", "SCAN"} {
+		if trimmed, ok := strings.CutSuffix(key, suffix); ok && trimmed != "" {
+			return trimmed
+		}
+	}
+	return key
+}
+
+// Comics maps the manga of the backup onto comics, in the order of the
+// backup. The names of the sources that match no publisher are returned
+// sorted, their manga are still mapped without a publisher.
+func (b *Backup) Comics() ([]service.ComicJSON, []string) {
+	sources := make(map[int64]string, len(b.Sources))
+	for _, source := range b.Sources {
+		sources[source.ID] = source.Name
+	}
+	categories := make(map[int64]string, len(b.Categories))
+	for _, category := range b.Categories {
+		categories[category.Order] = category.Name
+	}
+
+	comics := make([]service.ComicJSON, 0, len(b.Manga))
+	unknown := []string{}
+	for _, manga := range b.Manga {
+		comic := manga.comic(categories)
+		name, ok := sources[manga.Source]
+		if !ok {
+			name = "unknown source"
+		}
+		if publisher, ok := publisherKeys[nameKey(name)]; ok {
+			comic.PublishedIn = []int{publisher}
+		} else if !slices.Contains(unknown, name) {
+			unknown = append(unknown, name)
+		}
+		comics = append(comics, comic)
+	}
+	slices.Sort(unknown)
+	return comics, unknown
+}
+
+// comic maps the manga without its source. The type comes from a genre or a
+// category named after it, the current chapter from the highest chapter and
+// the viewed one from the highest read chapter.
+func (m Manga) comic(categories map[int64]string) service.ComicJSON {
+	comic := service.ComicJSON{
+		Author:       strings.TrimSpace(m.Author),
+		Description:  strings.TrimSpace(m.Description),
+		Cover:        m.Thumbnail,
+		CoverVisible: true,
+		Status:       int(statuses[m.Status]),
+		Track:        m.Favorite,
+		Genres:       []int{},
+		PublishedIn:  []int{},
+	}
+	if title := strings.TrimSpace(m.Title); title != "" {
+		comic.Titles = []string{title}
+	}
+	if comic.Author == "" {
+		comic.Author = strings.TrimSpace(m.Artist)
+	}
+
+	names := slices.Clone(m.Genres)
+	for _, order := range m.Categories {
+		names = append(names, categories[order])
+	}
+	for _, name := range names {
+		key := nameKey(name)
+		if genre, ok := genreKeys[key]; ok && !slices.Contains(comic.Genres, genre) {
+			comic.Genres = append(comic.Genres, genre)
+		}
+		if comType, ok := comicTypeKeys[key]; ok && comic.ComType == 0 {
+			comic.ComType = comType
+		}
+	}
+
+	comic.CurrentChap, comic.ViewedChap = chapters(m.Chapters)
+	return comic
+}
+
+// chapters returns the highest chapter and the highest read chapter. When
+// the source gives no numbers the chapters are counted instead.
+func chapters(list []Chapter) (current int, viewed int) {
+	numbered := false
+	read := 0
+	for _, chapter := range list {
+		if chapter.Read {
+			read++
+		}
+		if chapter.Number < 0 || math.IsNaN(float64(chapter.Number)) {
+			continue
+		}
+		numbered = true
+		number := int(chapter.Number)
+		current = max(current, number)
+		if chapter.Read {
+			viewed = max(viewed, number)
+		}
+	}
+	if !numbered {
+		return len(list), read
+	}
+	return current, viewed
+}
@@ -123,6 +123,10 @@ func (m *ComicResponse) validate(all bool) error {
 		// no validation rules for Error
 	}
 
+	if m.Etag != nil {
+		// no validation rules for Etag
+	}
+
 	if len(errors) > 0 {
 		return ComicResponseMultiError(errors)
 	}
@@ -302,6 +306,10 @@ func (m *ComicsResponse) validate(all bool) error {
 		// no validation rules for Error
 	}
 
+	if m.NextPageToken != nil {
+		// no validation rules for NextPageToken
+	}
+
 	if len(errors) > 0 {
 		return ComicsResponseMultiError(errors)
 	}
@@ -870,6 +878,10 @@ func (m *UpdateComicRequest) validate(all bool) error {
 		}
 	}
 
+	if m.IfMatch != nil {
+		// no validation rules for IfMatch
+	}
+
 	if len(errors) > 0 {
 		return UpdateComicRequestMultiError(errors)
 	}
@@ -1145,6 +1157,10 @@ func (m *PaginationRequest) validate(all bool) error {
 		errors = append(errors, err)
 	}
 
+	if m.PageToken != nil {
+		// no validation rules for PageToken
+	}
+
 	if len(errors) > 0 {
 		return PaginationRequestMultiError(errors)
 	}
@@ -1578,6 +1594,290 @@ var _ interface {
 	ErrorName() string
 } = SearchComicsRequestValidationError{}
 
+// Validate checks the field values on MarkComicsReadRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *MarkComicsReadRequest) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on MarkComicsReadRequest with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// MarkComicsReadRequestMultiError, or nil if none found.
+func (m *MarkComicsReadRequest) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *MarkComicsReadRequest) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetMetadata()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, MarkComicsReadRequestValidationError{
+					field:  "Metadata",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, MarkComicsReadRequestValidationError{
+					field:  "Metadata",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetMetadata()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return MarkComicsReadRequestValidationError{
+				field:  "Metadata",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if m.TrackedOnly != nil {
+		// no validation rules for TrackedOnly
+	}
+
+	if m.UncheckedOnly != nil {
+		// no validation rules for UncheckedOnly
+	}
+
+	if m.Publisher != nil {
+		// no validation rules for Publisher
+	}
+
+	if m.Genre != nil {
+		// no validation rules for Genre
+	}
+
+	if len(errors) > 0 {
+		return MarkComicsReadRequestMultiError(errors)
+	}
+
+	return nil
+}
+
+// MarkComicsReadRequestMultiError is an error wrapping multiple validation
+// errors returned by MarkComicsReadRequest.ValidateAll() if the designated
+// constraints aren't met.
+type MarkComicsReadRequestMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m MarkComicsReadRequestMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m MarkComicsReadRequestMultiError) AllErrors() []error { return m }
+
+// MarkComicsReadRequestValidationError is the validation error returned by
+// MarkComicsReadRequest.Validate if the designated constraints aren't met.
+type MarkComicsReadRequestValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e MarkComicsReadRequestValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e MarkComicsReadRequestValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e MarkComicsReadRequestValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e MarkComicsReadRequestValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e MarkComicsReadRequestValidationError) ErrorName() string {
+	return "MarkComicsReadRequestValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e MarkComicsReadRequestValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sMarkComicsReadRequest.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = MarkComicsReadRequestValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = MarkComicsReadRequestValidationError{}
+
+// Validate checks the field values on MarkComicsReadResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *MarkComicsReadResponse) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on MarkComicsReadResponse with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// MarkComicsReadResponseMultiError, or nil if none found.
+func (m *MarkComicsReadResponse) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *MarkComicsReadResponse) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if all {
+		switch v := interface{}(m.GetMetadata()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, MarkComicsReadResponseValidationError{
+					field:  "Metadata",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, MarkComicsReadResponseValidationError{
+					field:  "Metadata",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetMetadata()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return MarkComicsReadResponseValidationError{
+				field:  "Metadata",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	// no validation rules for Affected
+
+	if m.Error != nil {
+		// no validation rules for Error
+	}
+
+	if len(errors) > 0 {
+		return MarkComicsReadResponseMultiError(errors)
+	}
+
+	return nil
+}
+
+// MarkComicsReadResponseMultiError is an error wrapping multiple validation
+// errors returned by MarkComicsReadResponse.ValidateAll() if the designated
+// constraints aren't met.
+type MarkComicsReadResponseMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m MarkComicsReadResponseMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m MarkComicsReadResponseMultiError) AllErrors() []error { return m }
+
+// MarkComicsReadResponseValidationError is the validation error returned by
+// MarkComicsReadResponse.Validate if the designated constraints aren't met.
+type MarkComicsReadResponseValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e MarkComicsReadResponseValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e MarkComicsReadResponseValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e MarkComicsReadResponseValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e MarkComicsReadResponseValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e MarkComicsReadResponseValidationError) ErrorName() string {
+	return "MarkComicsReadResponseValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e MarkComicsReadResponseValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sMarkComicsReadResponse.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = MarkComicsReadResponseValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = MarkComicsReadResponseValidationError{}
+
 // Validate checks the field values on RequestMetadata with the rules defined
 // in the proto definition for this message. If any rules are violated, the
 // first error encountered is returned, or nil if there are no violations.
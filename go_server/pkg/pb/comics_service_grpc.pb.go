@@ -26,6 +26,7 @@ const (
 	ComicService_GetComicByTitle_FullMethodName = "/comics.ComicService/GetComicByTitle"
 	ComicService_GetComics_FullMethodName       = "/comics.ComicService/GetComics"
 	ComicService_SearchComics_FullMethodName    = "/comics.ComicService/SearchComics"
+	ComicService_MarkComicsRead_FullMethodName  = "/comics.ComicService/MarkComicsRead"
 )
 
 // ComicServiceClient is the client API for ComicService service.
@@ -56,6 +57,9 @@ type ComicServiceClient interface {
 	// Searches comics using fuzzy title matching
 	// Returns paginated results ordered by relevance
 	SearchComics(ctx context.Context, in *SearchComicsRequest, opts ...grpc.CallOption) (*ComicsResponse, error)
+	// Sets viewed_chap to current_chap on the selected comics
+	// Returns how many comics were caught up
+	MarkComicsRead(ctx context.Context, in *MarkComicsReadRequest, opts ...grpc.CallOption) (*MarkComicsReadResponse, error)
 }
 
 type comicServiceClient struct {
@@ -136,6 +140,16 @@ func (c *comicServiceClient) SearchComics(ctx context.Context, in *SearchComicsR
 	return out, nil
 }
 
+func (c *comicServiceClient) MarkComicsRead(ctx context.Context, in *MarkComicsReadRequest, opts ...grpc.CallOption) (*MarkComicsReadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MarkComicsReadResponse)
+	err := c.cc.Invoke(ctx, ComicService_MarkComicsRead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ComicServiceServer is the server API for ComicService service.
 // All implementations must embed UnimplementedComicServiceServer
 // for forward compatibility.
@@ -164,6 +178,9 @@ type ComicServiceServer interface {
 	// Searches comics using fuzzy title matching
 	// Returns paginated results ordered by relevance
 	SearchComics(context.Context, *SearchComicsRequest) (*ComicsResponse, error)
+	// Sets viewed_chap to current_chap on the selected comics
+	// Returns how many comics were caught up
+	MarkComicsRead(context.Context, *MarkComicsReadRequest) (*MarkComicsReadResponse, error)
 	mustEmbedUnimplementedComicServiceServer()
 }
 
@@ -195,6 +212,9 @@ func (UnimplementedComicServiceServer) GetComics(context.Context, *GetComicsRequ
 func (UnimplementedComicServiceServer) SearchComics(context.Context, *SearchComicsRequest) (*ComicsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SearchComics not implemented")
 }
+func (UnimplementedComicServiceServer) MarkComicsRead(context.Context, *MarkComicsReadRequest) (*MarkComicsReadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkComicsRead not implemented")
+}
 func (UnimplementedComicServiceServer) mustEmbedUnimplementedComicServiceServer() {}
 func (UnimplementedComicServiceServer) testEmbeddedByValue()                      {}
 
@@ -342,6 +362,24 @@ func _ComicService_SearchComics_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ComicService_MarkComicsRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MarkComicsReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ComicServiceServer).MarkComicsRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ComicService_MarkComicsRead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ComicServiceServer).MarkComicsRead(ctx, req.(*MarkComicsReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ComicService_ServiceDesc is the grpc.ServiceDesc for ComicService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -377,6 +415,10 @@ var ComicService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SearchComics",
 			Handler:    _ComicService_SearchComics_Handler,
 		},
+		{
+			MethodName: "MarkComicsRead",
+			Handler:    _ComicService_MarkComicsRead_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "comics_service.proto",
@@ -2,14 +2,51 @@ package sampler
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
 	"google.golang.org/protobuf/proto"
 )
 
+// jsonOptions writes every field with its proto name, an empty indent keeps
+// the message on a single line
+func jsonOptions(indent string) protojson.MarshalOptions {
+	return protojson.MarshalOptions{
+		Indent:            indent,
+		UseProtoNames:     true,
+		UseEnumNumbers:    false,
+		EmitDefaultValues: true,
+		EmitUnpopulated:   true,
+	}
+}
+
+// WriteProtobufField writes a proto.Message to w as one element of the
+// repeated message field of an enclosing message. The elements written one
+// after the other decode as that message, so long lists can be streamed.
+func WriteProtobufField(w io.Writer, field protowire.Number, message proto.Message) error {
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("proto.Marshal: %v", err)
+	}
+	data = protowire.AppendBytes(protowire.AppendTag(nil, field, protowire.BytesType), data)
+	_, err = w.Write(data)
+	return err
+}
+
+// WriteProtobufToJSON writes a proto.Message to w as proto JSON
+func WriteProtobufToJSON(w io.Writer, message proto.Message, indent string) error {
+	data, err := jsonOptions(indent).Marshal(message)
+	if err != nil {
+		return fmt.Errorf("protojson.Marshal: %v", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
+
 // WriteProtobufToBinaryFile writes a proto.Message to a binary file
 func WriteProtobufToBinaryFile(message proto.Message, filename string) error {
 	data, err := proto.Marshal(message)
@@ -36,13 +73,7 @@ func ReadProtobufFromBinaryFile(message proto.Message, filename string) error {
 
 // WriteProtobufToJSONFile writes a proto.Message to a JSON file
 func WriteProtobufToJSONFile(message proto.Message, filename string) error {
-	data, err := protojson.MarshalOptions{
-		Indent:            "  ",
-		UseProtoNames:     true,
-		UseEnumNumbers:    false,
-		EmitDefaultValues: true,
-		EmitUnpopulated:   true,
-	}.Marshal(message)
+	data, err := jsonOptions("  ").Marshal(message)
 	if err != nil {
 		return fmt.Errorf("protojson.Marshal: %v", err)
 	}
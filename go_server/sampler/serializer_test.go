@@ -1,11 +1,14 @@
 package sampler
 
 import (
+	"bytes"
 	"os"
+	"strings"
 	"testing"
 
 	pb "comics/pkg/pb"
 
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -117,3 +120,49 @@ func TestFailBadProtoSerializer(t *testing.T) {
 		return
 	}
 }
+
+func TestSerializerStreamedComics(t *testing.T) {
+	t.Parallel()
+
+	comics := &pb.Comics{Comics: []*pb.Comic{NewComic(), NewComic(), NewComic()}}
+	var binary, ndjson bytes.Buffer
+	for _, comic := range comics.Comics {
+		if err := WriteProtobufField(&binary, 1, comic); err != nil {
+			t.Errorf("WriteProtobufField() error = %v", err)
+			return
+		}
+		if err := WriteProtobufToJSON(&ndjson, comic, ""); err != nil {
+			t.Errorf("WriteProtobufToJSON() error = %v", err)
+			return
+		}
+		ndjson.WriteString("\n")
+	}
+
+	// The streamed elements decode as the enclosing message
+	comicsFromBinary := &pb.Comics{}
+	if err := proto.Unmarshal(binary.Bytes(), comicsFromBinary); err != nil {
+		t.Errorf("proto.Unmarshal() error = %v", err)
+		return
+	}
+	if !proto.Equal(comics, comicsFromBinary) {
+		t.Errorf("comics != comicsFromBinary")
+		return
+	}
+
+	lines := strings.Split(strings.TrimSuffix(ndjson.String(), "\n"), "\n")
+	if len(lines) != len(comics.Comics) {
+		t.Errorf("expected one JSON line per comic, got %d", len(lines))
+		return
+	}
+	for i, line := range lines {
+		comicFromJSON := &pb.Comic{}
+		if err := protojson.Unmarshal([]byte(line), comicFromJSON); err != nil {
+			t.Errorf("protojson.Unmarshal() error = %v", err)
+			return
+		}
+		if !proto.Equal(comics.Comics[i], comicFromJSON) {
+			t.Errorf("comic %d != comicFromJSON", i)
+			return
+		}
+	}
+}
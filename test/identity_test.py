@@ -1,8 +1,11 @@
+import json
 import unittest
+from pathlib import Path
 
 from src.db import ComicDB, Types
 from src.db.identity import (
     build_identity_key,
+    build_identity_key_from_titles,
     normalize_title_variants,
     title_match_key,
     titles_are_prefix_match,
@@ -52,6 +55,40 @@ class TestIdentityHelpers(unittest.TestCase):
         )
 
 
+class TestIdentityGoldenCorpus(unittest.TestCase):
+    """The corpus is shared with go_server/internal/identity, both
+    implementations must agree on every case."""
+
+    @classmethod
+    def setUpClass(cls):
+        corpus_path = Path(__file__).with_name("identity_golden.json")
+        cls.corpus = json.loads(corpus_path.read_text(encoding="utf-8"))
+
+    def test_titles(self):
+        for case in self.corpus["titles"]:
+            with self.subTest(titles=case["titles"]):
+                self.assertEqual(
+                    normalize_title_variants(case["titles"], case["com_type"]),
+                    case["normalized_titles"],
+                )
+                self.assertEqual(
+                    build_identity_key_from_titles(case["titles"], case["com_type"]),
+                    case["identity_key"],
+                )
+                self.assertEqual(
+                    [title_match_key(title) for title in case["titles"]],
+                    case["match_keys"],
+                )
+
+    def test_prefix_matches(self):
+        for case in self.corpus["prefix_matches"]:
+            with self.subTest(incoming=case["incoming"], stored=case["stored"]):
+                self.assertEqual(
+                    titles_are_prefix_match(case["incoming"], case["stored"]),
+                    case["match"],
+                )
+
+
 class TestComicIdentity(unittest.TestCase):
     def test_comic_db_normalizes_titles_and_identity_key(self):
         comic = ComicDB(